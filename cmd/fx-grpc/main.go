@@ -4,14 +4,14 @@ package main
 import (
   "context"
   "log"
-  "net"
-  "net/http"
   "sync"
 
-  gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-  "github.com/prometheus/client_golang/prometheus/promhttp"
   "google.golang.org/grpc"
 
+  "github.com/example/payment-gateway-poc/pkg/config"
+  "github.com/example/payment-gateway-poc/pkg/server"
+  "github.com/example/payment-gateway-poc/pkg/rbac"
+
   fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
   "github.com/example/payment-gateway-poc/internal/grpcserver"
 )
@@ -47,40 +47,25 @@ func registerAdmin(grpcServer *grpc.Server) {
 
 
 func main() {
-  grpcSrv := grpc.NewServer(
-    grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-    grpc.StreamInterceptor(gp.StreamServerInterceptor),
-  )
-
-  // ==== REGISTRASI SERVICE UTAMA (pilih salah satu sesuai kode generate) ====
-  // 1) Jika service di proto bernama `service FX { ... }`
-  // fxv1.RegisterFXServer(grpcSrv, &grpcserver.FxServer{})
-  // 2) Jika service di proto bernama `service FxService { ... }`
-  fxv1.RegisterFxServiceServer(grpcSrv, &grpcserver.FxServer{})
-
-  // Admin/Seed
-  registerAdmin(grpcSrv)
-
-  // Daftarkan metrics
-  gp.Register(grpcSrv)
+  cfg := config.DefaultFX()
+  config.MustLoad("fx-grpc", &cfg)
+
+  // recovery + metrics + mTLS authz (bila tls.cert_file di-set) dari pkg/server
+  srv, err := server.New(server.Options{
+    Name:   "fx-grpc",
+    Config: cfg.Server,
+    TLS:    cfg.TLS,
+    // RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+    Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+  })
+  if err != nil {
+    log.Fatalf("[fx-grpc] %v", err)
+  }
 
-  // gRPC listener
-  go func() {
-    lis, err := net.Listen("tcp", ":9092")
-    if err != nil {
-      log.Fatalf("[fx-grpc] listen :9092: %v", err)
-    }
-    log.Println("[fx-grpc] serving gRPC on :9092")
-    if err := grpcSrv.Serve(lis); err != nil {
-      log.Fatalf("[fx-grpc] serve error: %v", err)
-    }
-  }()
+  fxv1.RegisterFxServiceServer(srv.GRPC, &grpcserver.FxServer{})
+  registerAdmin(srv.GRPC)
 
-  // HTTP /metrics
-  mux := http.NewServeMux()
-  mux.Handle("/metrics", promhttp.Handler())
-  log.Println("[fx-grpc] serving metrics on :9102 /metrics")
-  if err := http.ListenAndServe(":9102", mux); err != nil && err != http.ErrServerClosed {
-    log.Fatalf("[fx-grpc] metrics server error: %v", err)
+  if err := srv.Run(context.Background()); err != nil {
+    log.Fatalf("[fx-grpc] %v", err)
   }
 }
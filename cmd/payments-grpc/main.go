@@ -5,39 +5,28 @@
 package main
 
 import (
-  "log"
-  "net"
-  "net/http"
+  "context"
 
-  gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-  "github.com/prometheus/client_golang/prometheus/promhttp"
-  "google.golang.org/grpc"
+  "github.com/example/payment-gateway-poc/pkg/config"
+  "github.com/example/payment-gateway-poc/pkg/logging"
+  "github.com/example/payment-gateway-poc/pkg/server"
 )
 
 func main() {
-  // gRPC server + Prometheus interceptors
-  grpcServer := grpc.NewServer(
-    grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-    grpc.StreamInterceptor(gp.StreamServerInterceptor),
-  )
-  gp.Register(grpcServer)
-
-  // Listen gRPC
-  lis, err := net.Listen("tcp", ":9091")
+  cfg := config.DefaultPayments()
+  config.MustLoad("payments-grpc", &cfg)
+
+  // recovery + metrics + mTLS authz (bila tls.cert_file di-set) dari pkg/server
+  srv, err := server.New(server.Options{
+    Name:   "payments-grpc",
+    Config: cfg.Server,
+    TLS:    cfg.TLS,
+  })
   if err != nil {
-    log.Fatalf("[payments-grpc] listen error: %v", err)
+    logging.Fatal("init server", "error", err)
   }
 
-  // Jalankan gRPC di goroutine
-  go func() {
-    log.Println("[payments-grpc] serving gRPC on :9091")
-    if err := grpcServer.Serve(lis); err != nil {
-      log.Fatalf("[payments-grpc] serve error: %v", err)
-    }
-  }()
-
-  // Endpoint Prometheus metrics (HTTP terpisah)
-  http.Handle("/metrics", promhttp.Handler())
-  log.Println("[payments-grpc] serving metrics on :9101 /metrics")
-  log.Fatal(http.ListenAndServe(":9101", nil))
+  if err := srv.Run(context.Background()); err != nil {
+    logging.Fatal("server stopped", "error", err)
+  }
 }
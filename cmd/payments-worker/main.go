@@ -3,79 +3,78 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"log/slog"
 	"time"
 
-	"github.com/segmentio/kafka-go"
+	"github.com/jackc/pgx/v5/pgxpool"
 
-	commonv1   "github.com/example/payment-gateway-poc/proto/gen/common/v1"
-	
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/pkg/server"
 )
 
-type inMsg struct {
-	IdempotencyKey string `json:"idempotency_key"`
-	SenderID       string `json:"sender_id"`
-	ReceiverID     string `json:"receiver_id"`
-	AmountIDR      int64  `json:"amount_idr"`
-	CurrencyInput  string `json:"currency_input"`
-	TxDate         string `json:"tx_date"`
-}
-
 func main() {
-	brokers := env("KAFKA_BROKERS", "kafka:9092")
-	reqTopic := env("KAFKA_REQ_TOPIC", "payments.request")
-	resTopic := env("KAFKA_RES_TOPIC", "payments.result")
+	conf := config.DefaultPaymentsWorker()
+	config.MustLoad("payments-worker", &conf)
+	concurrency := conf.Concurrency
 
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  []string{brokers},
-		Topic:    reqTopic,
-		GroupID:  "payments-worker",
-		MinBytes: 1,
-		MaxBytes: 10e6,
-	})
-	defer r.Close()
+	srv, err := server.New(server.Options{Name: "payments-worker", Config: conf.Server})
+	if err != nil {
+		logging.Fatal("init server", "error", err)
+	}
 
-	w := &kafka.Writer{
-		Addr:     kafka.TCP(brokers),
-		Topic:    resTopic,
-		Balancer: &kafka.LeastBytes{},
+	// === DB pool ===
+	cfg, err := pgxpool.ParseConfig(conf.Database.URL)
+	if err != nil {
+		logging.Fatal("parse dsn", "error", err)
 	}
-	defer w.Close()
+	cfg.MinConns = 1
+	cfg.MaxConns = int32(max(concurrency, 1))
+	cfg.HealthCheckPeriod = 30 * time.Second
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	db, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		logging.Fatal("connect db", "error", err)
+	}
+	defer db.Close()
 
-	log.Println("[payments-worker] started")
-	for {
-		m, err := r.ReadMessage(ctx)
-		if err != nil {
-			log.Printf("read err: %v", err)
-			return
-		}
-		var in inMsg
-		if err := json.Unmarshal(m.Value, &in); err != nil {
-			log.Printf("bad msg: %v", err)
-			continue
-		}
+	bus := queue.NewKafka(conf.Kafka.Brokers, conf.Kafka.RequestTopic, conf.Kafka.ResultTopic)
+	defer bus.Close()
 
-		// Contoh: panggil PaymentsService.LogAndSettle (disederhanakan; di sini kita mock)
-		_ = commonv1.Currency_IDR // touch import
+	r := bus.Subscribe("payments-worker")
+	defer r.Close()
 
-		// Simulasi proses OK
-		out := map[string]any{
-			"status": "SUCCESS",
-			"ref":    "RSV-" + in.IdempotencyKey,
-		}
-		b, _ := json.Marshal(out)
+	srv.Check("postgres", db.Ping)
+	srv.Check("kafka", health.Kafka(conf.Kafka.Brokers, conf.Kafka.RequestTopic))
 
-		if err := w.WriteMessages(ctx, kafka.Message{Key: m.Key, Value: b, Time: time.Now()}); err != nil {
-			log.Printf("write err: %v", err)
-		}
+	// Settlement: 1 transaksi per payment, atau micro-batch bila WORKER_BATCH_SIZE > 1
+	store := worker.NewStore(db)
+	if err := store.EnsureSchema(context.Background()); err != nil {
+		logging.Fatal("ensure schema", "error", err)
 	}
-}
+	var settler worker.Settler = store
+	if size := conf.BatchSize; size > 1 {
+		wait := conf.BatchWait
+		b := worker.NewBatcher(store, size, wait)
+		srv.Go(func(ctx context.Context) error { b.Run(ctx); return nil })
+		settler = b
+		slog.Info("batching settlements", "batch_size", size, "batch_wait", wait.String())
+	}
+
+	pool := worker.NewPool(worker.Config{
+		Concurrency:    concurrency,
+		LaneBuffer:     conf.LaneBuffer,
+		CommitInterval: conf.CommitInterval,
+		RetryBase:      conf.RetryBase,
+		RetryMax:       conf.RetryMax,
+	}, worker.SettleHandler(settler, bus))
+	// saat shutdown pool berhenti fetch, menyelesaikan lane, lalu commit offset
+	srv.Go(func(ctx context.Context) error { return pool.Run(ctx, r) })
 
-func env(k, d string) string { if v := os.Getenv(k); v != "" { return v }; return d }
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
+	}
+}
@@ -4,13 +4,13 @@ package main
 import (
   "context"
   "log"
-  "net"
-  "net/http"
 
-  gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-  "github.com/prometheus/client_golang/prometheus/promhttp"
   "google.golang.org/grpc"
 
+  "github.com/example/payment-gateway-poc/pkg/config"
+  "github.com/example/payment-gateway-poc/pkg/server"
+  "github.com/example/payment-gateway-poc/pkg/rbac"
+
   riskv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
   "github.com/example/payment-gateway-poc/internal/grpcserver"
 )
@@ -51,38 +51,25 @@ func registerAdmin(grpcServer *grpc.Server) {
 }
 
 func main() {
-  grpcSrv := grpc.NewServer(
-    grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-    grpc.StreamInterceptor(gp.StreamServerInterceptor),
-  )
-
-  // ==== REGISTRASI SERVICE UTAMA (pilih sesuai generate) ====
-  // riskv1.RegisterRiskServer(grpcSrv, &grpcserver.RiskServer{})
-  riskv1.RegisterRiskServiceServer(grpcSrv, &grpcserver.RiskServer{})
+  cfg := config.DefaultRisk()
+  config.MustLoad("risk-grpc", &cfg)
 
-  // Admin/Seed
-  registerAdmin(grpcSrv)
-
-  // Metrics
-  gp.Register(grpcSrv)
+  // recovery + metrics + mTLS authz (bila tls.cert_file di-set) dari pkg/server
+  srv, err := server.New(server.Options{
+    Name:   "risk-grpc",
+    Config: cfg.Server,
+    TLS:    cfg.TLS,
+    // RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+    Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+  })
+  if err != nil {
+    log.Fatalf("[risk-grpc] %v", err)
+  }
 
-  // gRPC
-  go func() {
-    lis, err := net.Listen("tcp", ":9094")
-    if err != nil {
-      log.Fatalf("[risk-grpc] listen :9094: %v", err)
-    }
-    log.Println("[risk-grpc] serving gRPC on :9094")
-    if err := grpcSrv.Serve(lis); err != nil {
-      log.Fatalf("[risk-grpc] serve: %v", err)
-    }
-  }()
+  riskv1.RegisterRiskServiceServer(srv.GRPC, &grpcserver.RiskServer{})
+  registerAdmin(srv.GRPC)
 
-  // Metrics HTTP
-  mux := http.NewServeMux()
-  mux.Handle("/metrics", promhttp.Handler())
-  log.Println("[risk-grpc] serving metrics on :9104 /metrics")
-  if err := http.ListenAndServe(":9104", mux); err != nil && err != http.ErrServerClosed {
-    log.Fatalf("[risk-grpc] metrics: %v", err)
+  if err := srv.Run(context.Background()); err != nil {
+    log.Fatalf("[risk-grpc] %v", err)
   }
 }
@@ -4,14 +4,14 @@ package main
 import (
   "context"
   "log"
-  "net"
-  "net/http"
   "sync"
 
-  gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-  "github.com/prometheus/client_golang/prometheus/promhttp"
   "google.golang.org/grpc"
 
+  "github.com/example/payment-gateway-poc/pkg/config"
+  "github.com/example/payment-gateway-poc/pkg/server"
+  "github.com/example/payment-gateway-poc/pkg/rbac"
+
   walletv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
   "github.com/example/payment-gateway-poc/internal/grpcserver"
 )
@@ -43,38 +43,25 @@ func registerAdmin(grpcServer *grpc.Server) {
 }
 
 func main() {
-  grpcSrv := grpc.NewServer(
-    grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-    grpc.StreamInterceptor(gp.StreamServerInterceptor),
-  )
-
-  // ==== REGISTRASI SERVICE UTAMA (pilih sesuai generate) ====
-  // walletv1.RegisterWalletServer(grpcSrv, &grpcserver.WalletServer{})
-  walletv1.RegisterWalletServiceServer(grpcSrv, &grpcserver.WalletServer{})
+  cfg := config.DefaultWallet()
+  config.MustLoad("wallet-grpc", &cfg)
 
-  // Admin/Seed
-  registerAdmin(grpcSrv)
-
-  // Metrics
-  gp.Register(grpcSrv)
+  // recovery + metrics + mTLS authz (bila tls.cert_file di-set) dari pkg/server
+  srv, err := server.New(server.Options{
+    Name:   "wallet-grpc",
+    Config: cfg.Server,
+    TLS:    cfg.TLS,
+    // RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+    Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+  })
+  if err != nil {
+    log.Fatalf("[wallet-grpc] %v", err)
+  }
 
-  // gRPC
-  go func() {
-    lis, err := net.Listen("tcp", ":9093")
-    if err != nil {
-      log.Fatalf("[wallet-grpc] listen :9093: %v", err)
-    }
-    log.Println("[wallet-grpc] serving gRPC on :9093")
-    if err := grpcSrv.Serve(lis); err != nil {
-      log.Fatalf("[wallet-grpc] serve: %v", err)
-    }
-  }()
+  walletv1.RegisterWalletServiceServer(srv.GRPC, &grpcserver.WalletServer{})
+  registerAdmin(srv.GRPC)
 
-  // Metrics HTTP
-  mux := http.NewServeMux()
-  mux.Handle("/metrics", promhttp.Handler())
-  log.Println("[wallet-grpc] serving metrics on :9103 /metrics")
-  if err := http.ListenAndServe(":9103", mux); err != nil && err != http.ErrServerClosed {
-    log.Fatalf("[wallet-grpc] metrics: %v", err)
+  if err := srv.Run(context.Background()); err != nil {
+    log.Fatalf("[wallet-grpc] %v", err)
   }
 }
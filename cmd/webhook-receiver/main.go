@@ -0,0 +1,52 @@
+// cmd/webhook-receiver/main.go
+// Merchant tiruan untuk dev lokal: memverifikasi signature dan mencetak
+// setiap webhook. Daftarkan URL-nya lewat
+// POST /api/merchants/{merchant_id}/webhooks, lalu set WEBHOOK_SECRET ke
+// secret yang dikembalikan.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/example/payment-gateway-poc/internal/webhook"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/server"
+)
+
+func main() {
+	cfg := config.DefaultWebhookReceiver()
+	config.MustLoad("webhook-receiver", &cfg)
+	addr := cfg.HTTPAddr
+	rc := webhook.NewReceiver(cfg.Secret)
+	if cfg.FailFirst > 0 {
+		rc.FailNext(cfg.FailFirst) // uji retry
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhooks", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		before := len(rc.Received())
+		rc.ServeHTTP(w, r)
+		if got := rc.Received(); len(got) > before {
+			b, _ := json.Marshal(got[len(got)-1])
+			slog.InfoContext(r.Context(), "webhook received",
+				"event_id", r.Header.Get(webhook.HeaderID), "body", json.RawMessage(b))
+		}
+	}))
+	mux.HandleFunc("GET /webhooks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(rc.Received())
+	})
+
+	srv, err := server.New(server.Options{Name: "webhook-receiver", Config: cfg.Server})
+	if err != nil {
+		logging.Fatal("init server", "error", err)
+	}
+	srv.HTTP(addr, mux)
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
+	}
+}
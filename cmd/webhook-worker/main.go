@@ -0,0 +1,75 @@
+// cmd/webhook-worker/main.go
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/example/payment-gateway-poc/internal/webhook"
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/server"
+)
+
+func main() {
+	cfg := config.DefaultWebhookWorker()
+	config.MustLoad("webhook-worker", &cfg)
+
+	srv, err := server.New(server.Options{Name: "webhook-worker", Config: cfg.Server})
+	if err != nil {
+		logging.Fatal("init server", "error", err)
+	}
+
+	db, err := pgxpool.New(context.Background(), cfg.Database.URL)
+	if err != nil {
+		logging.Fatal("connect db", "error", err)
+	}
+	defer db.Close()
+
+	store, err := webhook.NewPostgres(context.Background(), db)
+	if err != nil {
+		logging.Fatal("ensure schema", "error", err)
+	}
+
+	srv.Check("postgres", db.Ping)
+	srv.Check("kafka", health.Kafka(cfg.Kafka.Brokers, cfg.Kafka.ResultTopic))
+
+	retry := webhook.Retry{
+		BaseDelay: cfg.Retry.BaseDelay,
+		MaxDelay:  cfg.Retry.MaxDelay,
+		Window:    cfg.Retry.Window,
+	}
+
+	// Kirim: polling delivery yang jatuh tempo
+	d := webhook.NewDeliverer(store, webhook.DeliverConfig{
+		PollInterval: cfg.PollInterval,
+		Concurrency:  cfg.Concurrency,
+		Timeout:      cfg.Timeout,
+		Retry:        retry,
+		AllowPrivate: cfg.AllowPrivate,
+	})
+	srv.Go(func(ctx context.Context) error { d.Run(ctx); return nil })
+
+	// Dispatch: consumer group sendiri di payments.result, terpisah dari gateway
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  cfg.Kafka.Brokers,
+		GroupID:  cfg.Group,
+		Topic:    cfg.Kafka.ResultTopic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+	defer r.Close()
+
+	pool := worker.NewPool(worker.Config{Concurrency: cfg.DispatchConcurrency},
+		webhook.DispatchHandler(store, retry))
+
+	srv.Go(func(ctx context.Context) error { return pool.Run(ctx, r) })
+
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
+	}
+}
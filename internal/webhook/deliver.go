@@ -0,0 +1,177 @@
+// internal/webhook/deliver.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeliverConfig: semua field punya default.
+type DeliverConfig struct {
+	PollInterval time.Duration // default 1s
+	BatchSize    int           // default 50
+	Concurrency  int           // default 8
+	Timeout      time.Duration // per request, default 10s
+	Retry        Retry
+	// AllowPrivate: boleh kirim ke alamat loopback/privat (dev, test).
+	AllowPrivate bool
+}
+
+func (c DeliverConfig) withDefaults() DeliverConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	c.Retry = c.Retry.withDefaults()
+	return c
+}
+
+// Deliverer mengirim delivery yang jatuh tempo.
+type Deliverer struct {
+	cfg    DeliverConfig
+	store  Store
+	client *http.Client
+}
+
+func NewDeliverer(s Store, cfg DeliverConfig) *Deliverer {
+	cfg = cfg.withDefaults()
+	return &Deliverer{cfg: cfg, store: s, client: newClient(cfg.Timeout, cfg.AllowPrivate)}
+}
+
+// Run polling sampai ctx selesai.
+func (d *Deliverer) Run(ctx context.Context) {
+	t := time.NewTicker(d.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		for d.RunOnce(ctx) == d.cfg.BatchSize {
+			// masih ada backlog: langsung ambil batch berikutnya
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce mengirim satu batch dan mengembalikan jumlah delivery yang diproses.
+func (d *Deliverer) RunOnce(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+	// lease > timeout: delivery yang diklaim tidak diambil deliverer lain
+	// selama request masih jalan
+	ds, err := d.store.Claim(ctx, time.Now().UTC(), 2*d.cfg.Timeout, d.cfg.BatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "webhook: claim failed", "error", err)
+		return 0
+	}
+	sem := make(chan struct{}, d.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range ds {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(del *Delivery) {
+			defer func() { <-sem; wg.Done() }()
+			d.deliver(ctx, del)
+		}(&ds[i])
+	}
+	wg.Wait()
+	return len(ds)
+}
+
+func (d *Deliverer) deliver(ctx context.Context, del *Delivery) {
+	start := time.Now()
+	code, err := d.send(ctx, del)
+	elapsed := time.Since(start)
+	deliveryDuration.Observe(elapsed.Seconds())
+
+	now := time.Now().UTC()
+	del.Attempts++
+	del.LastStatusCode = code
+	del.LastError = ""
+	a := Attempt{DeliveryID: del.ID, N: del.Attempts, At: start.UTC(), StatusCode: code, Duration: elapsed}
+
+	var outcome string
+	switch {
+	case err == nil:
+		outcome = "delivered"
+		del.Status = StatusDelivered
+		del.DeliveredAt = &now
+	default:
+		a.Error = err.Error()
+		del.LastError = a.Error
+		next := now.Add(d.cfg.Retry.Delay(del.Attempts))
+		if isPermanent(err) || next.After(del.ExpiresAt) {
+			outcome = "failed"
+			del.Status = StatusFailed
+		} else {
+			outcome = "retry"
+			del.NextAttemptAt = next
+		}
+	}
+	deliveries.WithLabelValues(outcome).Inc()
+
+	// ctx bisa sudah selesai saat shutdown; hasil tetap dicatat
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.store.Record(rctx, del, a); err != nil {
+		slog.ErrorContext(rctx, "webhook: record attempt failed", "delivery_id", del.ID, "error", err)
+	}
+}
+
+// permanentError: tidak ada gunanya di-retry (endpoint dinonaktifkan, URL rusak).
+type permanentError struct{ error }
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+func (d *Deliverer) send(ctx context.Context, del *Delivery) (int, error) {
+	ep, err := d.store.GetEndpoint(ctx, del.EndpointID)
+	if err != nil {
+		return 0, fmt.Errorf("endpoint: %w", err)
+	}
+	if !ep.Active {
+		return 0, permanentError{errors.New("endpoint disabled")}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, permanentError{err}
+	}
+	ts := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "payment-gateway-webhooks/1")
+	req.Header.Set(HeaderID, del.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(ep.Secret, ts, del.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,126 @@
+// internal/webhook/dispatch.go
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/events"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+// Payload: body JSON yang dikirim ke merchant.
+type Payload struct {
+	ID        string      `json:"id"` // event id; sama untuk setiap retry
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      PaymentData `json:"data"`
+}
+
+type PaymentData struct {
+	MerchantID     string `json:"merchant_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason,omitempty"`
+	Ref            string `json:"ref,omitempty"` // payment_id di ledger
+}
+
+// DispatchHandler membaca payments.result dan mengantrikan delivery untuk
+// setiap endpoint aktif milik merchant. Dijalankan lewat worker.Pool dengan
+// consumer group sendiri. Gangguan store dikembalikan Retryable supaya
+// message diulang dan webhook-nya tidak hilang; hanya message rusak yang
+// dilewati.
+func DispatchHandler(s Store, retry Retry) worker.Handler {
+	retry = retry.withDefaults()
+	return func(ctx context.Context, m kafka.Message) error {
+		ev, err := events.DecodeResult(m.Value)
+		if err != nil {
+			return fmt.Errorf("bad msg: %w", err)
+		}
+		p, ok := payloadFor(ev)
+		if !ok {
+			return nil
+		}
+		ctx = logging.With(ctx, logging.FieldIdempotencyKey, p.Data.IdempotencyKey)
+		eps, err := s.ListEndpoints(ctx, p.Data.MerchantID)
+		if err != nil {
+			return worker.Retryable(fmt.Errorf("list endpoints: %w", err))
+		}
+		body, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("encode payload: %w", err)
+		}
+
+		now := time.Now().UTC()
+		var ds []Delivery
+		for _, e := range eps {
+			if !e.Active {
+				continue
+			}
+			ds = append(ds, Delivery{
+				EndpointID:    e.ID,
+				MerchantID:    e.MerchantID,
+				EventID:       p.ID,
+				EventType:     p.Type,
+				Payload:       body,
+				Status:        StatusPending,
+				NextAttemptAt: now,
+				ExpiresAt:     now.Add(retry.Window),
+			})
+		}
+		if len(ds) == 0 {
+			return nil
+		}
+		if err := s.Enqueue(ctx, ds); err != nil {
+			return worker.Retryable(fmt.Errorf("enqueue: %w", err))
+		}
+		dispatched.Add(float64(len(ds)))
+		slog.InfoContext(ctx, "webhook dispatched", "event_id", p.ID, "event_type", p.Type,
+			"merchant_id", p.Data.MerchantID, "endpoints", len(ds))
+		return nil
+	}
+}
+
+// payloadFor: hanya outcome final yang dikirim. Replay dan duplicate key
+// sudah dikabarkan saat payment aslinya selesai.
+func payloadFor(ev events.Result) (Payload, bool) {
+	if ev.GetMerchantId() == "" {
+		return Payload{}, false
+	}
+	p := Payload{
+		ID:        ev.GetHeader().GetEventId(),
+		CreatedAt: time.UnixMilli(ev.GetHeader().GetOccurredAtMs()).UTC(),
+		Data: PaymentData{
+			MerchantID:     ev.GetMerchantId(),
+			// key seperti dikirim merchant, tanpa namespace tenant internal
+			IdempotencyKey: tenant.ClientKey(ev.GetMerchantId(), ev.GetIdempotencyKey()),
+			Ref:            ev.GetPaymentId(),
+		},
+	}
+	switch ev := ev.(type) {
+	case *eventsv1.PaymentSettled:
+		if ev.GetStatus() != "SUCCESS" {
+			return Payload{}, false
+		}
+		p.Type, p.Data.Status = EventPaymentSucceeded, ev.GetStatus()
+	case *eventsv1.PaymentFailed:
+		if ev.GetReason() == "duplicate_idempotency_key" {
+			return Payload{}, false
+		}
+		p.Type, p.Data.Status, p.Data.Reason = EventPaymentFailed, "FAILED", ev.GetReason()
+	default:
+		return Payload{}, false
+	}
+	if p.ID == "" {
+		p.ID = p.Data.IdempotencyKey + ":" + p.Type
+	}
+	return p, true
+}
@@ -0,0 +1,87 @@
+// internal/webhook/dispatch_test.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/events"
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+// flakyStore: ListEndpoints gagal sekali (DB sempat down).
+type flakyStore struct {
+	*Memory
+	fails atomic.Int32
+}
+
+func (s *flakyStore) ListEndpoints(ctx context.Context, merchantID string) ([]Endpoint, error) {
+	if s.fails.Add(-1) >= 0 {
+		return nil, errors.New("conn refused")
+	}
+	return s.Memory.ListEndpoints(ctx, merchantID)
+}
+
+// oneMessage: satu message lalu menunggu ctx; commit diabaikan.
+type oneMessage chan kafka.Message
+
+func (r oneMessage) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (oneMessage) CommitMessages(context.Context, ...kafka.Message) error { return nil }
+
+func TestDispatchRetriesStoreErrors(t *testing.T) {
+	st := &flakyStore{Memory: NewMemory()}
+	st.fails.Store(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := st.CreateEndpoint(ctx, &Endpoint{MerchantID: "M-1", URL: "https://shop.example/hooks", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := events.EncodeResult(&eventsv1.PaymentSettled{
+		IdempotencyKey: "M-1:order-1", PaymentId: "pay-1", Status: "SUCCESS", MerchantId: "M-1",
+	}, "payments-worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := make(oneMessage, 1)
+	r <- kafka.Message{Topic: "payments.result", Value: b}
+
+	p := worker.NewPool(worker.Config{Concurrency: 1, RetryBase: time.Millisecond, RetryMax: 5 * time.Millisecond},
+		DispatchHandler(st, Retry{}))
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx, r) }()
+
+	var ds []Delivery
+	for deadline := time.Now().Add(5 * time.Second); len(ds) == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("delivery not enqueued after ListEndpoints failure")
+		}
+		if ds, err = st.ListDeliveries(ctx, "M-1", 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if st.fails.Load() >= 0 {
+		t.Fatal("ListEndpoints failure was not exercised")
+	}
+	if len(ds) != 1 {
+		t.Fatalf("deliveries %d, want 1", len(ds))
+	}
+}
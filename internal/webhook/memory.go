@@ -0,0 +1,174 @@
+// internal/webhook/memory.go
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Memory: Store in-process untuk dev/test (hilang saat restart).
+type Memory struct {
+	mu         sync.Mutex
+	endpoints  map[string]*Endpoint
+	deliveries map[string]*Delivery
+	byEvent    map[string]string // endpoint_id/event_id → delivery id
+	attempts   map[string][]Attempt
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		endpoints:  map[string]*Endpoint{},
+		deliveries: map[string]*Delivery{},
+		byEvent:    map[string]string{},
+		attempts:   map[string][]Attempt{},
+	}
+}
+
+func (s *Memory) CreateEndpoint(ctx context.Context, e *Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	e.CreatedAt = time.Now().UTC()
+	cp := *e
+	s.endpoints[e.ID] = &cp
+	return nil
+}
+
+func (s *Memory) GetEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.endpoints[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (s *Memory) ListEndpoints(ctx context.Context, merchantID string) ([]Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Endpoint
+	for _, e := range s.endpoints {
+		if e.MerchantID == merchantID {
+			out = append(out, *e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *Memory) DisableEndpoint(ctx context.Context, merchantID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.endpoints[id]
+	if !ok || e.MerchantID != merchantID {
+		return ErrNotFound
+	}
+	e.Active = false
+	return nil
+}
+
+func (s *Memory) Enqueue(ctx context.Context, ds []Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, d := range ds {
+		k := d.EndpointID + "/" + d.EventID
+		if _, dup := s.byEvent[k]; dup {
+			continue
+		}
+		if d.ID == "" {
+			d.ID = uuid.NewString()
+		}
+		d.CreatedAt, d.UpdatedAt = now, now
+		cp := d
+		s.deliveries[d.ID] = &cp
+		s.byEvent[k] = d.ID
+	}
+	return nil
+}
+
+func (s *Memory) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*Delivery
+	for _, d := range s.deliveries {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	out := make([]Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		out = append(out, *d)
+	}
+	return out, nil
+}
+
+func (s *Memory) Record(ctx context.Context, d *Delivery, a Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.deliveries[d.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	d.UpdatedAt = time.Now().UTC()
+	*cur = *d
+	s.attempts[d.ID] = append(s.attempts[d.ID], a)
+	return nil
+}
+
+func (s *Memory) GetDelivery(ctx context.Context, merchantID, id string) (*Delivery, []Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	if !ok || d.MerchantID != merchantID {
+		return nil, nil, ErrNotFound
+	}
+	cp := *d
+	return &cp, append([]Attempt(nil), s.attempts[id]...), nil
+}
+
+func (s *Memory) ListDeliveries(ctx context.Context, merchantID string, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Delivery
+	for _, d := range s.deliveries {
+		if d.MerchantID == merchantID {
+			out = append(out, *d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Memory) Redeliver(ctx context.Context, merchantID, id string, now, expiresAt time.Time) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deliveries[id]
+	if !ok || d.MerchantID != merchantID {
+		return nil, ErrNotFound
+	}
+	d.Status = StatusPending
+	d.NextAttemptAt = now
+	d.ExpiresAt = expiresAt
+	d.UpdatedAt = now
+	cp := *d
+	return &cp, nil
+}
@@ -0,0 +1,36 @@
+// internal/webhook/metrics.go
+package webhook
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	dispatched = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "webhook",
+			Name:      "dispatched_total",
+			Help:      "Deliveries queued from payments.result",
+		},
+	)
+	deliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "webhook",
+			Name:      "attempts_total",
+			Help:      "Delivery attempts by outcome (delivered, retry, failed)",
+		},
+		[]string{"outcome"},
+	)
+	deliveryDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "payment",
+			Subsystem: "webhook",
+			Name:      "attempt_duration_seconds",
+			Help:      "Time spent on one delivery attempt",
+			Buckets:   prometheus.DefBuckets,
+		},
+	)
+)
@@ -0,0 +1,273 @@
+// internal/webhook/postgres.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres: Store bersama untuk gateway (registrasi, redeliver) dan
+// webhook-worker (dispatch, kirim). Claim memakai SKIP LOCKED, jadi
+// beberapa worker boleh jalan bersamaan.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*Postgres)(nil)
+
+func NewPostgres(ctx context.Context, pool *pgxpool.Pool) (*Postgres, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+const deliveryCols = `id::text, endpoint_id::text, merchant_id, event_id, event_type, payload, status,
+	attempts, next_attempt_at, expires_at, last_status_code, last_error, created_at, updated_at, delivered_at`
+
+func scanDelivery(row pgx.Row) (*Delivery, error) {
+	var d Delivery
+	err := row.Scan(&d.ID, &d.EndpointID, &d.MerchantID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &d.ExpiresAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (s *Postgres) CreateEndpoint(ctx context.Context, e *Endpoint) error {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	return s.pool.QueryRow(ctx, `
+		INSERT INTO webhook_endpoints (id, merchant_id, url, secret, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, e.ID, e.MerchantID, e.URL, e.Secret, e.Active).Scan(&e.CreatedAt)
+}
+
+func (s *Postgres) GetEndpoint(ctx context.Context, id string) (*Endpoint, error) {
+	var e Endpoint
+	err := s.pool.QueryRow(ctx, `
+		SELECT id::text, merchant_id, url, secret, active, created_at
+		FROM webhook_endpoints WHERE id = $1
+	`, id).Scan(&e.ID, &e.MerchantID, &e.URL, &e.Secret, &e.Active, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query endpoint: %w", err)
+	}
+	return &e, nil
+}
+
+func (s *Postgres) ListEndpoints(ctx context.Context, merchantID string) ([]Endpoint, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id::text, merchant_id, url, secret, active, created_at
+		FROM webhook_endpoints WHERE merchant_id = $1 ORDER BY created_at
+	`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("query endpoints: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Endpoint, error) {
+		var e Endpoint
+		err := row.Scan(&e.ID, &e.MerchantID, &e.URL, &e.Secret, &e.Active, &e.CreatedAt)
+		return e, err
+	})
+}
+
+func (s *Postgres) DisableEndpoint(ctx context.Context, merchantID, id string) error {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE webhook_endpoints SET active = false WHERE id = $1 AND merchant_id = $2
+	`, id, merchantID)
+	if err != nil {
+		return fmt.Errorf("disable endpoint: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Postgres) Enqueue(ctx context.Context, ds []Delivery) error {
+	b := &pgx.Batch{}
+	for _, d := range ds {
+		if d.ID == "" {
+			d.ID = uuid.NewString()
+		}
+		b.Queue(`
+			INSERT INTO webhook_deliveries
+				(id, endpoint_id, merchant_id, event_id, event_type, payload, status, next_attempt_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		`, d.ID, d.EndpointID, d.MerchantID, d.EventID, d.EventType, d.Payload, d.Status, d.NextAttemptAt, d.ExpiresAt)
+	}
+	if err := s.pool.SendBatch(ctx, b).Close(); err != nil {
+		return fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+func (s *Postgres) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryCols,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim deliveries: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Delivery, error) {
+		d, err := scanDelivery(row)
+		if err != nil {
+			return Delivery{}, err
+		}
+		return *d, nil
+	})
+}
+
+func (s *Postgres) Record(ctx context.Context, d *Delivery, a Attempt) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		cmd, err := tx.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5,
+			    last_error = $6, delivered_at = $7, updated_at = now()
+			WHERE id = $1
+		`, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt)
+		if err != nil {
+			return fmt.Errorf("update delivery: %w", err)
+		}
+		if cmd.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO webhook_attempts (delivery_id, n, at, status_code, error, duration_ms)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, a.DeliveryID, a.N, a.At, a.StatusCode, a.Error, a.Duration.Milliseconds())
+		if err != nil {
+			return fmt.Errorf("insert attempt: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Postgres) GetDelivery(ctx context.Context, merchantID, id string) (*Delivery, []Attempt, error) {
+	d, err := scanDelivery(s.pool.QueryRow(ctx,
+		`SELECT `+deliveryCols+` FROM webhook_deliveries WHERE id = $1 AND merchant_id = $2`, id, merchantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("query delivery: %w", err)
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT delivery_id::text, n, at, status_code, error, duration_ms
+		FROM webhook_attempts WHERE delivery_id = $1 ORDER BY n
+	`, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query attempts: %w", err)
+	}
+	attempts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Attempt, error) {
+		var (
+			a  Attempt
+			ms int64
+		)
+		err := row.Scan(&a.DeliveryID, &a.N, &a.At, &a.StatusCode, &a.Error, &ms)
+		a.Duration = time.Duration(ms) * time.Millisecond
+		return a, err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan attempts: %w", err)
+	}
+	return d, attempts, nil
+}
+
+func (s *Postgres) ListDeliveries(ctx context.Context, merchantID string, limit int) ([]Delivery, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+deliveryCols+` FROM webhook_deliveries WHERE merchant_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query deliveries: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Delivery, error) {
+		d, err := scanDelivery(row)
+		if err != nil {
+			return Delivery{}, err
+		}
+		return *d, nil
+	})
+}
+
+func (s *Postgres) Redeliver(ctx context.Context, merchantID, id string, now, expiresAt time.Time) (*Delivery, error) {
+	d, err := scanDelivery(s.pool.QueryRow(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'PENDING', next_attempt_at = $3, expires_at = $4, updated_at = now()
+		WHERE id = $1 AND merchant_id = $2
+		RETURNING `+deliveryCols,
+		id, merchantID, now, expiresAt))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redeliver: %w", err)
+	}
+	return d, nil
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+  id          UUID PRIMARY KEY,
+  merchant_id TEXT        NOT NULL,
+  url         TEXT        NOT NULL,
+  secret      TEXT        NOT NULL,
+  active      BOOLEAN     NOT NULL DEFAULT true,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS webhook_endpoints_merchant_idx ON webhook_endpoints (merchant_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id               UUID PRIMARY KEY,
+  endpoint_id      UUID        NOT NULL REFERENCES webhook_endpoints (id),
+  merchant_id      TEXT        NOT NULL,
+  event_id         TEXT        NOT NULL,
+  event_type       TEXT        NOT NULL,
+  payload          BYTEA       NOT NULL,
+  status           TEXT        NOT NULL DEFAULT 'PENDING',
+  attempts         INT         NOT NULL DEFAULT 0,
+  next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at       TIMESTAMPTZ NOT NULL,
+  last_status_code INT         NOT NULL DEFAULT 0,
+  last_error       TEXT        NOT NULL DEFAULT '',
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at     TIMESTAMPTZ,
+  UNIQUE (endpoint_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_merchant_idx ON webhook_deliveries (merchant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+  delivery_id UUID        NOT NULL REFERENCES webhook_deliveries (id),
+  n           INT         NOT NULL,
+  at          TIMESTAMPTZ NOT NULL,
+  status_code INT         NOT NULL DEFAULT 0,
+  error       TEXT        NOT NULL DEFAULT '',
+  duration_ms BIGINT      NOT NULL DEFAULT 0,
+  PRIMARY KEY (delivery_id, n)
+);
+`)
+	return err
+}
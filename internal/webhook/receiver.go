@@ -0,0 +1,84 @@
+// internal/webhook/receiver.go
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Receiver: endpoint merchant tiruan untuk test dan dev lokal
+// (cmd/webhook-receiver). Memverifikasi signature, menyimpan payload, dan
+// bisa diset gagal untuk beberapa request pertama untuk menguji retry.
+type Receiver struct {
+	Secret    string
+	Tolerance time.Duration
+
+	mu       sync.Mutex
+	failNext int
+	received []Payload
+	notify   chan struct{}
+}
+
+func NewReceiver(secret string) *Receiver {
+	return &Receiver{Secret: secret, Tolerance: DefaultTolerance, notify: make(chan struct{}, 1)}
+}
+
+// FailNext: n request berikutnya dijawab 503.
+func (rc *Receiver) FailNext(n int) {
+	rc.mu.Lock()
+	rc.failNext = n
+	rc.mu.Unlock()
+}
+
+// Received: salinan payload yang sudah diterima, berurutan.
+func (rc *Receiver) Received() []Payload {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]Payload(nil), rc.received...)
+}
+
+// Wait menunggu sampai minimal n payload diterima.
+func (rc *Receiver) Wait(n int, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		if len(rc.Received()) >= n {
+			return true
+		}
+		select {
+		case <-rc.notify:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := VerifyRequest(rc.Secret, r, rc.Tolerance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	rc.mu.Lock()
+	if rc.failNext > 0 {
+		rc.failNext--
+		rc.mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		rc.mu.Unlock()
+		http.Error(w, "bad payload", http.StatusBadRequest)
+		return
+	}
+	rc.received = append(rc.received, p)
+	rc.mu.Unlock()
+
+	select {
+	case rc.notify <- struct{}{}:
+	default:
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
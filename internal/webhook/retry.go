@@ -0,0 +1,41 @@
+// internal/webhook/retry.go
+package webhook
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Retry: exponential backoff dengan jitter, dibatasi MaxDelay, sampai
+// Window sejak delivery dibuat.
+type Retry struct {
+	BaseDelay time.Duration // default 30s
+	MaxDelay  time.Duration // default 6h
+	Window    time.Duration // default 72h
+}
+
+func (r Retry) withDefaults() Retry {
+	if r.BaseDelay <= 0 {
+		r.BaseDelay = 30 * time.Second
+	}
+	if r.MaxDelay <= 0 {
+		r.MaxDelay = 6 * time.Hour
+	}
+	if r.Window <= 0 {
+		r.Window = 72 * time.Hour
+	}
+	return r
+}
+
+// Delay: jeda sebelum percobaan ke-(attempts+1), setelah attempts gagal.
+// Jitter ±20% supaya endpoint yang baru pulih tidak diserbu bersamaan.
+func (r Retry) Delay(attempts int) time.Duration {
+	r = r.withDefaults()
+	d := r.BaseDelay
+	for i := 1; i < attempts && d < r.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, r.MaxDelay)
+	jitter := time.Duration(rand.Int64N(int64(d)/5*2+1)) - d/5
+	return d + jitter
+}
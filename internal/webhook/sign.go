@@ -0,0 +1,68 @@
+// internal/webhook/sign.go
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header yang dikirim bersama setiap delivery.
+const (
+	HeaderID        = "Webhook-Id"        // event id, sama untuk setiap retry
+	HeaderTimestamp = "Webhook-Timestamp" // unix seconds
+	HeaderSignature = "Webhook-Signature" // "v1=<hex hmac-sha256>"
+)
+
+// DefaultTolerance: selisih timestamp maksimum yang diterima Verify.
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrBadSignature = errors.New("webhook: signature mismatch")
+	ErrStale        = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign: HMAC-SHA256(secret, "<timestamp>.<body>"). Timestamp ikut ditandatangani
+// supaya request lama tidak bisa diputar ulang setelah tolerance lewat.
+func Sign(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts.Unix())
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa signature dan timestamp dari header delivery.
+func Verify(secret string, h http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	sec, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrBadSignature)
+	}
+	ts := time.Unix(sec, 0)
+	if d := now.Sub(ts); d > tolerance || d < -tolerance {
+		return ErrStale
+	}
+	want := Sign(secret, ts, body)
+	// header boleh berisi beberapa signature (mis. saat rotasi secret)
+	for _, sig := range strings.Split(h.Get(HeaderSignature), ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(sig)), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
+
+// VerifyRequest membaca body r lalu memanggil Verify.
+func VerifyRequest(secret string, r *http.Request, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return body, Verify(secret, r.Header, body, tolerance, time.Now())
+}
@@ -0,0 +1,41 @@
+// internal/webhook/sign_test.go
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	now := time.Unix(1_700_000_000, 0)
+	h := http.Header{}
+	h.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	h.Set(HeaderSignature, Sign("whsec_a", now, body))
+
+	if err := Verify("whsec_a", h, body, DefaultTolerance, now.Add(time.Minute)); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := Verify("whsec_b", h, body, DefaultTolerance, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong secret: got %v", err)
+	}
+	if err := Verify("whsec_a", h, []byte(`{"id":"evt-2"}`), DefaultTolerance, now); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered body: got %v", err)
+	}
+	if err := Verify("whsec_a", h, body, DefaultTolerance, now.Add(10*time.Minute)); !errors.Is(err, ErrStale) {
+		t.Fatalf("replayed request: got %v", err)
+	}
+}
+
+func TestRetryDelayCapped(t *testing.T) {
+	r := Retry{BaseDelay: time.Second, MaxDelay: time.Minute}
+	for n := 1; n < 40; n++ {
+		d := r.Delay(n)
+		if d < 800*time.Millisecond || d > 72*time.Second {
+			t.Fatalf("attempt %d: delay %s out of bounds", n, d)
+		}
+	}
+}
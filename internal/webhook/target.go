@@ -0,0 +1,83 @@
+// internal/webhook/target.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget: URL webhook mengarah ke alamat internal (loopback,
+// jaringan privat, link-local seperti metadata cloud 169.254.169.254, ...).
+// Tanpa cek ini merchant bisa menyuruh webhook-worker POST ke service
+// internal (SSRF).
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// cgnat: 100.64.0.0/10 (shared address space), tidak dicakup IsPrivate.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr: alamat unicast global yang boleh dihubungi.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+// CheckURL: validasi URL endpoint saat registrasi. Host di-resolve dan
+// semua alamatnya harus publik; allowPrivate hanya untuk dev/test dengan
+// receiver di jaringan yang sama. Deliverer mengecek ulang saat dial
+// karena DNS bisa berubah setelah registrasi.
+func CheckURL(ctx context.Context, raw string, allowPrivate bool) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return nil, fmt.Errorf("url %q: want http(s)://host/path", raw)
+	}
+	if allowPrivate {
+		return u, nil
+	}
+	host := u.Hostname()
+	var ips []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		ips = []netip.Addr{ip}
+	} else if ips, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, ip := range ips {
+		if !publicAddr(ip) {
+			return nil, fmt.Errorf("%s (%s): %w", host, ip, ErrForbiddenTarget)
+		}
+	}
+	return u, nil
+}
+
+// newClient: http.Client untuk delivery. Alamat dicek di Dialer.Control,
+// yaitu IP yang benar-benar dihubungi setelah resolve (termasuk saat
+// redirect), jadi DNS rebinding tidak lolos. Proxy dari environment tidak
+// dipakai supaya cek ini tidak terlewati.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(ap.Addr()) {
+				return fmt.Errorf("dial %s: %w", address, ErrForbiddenTarget)
+			}
+			return nil
+		}
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil
+	tr.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: tr}
+}
@@ -0,0 +1,59 @@
+// internal/webhook/target_test.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, raw := range []string{
+		"http://127.0.0.1/hooks",
+		"http://localhost:8080/hooks",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/hooks",
+		"http://192.168.1.1/hooks",
+		"http://100.64.0.1/hooks",
+		"http://0.0.0.0/hooks",
+		"http://[::1]/hooks",
+		"http://[fd00::1]/hooks",
+		"http://[::ffff:127.0.0.1]/hooks",
+	} {
+		if _, err := CheckURL(ctx, raw, false); !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("CheckURL(%q) = %v, want ErrForbiddenTarget", raw, err)
+		}
+	}
+	for _, raw := range []string{"ftp://example.com/x", "http:///hooks", "http://user:pw@203.0.113.10/"} {
+		if _, err := CheckURL(ctx, raw, false); err == nil || errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("CheckURL(%q) = %v, want invalid url", raw, err)
+		}
+	}
+	if _, err := CheckURL(ctx, "https://203.0.113.10/hooks", false); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+	if _, err := CheckURL(ctx, "http://127.0.0.1/hooks", true); err != nil {
+		t.Errorf("allowPrivate: %v", err)
+	}
+}
+
+// URL yang lolos registrasi lalu di-resolve ke alamat internal (DNS
+// rebinding) tetap ditolak saat dial.
+func TestClientRefusesPrivateDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := newClient(time.Second, false).Post(srv.URL, "application/json", nil)
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Fatalf("dial to %s: %v, want ErrForbiddenTarget", srv.URL, err)
+	}
+	resp, err := newClient(time.Second, true).Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
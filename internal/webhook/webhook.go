@@ -0,0 +1,108 @@
+// internal/webhook/webhook.go
+// Package webhook delivers final payment outcomes to merchant endpoints.
+//
+// The dispatcher consumes payments.result and queues one Delivery per active
+// endpoint of the payment's merchant; the Deliverer posts queued deliveries,
+// signed with the endpoint secret, and retries with backoff until the
+// delivery succeeds or its retry window (72h by default) runs out. Every
+// attempt is kept in the delivery log.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("webhook: not found")
+	ErrInvalid  = errors.New("webhook: invalid endpoint")
+)
+
+// Delivery status.
+const (
+	StatusPending   = "PENDING"
+	StatusDelivered = "DELIVERED"
+	StatusFailed    = "FAILED" // retry window habis
+)
+
+// Event type pada payload webhook.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+)
+
+// Endpoint: URL milik merchant yang menerima webhook. Secret hanya
+// dikembalikan sekali, saat endpoint dibuat.
+type Endpoint struct {
+	ID         string    `json:"id"`
+	MerchantID string    `json:"merchant_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Delivery: satu event untuk satu endpoint.
+type Delivery struct {
+	ID             string     `json:"id"`
+	EndpointID     string     `json:"endpoint_id"`
+	MerchantID     string     `json:"merchant_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Attempt: satu percobaan kirim, untuk delivery log.
+type Attempt struct {
+	DeliveryID string        `json:"delivery_id"`
+	N          int           `json:"n"`
+	At         time.Time     `json:"at"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration_ns"`
+}
+
+// Store menyimpan endpoint, antrian delivery dan delivery log.
+type Store interface {
+	CreateEndpoint(ctx context.Context, e *Endpoint) error
+	GetEndpoint(ctx context.Context, id string) (*Endpoint, error)
+	ListEndpoints(ctx context.Context, merchantID string) ([]Endpoint, error)
+	DisableEndpoint(ctx context.Context, merchantID, id string) error
+
+	// Enqueue mengabaikan delivery yang sudah ada untuk (endpoint, event),
+	// jadi result yang dibaca ulang dari Kafka tidak terkirim dua kali.
+	Enqueue(ctx context.Context, ds []Delivery) error
+	// Claim mengambil delivery PENDING yang jatuh tempo dan menundanya
+	// selama lease, supaya deliverer lain tidak mengirim yang sama.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+	// Record menyimpan hasil percobaan: status/attempts/next_attempt_at dari d
+	// dan satu baris Attempt.
+	Record(ctx context.Context, d *Delivery, a Attempt) error
+
+	GetDelivery(ctx context.Context, merchantID, id string) (*Delivery, []Attempt, error)
+	ListDeliveries(ctx context.Context, merchantID string, limit int) ([]Delivery, error)
+	// Redeliver menjadwalkan ulang delivery (status apa pun) sekarang, dengan
+	// retry window baru sampai expiresAt.
+	Redeliver(ctx context.Context, merchantID, id string, now, expiresAt time.Time) (*Delivery, error)
+}
+
+// NewSecret: secret acak 32 byte, hex, prefix "whsec_".
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,165 @@
+// internal/worker/batch.go
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+)
+
+var (
+	batchSize = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "batch_size",
+			Help:      "Payments settled per DB transaction",
+			Buckets:   []float64{1, 2, 4, 8, 16, 32, 64, 128, 256},
+		},
+	)
+	batchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "batch_duration_seconds",
+			Help:      "Latency of one batch transaction, including per-item fallback",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"mode"}, // batch | fallback
+	)
+	batchFallbacks = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "batch_fallback_total",
+			Help:      "Batches that failed and were retried item by item",
+		},
+	)
+)
+
+type batchItem struct {
+	p    Payment
+	done chan batchDone
+	// span settle pemanggil; span batch menautkan semuanya
+	span trace.SpanContext
+}
+
+type batchDone struct {
+	res Result
+	err error
+}
+
+// Batcher groups concurrent Settle calls (one per lane) into a single
+// transaction of up to Size payments, waiting at most Wait for the batch to
+// fill. Lanes block until their own payment is committed, so per-key
+// ordering from the pool still holds.
+type Batcher struct {
+	store batchStore
+	size  int
+	wait  time.Duration
+	reqs  chan *batchItem
+}
+
+// batchStore: bagian Store yang dipakai Batcher.
+type batchStore interface {
+	Settler
+	SettleBatch(ctx context.Context, ps []Payment) ([]Result, error)
+}
+
+func NewBatcher(store *Store, size int, wait time.Duration) *Batcher {
+	return newBatcher(store, size, wait)
+}
+
+func newBatcher(store batchStore, size int, wait time.Duration) *Batcher {
+	if size <= 0 {
+		size = 1
+	}
+	return &Batcher{store: store, size: size, wait: wait, reqs: make(chan *batchItem, size)}
+}
+
+func (b *Batcher) Settle(ctx context.Context, p Payment) (Result, error) {
+	it := &batchItem{p: p, done: make(chan batchDone, 1), span: trace.SpanContextFromContext(ctx)}
+	select {
+	case b.reqs <- it:
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+	select {
+	case d := <-it.done:
+		return d.res, d.err
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+// Run collects and flushes batches until ctx is canceled.
+func (b *Batcher) Run(ctx context.Context) {
+	for {
+		var first *batchItem
+		select {
+		case first = <-b.reqs:
+		case <-ctx.Done():
+			return
+		}
+
+		batch := []*batchItem{first}
+		timer := time.NewTimer(b.wait)
+	collect:
+		for len(batch) < b.size {
+			select {
+			case it := <-b.reqs:
+				batch = append(batch, it)
+			case <-timer.C:
+				break collect
+			case <-ctx.Done():
+				break collect
+			}
+		}
+		timer.Stop()
+		b.flush(ctx, batch)
+	}
+}
+
+func (b *Batcher) flush(ctx context.Context, batch []*batchItem) {
+	start := time.Now()
+	batchSize.Observe(float64(len(batch)))
+
+	ps := make([]Payment, len(batch))
+	links := make([]trace.Link, 0, len(batch))
+	for i, it := range batch {
+		ps[i] = it.p
+		if it.span.IsValid() {
+			links = append(links, trace.Link{SpanContext: it.span})
+		}
+	}
+	// satu transaksi untuk banyak trace: span sendiri, ditautkan (link)
+	// ke span settle tiap payment
+	ctx, span := tracing.Start(ctx, "settle batch", trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("payment.batch_size", len(batch))))
+	defer span.End()
+	res, err := b.store.SettleBatch(ctx, ps)
+	if err == nil {
+		batchDuration.WithLabelValues("batch").Observe(time.Since(start).Seconds())
+		for i, it := range batch {
+			it.done <- batchDone{res: res[i]}
+		}
+		return
+	}
+
+	// satu payment jelek jangan meracuni sisanya: ulangi satu per satu
+	batchFallbacks.Inc()
+	span.RecordError(err)
+	slog.WarnContext(ctx, "batch failed, settling one by one", "batch_size", len(batch), "error", err)
+	for _, it := range batch {
+		r, e := b.store.Settle(ctx, it.p)
+		it.done <- batchDone{res: r, err: e}
+	}
+	batchDuration.WithLabelValues("fallback").Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,267 @@
+// internal/worker/batch_test.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeBatchStore: SettleBatch gagal bila ada payment "bad-*" di batch;
+// Settle per item hanya gagal untuk payment itu sendiri.
+type fakeBatchStore struct {
+	mu      sync.Mutex
+	batches [][]string
+	singles []string
+}
+
+func (s *fakeBatchStore) SettleBatch(_ context.Context, ps []Payment) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, len(ps))
+	out := make([]Result, len(ps))
+	var err error
+	for i, p := range ps {
+		keys[i] = p.IdempotencyKey
+		out[i] = Result{Status: "SUCCESS", Ref: p.IdempotencyKey}
+		if strings.HasPrefix(p.IdempotencyKey, "bad") {
+			err = errors.New("deadlock detected")
+		}
+	}
+	s.batches = append(s.batches, keys)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *fakeBatchStore) Settle(_ context.Context, p Payment) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.singles = append(s.singles, p.IdempotencyKey)
+	if strings.HasPrefix(p.IdempotencyKey, "bad") {
+		return Result{}, errors.New("deadlock detected")
+	}
+	return Result{Status: "SUCCESS", Ref: p.IdempotencyKey}, nil
+}
+
+type settled struct {
+	res Result
+	err error
+}
+
+// settleAll: Settle paralel (satu goroutine per lane) lewat Batcher yang
+// sedang Run; hasil per idempotency key.
+func settleAll(t *testing.T, b *Batcher, keys ...string) map[string]settled {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runCtx, stop := context.WithCancel(context.Background())
+	ran := make(chan struct{})
+	go func() { b.Run(runCtx); close(ran) }()
+	defer func() { stop(); <-ran }()
+
+	var mu sync.Mutex
+	out := map[string]settled{}
+	var wg sync.WaitGroup
+	for _, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := b.Settle(ctx, Payment{IdempotencyKey: k})
+			mu.Lock()
+			out[k] = settled{res, err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		t.Fatal("batch not flushed")
+	}
+	return out
+}
+
+// batch penuh di-flush tanpa menunggu linger
+func TestBatcherFlushesFullBatch(t *testing.T) {
+	st := &fakeBatchStore{}
+	got := settleAll(t, newBatcher(st, 2, time.Hour), "k1", "k2", "k3", "k4")
+
+	for k, s := range got {
+		if s.err != nil || s.res.Ref != k {
+			t.Errorf("%s: %+v %v", k, s.res, s.err)
+		}
+	}
+	if len(st.batches) != 2 || len(st.batches[0]) != 2 || len(st.batches[1]) != 2 {
+		t.Fatalf("batches %v, want 2 of 2", st.batches)
+	}
+	if len(st.singles) != 0 {
+		t.Fatalf("per-item settles %v, want none", st.singles)
+	}
+}
+
+// batch yang tidak penuh di-flush setelah wait
+func TestBatcherFlushesAfterWait(t *testing.T) {
+	st := &fakeBatchStore{}
+	wait := 20 * time.Millisecond
+	start := time.Now()
+	got := settleAll(t, newBatcher(st, 10, wait), "k1")
+
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Errorf("flushed after %v, before wait %v", elapsed, wait)
+	}
+	if s := got["k1"]; s.err != nil || s.res.Status != "SUCCESS" {
+		t.Fatalf("k1: %+v %v", s.res, s.err)
+	}
+	if len(st.batches) != 1 || len(st.batches[0]) != 1 {
+		t.Fatalf("batches %v, want [[k1]]", st.batches)
+	}
+}
+
+// satu payment yang membuat transaksi batch gagal: sisanya tetap settle
+// satu per satu, error hanya untuk payment itu
+func TestBatcherFallsBackPerItem(t *testing.T) {
+	st := &fakeBatchStore{}
+	got := settleAll(t, newBatcher(st, 3, time.Hour), "k1", "bad-1", "k2")
+
+	for _, k := range []string{"k1", "k2"} {
+		if s := got[k]; s.err != nil || s.res.Ref != k {
+			t.Errorf("%s: %+v %v", k, s.res, s.err)
+		}
+	}
+	if got["bad-1"].err == nil {
+		t.Error("bad-1: no error")
+	}
+	if len(st.batches) != 1 || len(st.singles) != 3 {
+		t.Fatalf("batches %v singles %v, want one batch then 3 singles", st.batches, st.singles)
+	}
+}
+
+// memDB: tabel wallet_accounts dan payments di memori.
+type memDB struct {
+	balances map[string]int64  // account_id → balance_idr
+	owners   map[string]string // account_id → merchant_id
+	payments map[string]string // idempotency_key → status
+}
+
+func (db *memDB) clone() *memDB {
+	c := &memDB{balances: map[string]int64{}, owners: db.owners, payments: map[string]string{}}
+	for k, v := range db.balances {
+		c.balances[k] = v
+	}
+	for k, v := range db.payments {
+		c.payments[k] = v
+	}
+	return c
+}
+
+// memTx: pgx.Tx untuk settleTx. Begin = savepoint: snapshot dipulihkan
+// bila Rollback sebelum Commit. Method lain tidak dipakai settleTx.
+type memTx struct {
+	pgx.Tx
+	db   *memDB
+	snap *memDB
+	done bool
+}
+
+func (t *memTx) Begin(context.Context) (pgx.Tx, error) {
+	return &memTx{db: t.db, snap: t.db.clone()}, nil
+}
+
+func (t *memTx) Commit(context.Context) error {
+	t.done = true
+	return nil
+}
+
+func (t *memTx) Rollback(context.Context) error {
+	if !t.done && t.snap != nil {
+		*t.db = *t.snap
+	}
+	t.done = true
+	return nil
+}
+
+func (t *memTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db := t.db
+	switch {
+	case strings.Contains(sql, "balance_idr - $2"):
+		acc, amt, m := args[0].(string), args[1].(int64), args[2].(string)
+		if bal, ok := db.balances[acc]; !ok || bal < amt || db.owners[acc] != m {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		db.balances[acc] -= amt
+	case strings.Contains(sql, "balance_idr + $2"):
+		acc, amt, m := args[0].(string), args[1].(int64), args[2].(string)
+		if _, ok := db.balances[acc]; !ok || db.owners[acc] != m {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		db.balances[acc] += amt
+	case strings.Contains(sql, "INSERT INTO payments"):
+		db.payments[args[1].(string)] = args[6].(string)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	default:
+		return pgconn.CommandTag{}, errors.New("unexpected statement: " + sql)
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+type memRow struct {
+	vals []string
+	err  error
+}
+
+func (r memRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		*d.(*string) = r.vals[i]
+	}
+	return nil
+}
+
+func (t *memTx) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	status, ok := t.db.payments[args[0].(string)]
+	if !ok {
+		return memRow{err: pgx.ErrNoRows}
+	}
+	return memRow{vals: []string{"pay-" + args[0].(string), status}}
+}
+
+// Dalam satu transaksi batch, payment yang gagal di tengah jalan (debit
+// sudah jalan, receiver tidak ada) hanya membatalkan savepoint-nya sendiri.
+func TestSettleTxSavepointIsolatesFailedPayment(t *testing.T) {
+	db := &memDB{
+		balances: map[string]int64{"A": 1000, "B": 0, "C": 0},
+		owners:   map[string]string{"A": "M-1", "B": "M-1", "C": "M-2"},
+		payments: map[string]string{},
+	}
+	tx := &memTx{db: db}
+	ctx := context.Background()
+	want := []Result{{Status: "SUCCESS"}, {Status: "FAILED", Reason: "receiver_not_found"}, {Status: "SUCCESS"}}
+	for i, p := range []Payment{
+		{IdempotencyKey: "p1", SenderID: "A", ReceiverID: "B", AmountIDR: 300, MerchantID: "M-1"},
+		{IdempotencyKey: "p2", SenderID: "A", ReceiverID: "C", AmountIDR: 300, MerchantID: "M-1"},
+		// hanya cukup bila debit p2 sudah dibatalkan
+		{IdempotencyKey: "p3", SenderID: "A", ReceiverID: "B", AmountIDR: 700, MerchantID: "M-1"},
+	} {
+		res, err := settleTx(ctx, tx, p)
+		if err != nil {
+			t.Fatalf("%s: %v", p.IdempotencyKey, err)
+		}
+		if res.Status != want[i].Status || res.Reason != want[i].Reason {
+			t.Fatalf("%s: %+v, want %+v", p.IdempotencyKey, res, want[i])
+		}
+	}
+	if db.balances["A"] != 0 || db.balances["B"] != 1000 || db.balances["C"] != 0 {
+		t.Fatalf("balances %v", db.balances)
+	}
+	if len(db.payments) != 3 || db.payments["p2"] != "FAILED" {
+		t.Fatalf("payments %v", db.payments)
+	}
+}
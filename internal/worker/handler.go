@@ -0,0 +1,101 @@
+// internal/worker/handler.go
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/example/payment-gateway-poc/pkg/events"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+const producerName = "payments-worker"
+
+// Replier publishes a result for the gateway, keyed by idempotency key.
+// queue.Bus satisfies it.
+type Replier interface {
+	Reply(ctx context.Context, key, payload []byte) error
+}
+
+// SettleHandler decodes a payments.request message, settles it and replies
+// on the result topic. Only business outcomes (insufficient funds, ...) are
+// replied; database or broker errors come back Retryable so the pool
+// redelivers the message instead of reporting the payment as FAILED.
+func SettleHandler(s Settler, out Replier) Handler {
+	return func(ctx context.Context, m kafka.Message) error {
+		ev, err := events.DecodeRequested(m.Value)
+		if err != nil {
+			return fmt.Errorf("bad msg: %w", err)
+		}
+		in := Payment{
+			IdempotencyKey: ev.GetIdempotencyKey(),
+			SenderID:       ev.GetSenderId(),
+			ReceiverID:     ev.GetReceiverId(),
+			AmountIDR:      ev.GetAmountIdr(),
+			CurrencyInput:  ev.GetCurrencyInput(),
+			TxDate:         ev.GetTxDate(),
+			MerchantID:     ev.GetMerchantId(),
+			FeeIDR:         ev.GetFeeIdr(),
+			// SettlementCurrency kosong (event lama): IDR, lihat settleTx
+			SettlementCurrency: ev.GetSettlementCurrency(),
+			SettlementAmount:   ev.GetSettlementAmount(),
+		}
+		// payment_id gateway datang dari header; idempotency key juga ada di event
+		ctx = logging.With(ctx, logging.FieldIdempotencyKey, in.IdempotencyKey)
+
+		sctx, span := tracing.Start(ctx, "settle", trace.WithAttributes(
+			attribute.String("payment.idempotency_key", in.IdempotencyKey),
+			attribute.String("payment.merchant_id", in.MerchantID),
+			attribute.Int64("payment.amount_idr", in.AmountIDR)))
+		res, settleErr := s.Settle(sctx, in)
+		span.SetAttributes(attribute.String("payment.id", res.Ref), attribute.String("payment.status", res.Status))
+		if settleErr != nil {
+			span.RecordError(settleErr)
+			span.SetStatus(codes.Error, settleErr.Error())
+		}
+		span.End()
+		if settleErr != nil {
+			// gangguan infrastruktur, bukan penolakan bisnis: jangan balas
+			// FAILED; pool mengulang message ini dan offset-nya tidak di-commit
+			return Retryable(fmt.Errorf("settle %s: %w", in.IdempotencyKey, settleErr))
+		}
+		slog.InfoContext(ctx, "payment settled", "status", res.Status, "reason", res.Reason,
+			"ref", res.Ref, "merchant_id", in.MerchantID, "amount_idr", in.AmountIDR, "fee_idr", in.FeeIDR)
+
+		// request di-key per sender (urutan per akun); result di-key per idempotency key
+		key := in.IdempotencyKey
+		if key == "" {
+			key = string(m.Key)
+		}
+		b, err := events.EncodeResult(resultEvent(key, in, res), producerName)
+		if err != nil {
+			return fmt.Errorf("encode result: %w", err)
+		}
+		if err := out.Reply(ctx, []byte(key), b); err != nil {
+			// settlement idempotent: ulangan menghasilkan SUCCESS_REPLAY
+			return Retryable(fmt.Errorf("write err: %w", err))
+		}
+		return nil
+	}
+}
+
+func resultEvent(key string, p Payment, r Result) events.Result {
+	if r.Status == "FAILED" {
+		return &eventsv1.PaymentFailed{
+			IdempotencyKey: key, PaymentId: r.Ref, Reason: r.Reason,
+			MerchantId: p.MerchantID, SenderId: p.SenderID, ReceiverId: p.ReceiverID,
+		}
+	}
+	return &eventsv1.PaymentSettled{
+		IdempotencyKey: key, PaymentId: r.Ref, Status: r.Status,
+		MerchantId: p.MerchantID, SenderId: p.SenderID, ReceiverId: p.ReceiverID,
+	}
+}
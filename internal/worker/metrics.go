@@ -0,0 +1,56 @@
+// internal/worker/metrics.go
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	laneInflight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "lane_inflight",
+			Help:      "Messages queued or being processed per lane",
+		},
+		[]string{"lane"},
+	)
+	laneMessages = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "lane_messages_total",
+			Help:      "Messages processed per lane",
+		},
+		[]string{"lane", "status"},
+	)
+	laneRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "lane_retries_total",
+			Help:      "Messages retried after a transient (infrastructure) failure",
+		},
+		[]string{"lane"},
+	)
+	laneDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "lane_duration_seconds",
+			Help:      "Time spent handling one message per lane",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"lane"},
+	)
+	committedOffset = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "payment",
+			Subsystem: "worker",
+			Name:      "committed_offset",
+			Help:      "Last offset committed per topic partition",
+		},
+		[]string{"topic", "partition"},
+	)
+)
@@ -0,0 +1,101 @@
+// internal/worker/offsets.go
+package worker
+
+import "sync"
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// partitionOffsets tracks which fetched offsets of one partition are still
+// being processed. The committable offset is always the lowest in-flight
+// offset, so a slow lane can never let a later offset be committed past it.
+type partitionOffsets struct {
+	inflight  map[int64]struct{}
+	highest   int64 // highest offset fetched so far
+	committed int64 // next offset to consume, as last accepted by Kafka
+}
+
+type offsetTracker struct {
+	mu    sync.Mutex
+	parts map[topicPartition]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{parts: map[topicPartition]*partitionOffsets{}}
+}
+
+// begin marks an offset as fetched and in flight.
+func (t *offsetTracker) begin(topic string, partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tp := topicPartition{topic, partition}
+	p, ok := t.parts[tp]
+	if !ok {
+		p = &partitionOffsets{inflight: map[int64]struct{}{}, highest: offset - 1, committed: offset}
+		t.parts[tp] = p
+	}
+	p.inflight[offset] = struct{}{}
+	if offset > p.highest {
+		p.highest = offset
+	}
+}
+
+// done marks an offset as fully processed.
+func (t *offsetTracker) done(topic string, partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.parts[topicPartition{topic, partition}]; ok {
+		delete(p.inflight, offset)
+	}
+}
+
+// advance returns, per partition, the next offset to consume whenever it is
+// ahead of the last successful commit. Nothing is recorded here: the caller
+// must call committed after CommitMessages succeeds, so a failed commit is
+// retried on the next call.
+func (t *offsetTracker) advance() map[topicPartition]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := map[topicPartition]int64{}
+	for tp, p := range t.parts {
+		next := p.highest + 1
+		for off := range p.inflight {
+			if off < next {
+				next = off
+			}
+		}
+		if next > p.committed {
+			out[tp] = next
+		}
+	}
+	return out
+}
+
+// committed records offsets returned by advance once Kafka accepted them.
+func (t *offsetTracker) committed(next map[topicPartition]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for tp, off := range next {
+		if p, ok := t.parts[tp]; ok && off > p.committed {
+			p.committed = off
+		}
+	}
+}
+
+// inflight returns the number of offsets still being processed.
+func (t *offsetTracker) inflight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, p := range t.parts {
+		n += len(p.inflight)
+	}
+	return n
+}
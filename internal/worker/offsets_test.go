@@ -0,0 +1,44 @@
+// internal/worker/offsets_test.go
+package worker
+
+import "testing"
+
+func TestOffsetTrackerCommitsBelowLowestInflight(t *testing.T) {
+	tr := newOffsetTracker()
+	for off := int64(10); off <= 14; off++ {
+		tr.begin("payments.request", 0, off)
+	}
+
+	// 11 dan 13 selesai duluan, 10 masih jalan → belum boleh commit
+	tr.done("payments.request", 0, 11)
+	tr.done("payments.request", 0, 13)
+	if got := tr.advance(); len(got) != 0 {
+		t.Fatalf("expected no commit while offset 10 in flight, got %v", got)
+	}
+
+	tr.done("payments.request", 0, 10)
+	got := tr.advance()[topicPartition{"payments.request", 0}]
+	if got != 12 {
+		t.Fatalf("expected next offset 12, got %d", got)
+	}
+	// commit ke Kafka gagal: offset yang sama ditawarkan lagi
+	if got := tr.advance()[topicPartition{"payments.request", 0}]; got != 12 {
+		t.Fatalf("expected offset 12 again after failed commit, got %d", got)
+	}
+	tr.committed(map[topicPartition]int64{{"payments.request", 0}: 12})
+	if got := tr.advance(); len(got) != 0 {
+		t.Fatalf("expected nothing new after commit, got %v", got)
+	}
+
+	tr.done("payments.request", 0, 12)
+	tr.done("payments.request", 0, 14)
+	next := tr.advance()
+	tr.committed(next)
+	got = next[topicPartition{"payments.request", 0}]
+	if got != 15 {
+		t.Fatalf("expected next offset 15, got %d", got)
+	}
+	if n := tr.inflight(); n != 0 {
+		t.Fatalf("expected nothing in flight, got %d", n)
+	}
+}
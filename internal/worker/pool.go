@@ -0,0 +1,244 @@
+// internal/worker/pool.go
+// Package worker runs settlement messages from Kafka on a pool of lanes.
+// Messages with the same key (sender account) always land on the same lane,
+// so they are handled in order while different accounts run in parallel.
+package worker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+)
+
+// Reader is the part of *kafka.Reader the pool needs. Offsets are committed
+// by the pool itself, so the reader must belong to a consumer group.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Handler processes one message. A returned error is counted and logged;
+// the message is still treated as done, same as the old single loop did,
+// unless the error is marked with Retryable.
+type Handler func(ctx context.Context, m kafka.Message) error
+
+type Config struct {
+	Concurrency    int           // number of lanes (default 8)
+	LaneBuffer     int           // messages queued per lane (default 64)
+	CommitInterval time.Duration // how often offsets are committed (default 1s)
+	RetryBase      time.Duration // first backoff for Retryable errors (default 100ms)
+	RetryMax       time.Duration // backoff cap (default 10s)
+}
+
+type retryableError struct{ err error }
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// Retryable marks a transient failure (database or broker down). The pool
+// runs the message again on the same lane with exponential backoff until it
+// succeeds or the pool stops; meanwhile the lane is blocked, so per-key order
+// holds, and the offset stays uncommitted, so a restart redelivers it.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err}
+}
+
+func isRetryable(err error) bool {
+	var r retryableError
+	return errors.As(err, &r)
+}
+
+type Pool struct {
+	cfg     Config
+	handle  Handler
+	offsets *offsetTracker
+}
+
+func NewPool(cfg Config, h Handler) *Pool {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 8
+	}
+	if cfg.LaneBuffer <= 0 {
+		cfg.LaneBuffer = 64
+	}
+	if cfg.CommitInterval <= 0 {
+		cfg.CommitInterval = time.Second
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 100 * time.Millisecond
+	}
+	if cfg.RetryMax < cfg.RetryBase {
+		cfg.RetryMax = max(10*time.Second, cfg.RetryBase)
+	}
+	return &Pool{cfg: cfg, handle: h, offsets: newOffsetTracker()}
+}
+
+// Run fetches from r until ctx is canceled or the reader fails. Offsets are
+// only committed up to the lowest message still in flight, so anything not
+// finished at shutdown is redelivered.
+func (p *Pool) Run(ctx context.Context, r Reader) error {
+	lanes := make([]chan kafka.Message, p.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range lanes {
+		lanes[i] = make(chan kafka.Message, p.cfg.LaneBuffer)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.runLane(ctx, i, lanes[i])
+		}(i)
+	}
+
+	commitDone := make(chan struct{})
+	stopCommit := make(chan struct{})
+	go func() {
+		defer close(commitDone)
+		t := time.NewTicker(p.cfg.CommitInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				p.commit(ctx, r)
+			case <-stopCommit:
+				return
+			}
+		}
+	}()
+
+	var err error
+	for {
+		m, e := r.FetchMessage(ctx)
+		if e != nil {
+			if ctx.Err() == nil {
+				err = e
+			}
+			break
+		}
+		p.offsets.begin(m.Topic, m.Partition, m.Offset)
+		lane := p.laneFor(m)
+		laneInflight.WithLabelValues(strconv.Itoa(lane)).Inc()
+		lanes[lane] <- m
+	}
+
+	for _, ch := range lanes {
+		close(ch)
+	}
+	wg.Wait()
+	close(stopCommit)
+	<-commitDone
+
+	// final commit: ctx is usually canceled by now
+	fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p.commit(fctx, r)
+	if n := p.offsets.inflight(); n > 0 {
+		slog.Warn("worker stopped with uncommitted messages", "count", n)
+	}
+	return err
+}
+
+func (p *Pool) runLane(ctx context.Context, lane int, ch <-chan kafka.Message) {
+	label := strconv.Itoa(lane)
+	for m := range ch {
+		laneInflight.WithLabelValues(label).Dec()
+		if ctx.Err() != nil {
+			// shutting down: leave it uncommitted so it is redelivered
+			continue
+		}
+
+		err := p.handleWithRetry(ctx, lane, m)
+		if ctx.Err() != nil {
+			continue
+		}
+
+		status := "SUCCESS"
+		if err != nil {
+			status = "FAILED"
+		}
+		laneMessages.WithLabelValues(label, status).Inc()
+		p.offsets.done(m.Topic, m.Partition, m.Offset)
+	}
+}
+
+// handleWithRetry runs the handler, repeating Retryable failures with
+// backoff. It returns early only when ctx is canceled; the caller then leaves
+// the message uncommitted.
+func (p *Pool) handleWithRetry(ctx context.Context, lane int, m kafka.Message) error {
+	label := strconv.Itoa(lane)
+	delay := p.cfg.RetryBase
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		// lanjutkan trace dan field log producer dari header message
+		hctx, span := tracing.StartConsume(logging.Extract(ctx, m), m)
+		err := p.handle(hctx, m)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		laneDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+		if ctx.Err() != nil {
+			return err
+		}
+		if !isRetryable(err) {
+			if err != nil {
+				slog.ErrorContext(hctx, "worker: handle failed", "lane", lane,
+					"partition", m.Partition, "offset", m.Offset, "error", err)
+			}
+			return err
+		}
+
+		laneRetries.WithLabelValues(label).Inc()
+		slog.WarnContext(hctx, "worker: transient failure, retrying", "lane", lane,
+			"partition", m.Partition, "offset", m.Offset, "attempt", attempt, "backoff", delay.String(), "error", err)
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+		delay = min(delay*2, p.cfg.RetryMax)
+	}
+}
+
+func (p *Pool) laneFor(m kafka.Message) int {
+	if len(m.Key) == 0 {
+		return m.Partition % p.cfg.Concurrency
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(m.Key)
+	return int(h.Sum32() % uint32(p.cfg.Concurrency))
+}
+
+func (p *Pool) commit(ctx context.Context, r Reader) {
+	next := p.offsets.advance()
+	if len(next) == 0 {
+		return
+	}
+	msgs := make([]kafka.Message, 0, len(next))
+	for tp, off := range next {
+		// CommitMessages commits Offset+1
+		msgs = append(msgs, kafka.Message{Topic: tp.topic, Partition: tp.partition, Offset: off - 1})
+	}
+	if err := r.CommitMessages(ctx, msgs...); err != nil {
+		slog.ErrorContext(ctx, "worker: commit failed", "error", err)
+		return
+	}
+	p.offsets.committed(next)
+	for tp, off := range next {
+		committedOffset.WithLabelValues(tp.topic, strconv.Itoa(tp.partition)).Set(float64(off))
+	}
+}
@@ -0,0 +1,273 @@
+// internal/worker/pool_test.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader: kirim msgs lalu menunggu ctx; mencatat offset yang di-commit.
+type fakeReader struct {
+	msgs chan kafka.Message
+
+	mu        sync.Mutex
+	committed map[int]int64 // partition → offset message terakhir yang di-commit
+}
+
+func newFakeReader(msgs ...kafka.Message) *fakeReader {
+	r := &fakeReader{msgs: make(chan kafka.Message, len(msgs)), committed: map[int]int64{}}
+	for _, m := range msgs {
+		r.msgs <- m
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.msgs:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range msgs {
+		r.committed[m.Partition] = m.Offset
+	}
+	return nil
+}
+
+func (r *fakeReader) offset(partition int) (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	off, ok := r.committed[partition]
+	return off, ok
+}
+
+func runPool(t *testing.T, h Handler, r Reader, until func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	p := NewPool(Config{Concurrency: 2, CommitInterval: 10 * time.Millisecond, RetryBase: time.Millisecond, RetryMax: 5 * time.Millisecond}, h)
+	go func() { done <- p.Run(ctx, r) }()
+	for deadline := time.Now().Add(5 * time.Second); !until(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Postgres sempat down: message diulang di lane yang sama, lalu di-commit.
+func TestPoolRetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	h := func(context.Context, kafka.Message) error {
+		if calls.Add(1) <= 2 {
+			return Retryable(errors.New("conn refused"))
+		}
+		return nil
+	}
+	r := newFakeReader(kafka.Message{Topic: "payments.request", Partition: 0, Offset: 7, Key: []byte("u1")})
+	runPool(t, h, r, func() bool { _, ok := r.offset(0); return ok })
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("handler called %d times, want 3", n)
+	}
+	if off, _ := r.offset(0); off != 7 {
+		t.Errorf("committed offset %d, want 7", off)
+	}
+}
+
+// Gangguan yang tidak pulih: offset tidak pernah di-commit, message
+// berikutnya di partisi yang sama juga tidak, supaya restart mengulangnya.
+func TestPoolKeepsRetryableUncommitted(t *testing.T) {
+	var calls atomic.Int32
+	h := func(_ context.Context, m kafka.Message) error {
+		if m.Offset == 1 {
+			calls.Add(1)
+			return Retryable(errors.New("conn refused"))
+		}
+		return nil
+	}
+	r := newFakeReader(
+		kafka.Message{Topic: "payments.request", Partition: 0, Offset: 0, Key: []byte("a")},
+		kafka.Message{Topic: "payments.request", Partition: 0, Offset: 1, Key: []byte("b")},
+		kafka.Message{Topic: "payments.request", Partition: 0, Offset: 2, Key: []byte("c")},
+	)
+	runPool(t, h, r, func() bool { return calls.Load() >= 3 })
+
+	if off, _ := r.offset(0); off != 0 {
+		t.Errorf("committed offset %d, want 0", off)
+	}
+}
+
+// Error biasa (message rusak) tidak diulang dan tetap di-commit.
+func TestPoolDoesNotRetryPermanentErrors(t *testing.T) {
+	var calls atomic.Int32
+	h := func(context.Context, kafka.Message) error {
+		calls.Add(1)
+		return errors.New("bad msg")
+	}
+	r := newFakeReader(kafka.Message{Topic: "payments.request", Partition: 0, Offset: 3})
+	runPool(t, h, r, func() bool { _, ok := r.offset(0); return ok })
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+}
+
+// Banyak lane, key diselang-seling: message satu key diproses berurutan dan
+// tidak pernah bersamaan, key lain tetap paralel.
+func TestPoolPreservesPerKeyOrder(t *testing.T) {
+	const keys, perKey = 12, 25
+	var (
+		mu      sync.Mutex
+		seen    = map[string][]int{}
+		active  = map[string]bool{}
+		running int
+		peak    int
+		overlap []string
+	)
+	h := func(_ context.Context, m kafka.Message) error {
+		k := string(m.Key)
+		mu.Lock()
+		if active[k] {
+			overlap = append(overlap, k)
+		}
+		active[k] = true
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(time.Duration(m.Offset%3) * time.Millisecond)
+
+		seq, _ := strconv.Atoi(string(m.Value))
+		mu.Lock()
+		seen[k] = append(seen[k], seq)
+		active[k] = false
+		running--
+		mu.Unlock()
+		return nil
+	}
+
+	var msgs []kafka.Message
+	for seq := 0; seq < perKey; seq++ {
+		for k := 0; k < keys; k++ {
+			msgs = append(msgs, kafka.Message{Topic: "payments.request", Partition: 0, Offset: int64(len(msgs)),
+				Key: []byte("acc-" + strconv.Itoa(k)), Value: []byte(strconv.Itoa(seq))})
+		}
+	}
+	last := int64(len(msgs) - 1)
+	r := newFakeReader(msgs...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	p := NewPool(Config{Concurrency: 8, CommitInterval: 5 * time.Millisecond}, h)
+	go func() { done <- p.Run(ctx, r) }()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if off, ok := r.offset(0); ok && off == last {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(overlap) > 0 {
+		t.Errorf("same key handled concurrently: %v", overlap)
+	}
+	if peak < 2 {
+		t.Errorf("peak concurrency %d, want lanes in parallel", peak)
+	}
+	for k, got := range seen {
+		if len(got) != perKey {
+			t.Fatalf("%s: %d messages, want %d", k, len(got), perKey)
+		}
+		for i, seq := range got {
+			if seq != i {
+				t.Fatalf("%s: order %v", k, got)
+			}
+		}
+	}
+}
+
+// Offset lambat di tengah partisi: yang sesudahnya selesai lebih dulu, tapi
+// commit tidak boleh melewati offset yang masih jalan.
+func TestPoolCommitStopsAtLowestInflight(t *testing.T) {
+	p := NewPool(Config{Concurrency: 8, CommitInterval: 5 * time.Millisecond}, nil)
+	slowKey := []byte("slow")
+	msgs := []kafka.Message{}
+	for i, k := 0, 0; len(msgs) < 6; k++ {
+		m := kafka.Message{Topic: "payments.request", Partition: 0, Offset: int64(i), Key: []byte("acc-" + strconv.Itoa(k))}
+		if i == 3 {
+			m.Key = slowKey
+		} else if p.laneFor(m) == p.laneFor(kafka.Message{Key: slowKey}) {
+			continue // jangan antre di belakang message lambat
+		}
+		msgs = append(msgs, m)
+		i++
+	}
+
+	release := make(chan struct{})
+	var handled atomic.Int32
+	p.handle = func(ctx context.Context, m kafka.Message) error {
+		if string(m.Key) == "slow" {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+		}
+		handled.Add(1)
+		return nil
+	}
+	r := newFakeReader(msgs...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx, r) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	wait := func(cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out")
+			}
+		}
+	}
+	wait(func() bool { return handled.Load() == 5 })
+	// beberapa putaran commit sementara offset 3 masih jalan
+	for end := time.Now().Add(50 * time.Millisecond); time.Now().Before(end); time.Sleep(time.Millisecond) {
+		if off, ok := r.offset(0); ok && off > 2 {
+			t.Fatalf("committed offset %d while offset 3 is in flight", off)
+		}
+	}
+	if off, ok := r.offset(0); !ok || off != 2 {
+		t.Fatalf("committed offset %d (%v), want 2", off, ok)
+	}
+
+	close(release)
+	wait(func() bool { off, _ := r.offset(0); return off == 5 })
+}
@@ -0,0 +1,200 @@
+// internal/worker/settle.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Payment is one PaymentRequested event from payments.request.
+type Payment struct {
+	IdempotencyKey string
+	SenderID       string
+	ReceiverID     string
+	AmountIDR      int64
+	CurrencyInput  string
+	TxDate         string
+	MerchantID     string // tenant: sender dan receiver harus milik merchant ini
+	// FeeIDR: fee plan merchant, dipotong dari kredit receiver dan dicatat
+	// di payments.fee_idr (pendapatan platform).
+	FeeIDR int64
+	// SettlementCurrency/SettlementAmount: nilai bersih (AmountIDR - FeeIDR)
+	// dalam mata uang settlement merchant, dikonversi gateway. Saldo wallet
+	// tetap IDR; keduanya dicatat di payments untuk payout.
+	SettlementCurrency string
+	SettlementAmount   float64
+}
+
+// Result is the settlement outcome; it goes back on payments.result as
+// PaymentSettled or PaymentFailed.
+type Result struct {
+	Status string // SUCCESS | SUCCESS_REPLAY | FAILED
+	Reason string
+	Ref    string // payment_id
+}
+
+// Settler settles one payment. Business failures (insufficient funds, ...)
+// come back as a FAILED Result; an error means nothing was written.
+type Settler interface {
+	Settle(ctx context.Context, p Payment) (Result, error)
+}
+
+// Store settles payments directly in Postgres (wallet_accounts + payments).
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+func NewStore(pool *pgxpool.Pool) *Store { return &Store{pool: pool} }
+
+// EnsureSchema adds the tenant, fee and failure-reason columns settlement
+// relies on. The
+// tables themselves are owned by the wallet / ledger schema.
+func (s *Store) EnsureSchema(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, `
+ALTER TABLE IF EXISTS wallet_accounts ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS fee_idr BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS settlement_currency TEXT NOT NULL DEFAULT 'IDR';
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS settlement_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+`)
+	if err != nil {
+		return fmt.Errorf("ensure schema: %w", err)
+	}
+	return nil
+}
+
+// Settle runs a single payment in its own transaction.
+func (s *Store) Settle(ctx context.Context, p Payment) (Result, error) {
+	var res Result
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		res, err = settleTx(ctx, tx, p)
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// SettleBatch runs all payments in one transaction. Any error rolls back the
+// whole batch; the caller is expected to retry item by item.
+func (s *Store) SettleBatch(ctx context.Context, ps []Payment) ([]Result, error) {
+	out := make([]Result, len(ps))
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for i, p := range ps {
+			res, err := settleTx(ctx, tx, p)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.IdempotencyKey, err)
+			}
+			out[i] = res
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// settleTx: idempotent via payments.idempotency_key, debit sender, credit
+// receiver, insert payments row. Balance moves run in a savepoint so a
+// business failure only undoes this payment, not the rest of a batch.
+func settleTx(ctx context.Context, tx pgx.Tx, p Payment) (Result, error) {
+	if p.IdempotencyKey == "" || p.SenderID == "" || p.ReceiverID == "" || p.AmountIDR <= 0 || p.FeeIDR < 0 {
+		return Result{Status: "FAILED", Reason: "invalid_input"}, nil
+	}
+	if p.SettlementCurrency == "" {
+		p.SettlementCurrency, p.SettlementAmount = "IDR", float64(p.AmountIDR-p.FeeIDR)
+	}
+
+	// replay?
+	var (
+		paymentID string
+		status    string
+	)
+	err := tx.QueryRow(ctx,
+		`SELECT payment_id::text, status FROM payments WHERE idempotency_key = $1`,
+		p.IdempotencyKey,
+	).Scan(&paymentID, &status)
+	switch {
+	case err == nil:
+		if status == "SUCCESS" {
+			return Result{Status: "SUCCESS_REPLAY", Ref: paymentID}, nil
+		}
+		return Result{Status: status, Reason: "duplicate_idempotency_key", Ref: paymentID}, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Result{}, fmt.Errorf("lookup payment: %w", err)
+	}
+
+	reason := "fee_exceeds_amount"
+	if p.FeeIDR < p.AmountIDR {
+		if reason, err = moveFunds(ctx, tx, p); err != nil {
+			return Result{}, err
+		}
+	}
+
+	paymentID = uuid.New().String()
+	status = "SUCCESS"
+	if reason != "" {
+		status = "FAILED"
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO payments
+			(payment_id, idempotency_key, sender_id, receiver_id, currency_input, amount_idr, status, merchant_id,
+			 fee_idr, settlement_currency, settlement_amount, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, paymentID, p.IdempotencyKey, p.SenderID, p.ReceiverID, p.CurrencyInput, p.AmountIDR, status, p.MerchantID,
+		p.FeeIDR, p.SettlementCurrency, p.SettlementAmount, reason)
+	if err != nil {
+		return Result{}, fmt.Errorf("insert payment: %w", err)
+	}
+	return Result{Status: status, Reason: reason, Ref: paymentID}, nil
+}
+
+// moveFunds returns a non-empty reason when the payment is rejected. Both
+// accounts must belong to the payment's tenant; an account of another
+// merchant is indistinguishable from a missing one. The sender pays the full
+// amount, the receiver gets it net of the merchant fee.
+func moveFunds(ctx context.Context, tx pgx.Tx, p Payment) (string, error) {
+	sp, err := tx.Begin(ctx) // savepoint
+	if err != nil {
+		return "", fmt.Errorf("savepoint: %w", err)
+	}
+	defer func() { _ = sp.Rollback(ctx) }()
+
+	cmd, err := sp.Exec(ctx, `
+		UPDATE wallet_accounts
+		SET balance_idr = balance_idr - $2, updated_at = now()
+		WHERE account_id = $1 AND balance_idr >= $2 AND merchant_id = $3
+	`, p.SenderID, p.AmountIDR, p.MerchantID)
+	if err != nil {
+		return "", fmt.Errorf("debit sender: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return "insufficient_funds", nil
+	}
+
+	cmd, err = sp.Exec(ctx, `
+		UPDATE wallet_accounts
+		SET balance_idr = balance_idr + $2, updated_at = now()
+		WHERE account_id = $1 AND merchant_id = $3
+	`, p.ReceiverID, p.AmountIDR-p.FeeIDR, p.MerchantID)
+	if err != nil {
+		return "", fmt.Errorf("credit receiver: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return "receiver_not_found", nil
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return "", fmt.Errorf("release savepoint: %w", err)
+	}
+	return "", nil
+}
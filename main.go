@@ -13,8 +13,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/example/payment-gateway-poc/pkg/mtls"
 	paymentsv1 "github.com/example/payment-gateway-poc/proto/gen/payments/v1"
 )
 
@@ -24,11 +24,12 @@ type APIServer struct {
 }
 
 func NewAPIServer() (*APIServer, error) {
-	// Connect to payments gRPC service
-	paymentsConn, err := grpc.Dial(
-		os.Getenv("PAYMENTS_ADDR"),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	// Connect to payments gRPC service (mTLS bila TLS_CERT_FILE di-set)
+	creds, _, err := mtls.DialOption(mtls.FromEnv())
+	if err != nil {
+		return nil, err
+	}
+	paymentsConn, err := grpc.Dial(os.Getenv("PAYMENTS_ADDR"), creds)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,327 @@
+// pkg/config/config.go
+// Konfigurasi bersama semua service. Struct per service (lihat services.go)
+// diisi dengan urutan prioritas:
+//
+//	default (Default*()) < file YAML (-config / CONFIG_FILE) < env < flag
+//
+// Tag field:
+//
+//	yaml:"grpc_addr"   kunci di file YAML; nama flag = path YAML bertitik
+//	                   dengan "_" → "-" (mis. -server.grpc-addr)
+//	env:"GRPC_ADDR"    variabel env (nama lama tetap dipakai, kompatibel compose)
+//	secret:"true"      disamarkan di Dump; URL hanya password-nya
+//
+// Setelah dimuat, setiap struct yang punya method Validate() error dicek.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile: env yang menunjuk file YAML bila -config tidak diberikan.
+const EnvFile = "CONFIG_FILE"
+
+const redacted = "[REDACTED]"
+
+// Validator diimplementasikan struct config yang punya aturan sendiri.
+type Validator interface {
+	Validate() error
+}
+
+// field: satu nilai skalar (termasuk durasi dan []string) di struct config.
+type field struct {
+	path   string // path YAML bertitik, mis. server.grpc_addr
+	env    string
+	secret bool
+	v      reflect.Value
+}
+
+func (f field) flagName() string { return strings.ReplaceAll(f.path, "_", "-") }
+
+// Load mengisi cfg (pointer ke struct yang sudah berisi default) dari file
+// YAML, env, lalu flag di args, kemudian menjalankan validasi.
+func Load(service string, cfg any, args []string) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: %T is not a pointer to struct", cfg)
+	}
+	fields, err := collect(rv.Elem(), "")
+	if err != nil {
+		return err
+	}
+
+	// flag diparse lebih dulu (untuk -config) tapi diterapkan terakhir
+	fs := flag.NewFlagSet(service, flag.ContinueOnError)
+	file := fs.String("config", os.Getenv(EnvFile), "YAML config file (env "+EnvFile+")")
+	flagged := map[string]string{}
+	for _, f := range fields {
+		usage := fmt.Sprintf("default %q", format(f.v))
+		if f.secret {
+			usage = "secret"
+		}
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		name := f.flagName()
+		fs.Func(name, usage, func(s string) error {
+			flagged[name] = s
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		if err := loadFile(*file, cfg); err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := os.LookupEnv(f.env); ok && s != "" {
+			if err := set(f.v, s); err != nil {
+				return fmt.Errorf("config: env %s: %w", f.env, err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if s, ok := flagged[f.flagName()]; ok {
+			if err := set(f.v, s); err != nil {
+				return fmt.Errorf("config: flag -%s: %w", f.flagName(), err)
+			}
+		}
+	}
+	return validate(rv.Elem(), "")
+}
+
+// MustLoad: Load dengan os.Args, keluar bila gagal, lalu mencatat config
+// efektif (secret disamarkan) ke log. Dipanggil sebelum server.New, jadi
+// baris ini memakai logger default (lihat pkg/logging).
+func MustLoad(service string, cfg any) {
+	err := Load(service, cfg, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("config: load failed", "service", service, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("effective config", "service", service, "config", Dump(cfg))
+}
+
+func loadFile(path string, cfg any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true) // salah ketik kunci = error, bukan diam-diam diabaikan
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+func collect(v reflect.Value, prefix string) ([]field, error) {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(sf.Name)
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			sub, err := collect(fv, path)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sub...)
+			continue
+		}
+		if !supported(fv) {
+			return nil, fmt.Errorf("config: %s: unsupported type %s", path, fv.Type())
+		}
+		out = append(out, field{path: path, env: sf.Tag.Get("env"), secret: sf.Tag.Get("secret") == "true", v: fv})
+	}
+	return out, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func supported(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// set mem-parse s sesuai tipe field; []string dipisah koma.
+func set(v reflect.Value, s string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int, v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		var out []string
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		v.Set(reflect.ValueOf(out))
+	}
+	return nil
+}
+
+func format(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// validate memanggil Validate() dari dalam ke luar; semua error dikumpulkan.
+func validate(v reflect.Value, path string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if fv := v.Field(i); fv.Kind() == reflect.Struct && t.Field(i).IsExported() {
+			key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if path != "" {
+				key = path + "." + key
+			}
+			errs = append(errs, validate(fv, key))
+		}
+	}
+	if val, ok := v.Addr().Interface().(Validator); ok {
+		if err := val.Validate(); err != nil {
+			if path != "" {
+				err = fmt.Errorf("%s: %w", path, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: invalid: %w", err)
+	}
+	return nil
+}
+
+// Dump: config efektif sebagai YAML (bisa dipakai lagi sebagai -config),
+// field secret disamarkan.
+func Dump(cfg any) string {
+	rv := reflect.Indirect(reflect.ValueOf(cfg))
+	cp := reflect.New(rv.Type())
+	cp.Elem().Set(rv)
+	if fields, err := collect(cp.Elem(), ""); err == nil {
+		for _, f := range fields {
+			if f.secret {
+				redact(f.v)
+			}
+		}
+	}
+	b, err := yaml.Marshal(cp.Interface())
+	if err != nil {
+		return fmt.Sprintf("<dump: %v>", err)
+	}
+	return string(b)
+}
+
+// redact: URL dengan password (DSN Postgres) tetap terbaca kecuali
+// password-nya; nilai lain diganti seluruhnya. Nilai kosong dibiarkan supaya
+// terlihat bahwa secret belum di-set.
+func redact(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if s == "" {
+			return
+		}
+		if u, err := url.Parse(s); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				v.SetString(u.Redacted())
+				return
+			}
+		}
+		v.SetString(redacted)
+	case reflect.Slice:
+		if v.Len() > 0 {
+			v.Set(reflect.ValueOf([]string{redacted}))
+		}
+	default:
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// CheckAddr: host:port untuk listen (host boleh kosong) atau dial.
+func CheckAddr(name, addr string, dial bool) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%s: invalid port %q", name, port)
+	}
+	if dial && host == "" {
+		return fmt.Errorf("%s: host required in %q", name, addr)
+	}
+	return nil
+}
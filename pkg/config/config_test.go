@@ -0,0 +1,102 @@
+// pkg/config/config_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeFile(t, `
+http_addr: ":8000"
+upstreams:
+  wallet: wallet-a:9093
+  risk: risk-a:9094
+auth:
+  clock_skew: 1m
+v1_rpc_timeout: 3s
+`)
+	t.Setenv(EnvFile, path)
+	t.Setenv("RISK_ADDR", "risk-env:9094")
+	t.Setenv("V1_RPC_TIMEOUT", "4s")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+
+	cfg := DefaultGateway()
+	if err := Load("api-gateway", &cfg, []string{"-upstreams.risk", "risk-flag:9094", "-v1-rpc-timeout=5s"}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"default": cfg.Upstreams.Fx == "fx-grpc:9102",
+		"file":    cfg.HTTPAddr == ":8000" && cfg.Upstreams.Wallet == "wallet-a:9093" && cfg.Auth.ClockSkew == time.Minute,
+		"env":     len(cfg.CORSAllowedOrigins) == 2 && cfg.CORSAllowedOrigins[1] == "https://b.example",
+		"flag":    cfg.Upstreams.Risk == "risk-flag:9094" && cfg.V1RPCTimeout == 5*time.Second,
+	}
+	for source, ok := range want {
+		if !ok {
+			t.Errorf("%s value not applied: %+v", source, cfg)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Setenv("WALLET_ADDR", "wallet-grpc")
+	t.Setenv("GATEWAY_AUTH", "maybe")
+	t.Setenv("TLS_CERT_FILE", "/etc/tls/tls.crt")
+	cfg := DefaultGateway()
+	err := Load("api-gateway", &cfg, nil)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"upstreams: wallet", "auth: mode", "tls: cert_file"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+
+	if err := Load("x", &cfg, []string{"-upstreams.fx=fx:1", "-nope=1"}); err == nil {
+		t.Fatal("unknown flag accepted")
+	}
+	w := DefaultWallet()
+	if err := Load("wallet-grpc", &w, []string{"-config", writeFile(t, "server:\n  grpc_adr: \":1\"\n")}); err == nil {
+		t.Fatal("unknown YAML key accepted")
+	}
+	t.Setenv("METRICS_ADDR", "oops")
+	if err := Load("wallet-grpc", &w, nil); err == nil || !strings.Contains(err.Error(), "server: metrics_addr") {
+		t.Fatalf("bad metrics addr: %v", err)
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := DefaultGateway()
+	cfg.Auth.AdminToken = "adm-123"
+	out := Dump(&cfg)
+	if strings.Contains(out, "adm-123") || strings.Contains(out, ":secret@") {
+		t.Fatalf("secret leaked:\n%s", out)
+	}
+	if !strings.Contains(out, "admin_token: '[REDACTED]'") || !strings.Contains(out, "postgresql://postgres:xxxxx@") {
+		t.Fatalf("unexpected dump:\n%s", out)
+	}
+	if cfg.Auth.AdminToken != "adm-123" {
+		t.Fatal("Dump modified the config")
+	}
+
+	// dump bisa dimuat ulang sebagai file config
+	r := DefaultRisk()
+	r.Server.GRPCAddr = ":7000"
+	loaded := DefaultRisk()
+	if err := Load("risk-grpc", &loaded, []string{"-config", writeFile(t, Dump(r))}); err != nil || loaded != r {
+		t.Fatalf("round trip: %+v %v", loaded, err)
+	}
+}
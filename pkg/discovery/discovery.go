@@ -0,0 +1,135 @@
+// pkg/discovery/discovery.go
+// Package discovery: service discovery dan load balancing sisi client untuk
+// gRPC internal. Tanpa ini satu ClientConn menempel ke satu koneksi HTTP/2,
+// sehingga replica risk-grpc / fx-grpc tambahan tidak menerima beban.
+//
+// Target upstream (FX_ADDR, RISK_ADDR, ...) boleh berupa:
+//
+//	risk-grpc:9094                          satu alamat (seperti sebelumnya)
+//	dns:///risk-grpc:9094                   semua A/AAAA record, di-resolve ulang berkala (bawaan grpc-go)
+//	static:///10.0.0.1:9094,10.0.0.2:9094   daftar tetap
+//	srv:///_grpc._tcp.risk-grpc.poc.svc     DNS SRV record, di-lookup ulang tiap Options.Refresh
+//	file:///etc/payment/risk.endpoints      satu host:port per baris, file dipantau tiap Options.Refresh
+//
+// Authority di URL (static://risk-grpc/...) dipakai sebagai nama server TLS
+// untuk semua endpoint; tanpa itu nama host tiap endpoint (static, file)
+// atau nama service SRV tanpa label _grpc._tcp.
+//
+// Balancer round_robin / least_request hanya memakai endpoint READY: koneksi
+// yang gagal keluar dari rotasi sampai tersambung lagi, dan dengan
+// Options.HealthCheck endpoint yang melaporkan NOT_SERVING lewat
+// grpc.health.v1 Watch (readiness pkg/server) ikut dikeluarkan.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/balancer/pickfirst"
+	"google.golang.org/grpc/balancer/roundrobin"
+	_ "google.golang.org/grpc/health" // client health check untuk healthCheckConfig
+	"google.golang.org/grpc/resolver"
+)
+
+// Balancer yang didukung (Options.Balancer).
+const (
+	RoundRobin   = "round_robin"
+	LeastRequest = "least_request"
+	PickFirst    = "pick_first"
+)
+
+type Options struct {
+	// Balancer: round_robin (default), least_request (pilih yang RPC
+	// in-flight-nya paling sedikit dari dua endpoint acak) atau pick_first.
+	Balancer string
+	// HealthCheck: endpoint NOT_SERVING keluar dari rotasi. Tidak berlaku
+	// untuk pick_first. Server tanpa grpc.health.v1 dianggap sehat.
+	HealthCheck bool
+	// Refresh: interval lookup ulang srv:// dan file://.
+	Refresh time.Duration
+}
+
+// ServiceConfig: default service config gRPC (JSON) untuk Options.
+func ServiceConfig(o Options) (string, error) {
+	var lb string
+	switch o.Balancer {
+	case RoundRobin, "":
+		lb = `{"` + roundrobin.Name + `":{}}`
+	case LeastRequest:
+		lb = `{"` + leastrequest.Name + `":{"choiceCount":2}}`
+	case PickFirst:
+		lb = `{"` + pickfirst.Name + `":{}}`
+	default:
+		return "", fmt.Errorf("balancer: unknown %q", o.Balancer)
+	}
+	sc := `{"loadBalancingConfig":[` + lb + `]`
+	if o.HealthCheck {
+		sc += `,"healthCheckConfig":{"serviceName":""}`
+	}
+	return sc + "}", nil
+}
+
+// DialOptions: resolver static/srv/file dan balancer untuk grpc.Dial(target,
+// ...). Target static/srv/file yang salah tulis langsung ditolak di sini,
+// bukan saat RPC pertama.
+func DialOptions(target string, o Options) ([]grpc.DialOption, error) {
+	if _, err := parse(target); err != nil {
+		return nil, err
+	}
+	sc, err := ServiceConfig(o)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.DialOption{
+		grpc.WithResolvers(builders(o.Refresh)...),
+		grpc.WithDefaultServiceConfig(sc),
+	}, nil
+}
+
+// Resolve: lookup satu kali semua endpoint target, untuk operasi yang harus
+// mengenai setiap replica (mis. FX UpdateRates, karena kurs disimpan per
+// proses). ServerName selalu terisi; dial tiap Addr dengan
+// grpc.WithAuthority(ServerName).
+func Resolve(ctx context.Context, target string) ([]resolver.Address, error) {
+	src, err := parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if src != nil {
+		addrs, err := src.lookup(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", target, err)
+		}
+		auth := src.authority()
+		for i := range addrs {
+			if addrs[i].ServerName == "" {
+				addrs[i].ServerName = auth
+			}
+		}
+		return addrs, nil
+	}
+
+	// host:port atau dns:///host:port: semua IP dari DNS
+	endpoint := target
+	if u, ok := strings.CutPrefix(target, "dns:"); ok {
+		endpoint = u[strings.LastIndex(u, "/")+1:]
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", target, err)
+	}
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", target, err)
+	}
+	addrs := make([]resolver.Address, len(ips))
+	for i, ip := range ips {
+		addrs[i] = resolver.Address{Addr: net.JoinHostPort(ip, port), ServerName: host}
+	}
+	return addrs, nil
+}
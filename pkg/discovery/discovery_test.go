@@ -0,0 +1,184 @@
+// pkg/discovery/discovery_test.go
+package discovery
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type backend struct {
+	addr   string
+	srv    *grpc.Server
+	health *health.Server
+	calls  atomic.Int64
+}
+
+// startBackend: server gRPC dengan grpc.health.v1; calls menghitung Check
+// yang sampai di server ini.
+func startBackend(t *testing.T) *backend {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &backend{addr: lis.Addr().String(), health: health.NewServer()}
+	b.srv = grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		b.calls.Add(1)
+		return h(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(b.srv, b.health)
+	go b.srv.Serve(lis)
+	t.Cleanup(b.srv.Stop)
+	return b
+}
+
+func dialTest(t *testing.T, target string, o Options) healthpb.HealthClient {
+	t.Helper()
+	opts, err := DialOptions(target, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.Dial(target, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+// spread: n RPC, lalu jumlah yang diterima tiap backend.
+func spread(t *testing.T, c healthpb.HealthClient, n int, bs ...*backend) []int64 {
+	t.Helper()
+	before := make([]int64, len(bs))
+	for i, b := range bs {
+		before[i] = b.calls.Load()
+	}
+	for i := 0; i < n; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err := c.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			t.Fatalf("rpc %d: %v", i, err)
+		}
+	}
+	got := make([]int64, len(bs))
+	for i, b := range bs {
+		got[i] = b.calls.Load() - before[i]
+	}
+	return got
+}
+
+// eventually: cond dicek ulang sampai 5s (perubahan health/endpoint sampai
+// ke balancer secara asinkron).
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestRoundRobinEjectsUnhealthy(t *testing.T) {
+	a, b, c := startBackend(t), startBackend(t), startBackend(t)
+	client := dialTest(t, "static:///"+a.addr+","+b.addr+","+c.addr, Options{Balancer: RoundRobin, HealthCheck: true})
+
+	eventually(t, "traffic on all endpoints", func() bool {
+		got := spread(t, client, 30, a, b, c)
+		return got[0] > 0 && got[1] > 0 && got[2] > 0
+	})
+
+	b.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	eventually(t, "NOT_SERVING endpoint out of rotation", func() bool { return spread(t, client, 30, a, b, c)[1] == 0 })
+
+	// server mati: koneksi gagal, sisa trafik ke a; GracefulStop tidak
+	// tertahan stream health Watch client
+	stopped := make(chan struct{})
+	go func() { c.srv.GracefulStop(); close(stopped) }()
+	select {
+	case <-stopped:
+	case <-time.After(3 * time.Second):
+		t.Fatal("GracefulStop blocked by health watch")
+	}
+	eventually(t, "only healthy endpoint used", func() bool {
+		got := spread(t, client, 20, a, b, c)
+		return got[0] == 20
+	})
+
+	b.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	eventually(t, "recovered endpoint back in rotation", func() bool { return spread(t, client, 30, a, b, c)[1] > 0 })
+}
+
+func TestFileWatch(t *testing.T) {
+	a, b := startBackend(t), startBackend(t)
+	path := filepath.Join(t.TempDir(), "risk.endpoints")
+	if err := os.WriteFile(path, []byte("# replica risk\n"+a.addr+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := dialTest(t, "file://"+path, Options{Balancer: LeastRequest, Refresh: 20 * time.Millisecond})
+	if got := spread(t, client, 10, a, b); got[0] != 10 {
+		t.Fatalf("before update: %v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(b.addr+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "file change picked up", func() bool { return spread(t, client, 10, a, b)[1] == 10 })
+
+	// file rusak: endpoint lama tetap dipakai
+	if err := os.WriteFile(path, []byte("not-an-endpoint\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := spread(t, client, 10, a, b); got[1] != 10 {
+		t.Fatalf("after bad file: %v", got)
+	}
+}
+
+func TestSRV(t *testing.T) {
+	lookupSRV = func(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+		if name != "_grpc._tcp.risk-grpc.poc.svc" {
+			t.Errorf("lookup %q", name)
+		}
+		return name, []*net.SRV{{Target: "risk-1.risk-grpc.poc.svc.", Port: 9094}, {Target: "risk-0.risk-grpc.poc.svc.", Port: 9094}}, nil
+	}
+	defer func() { lookupSRV = net.DefaultResolver.LookupSRV }()
+
+	addrs, err := Resolve(context.Background(), "srv:///_grpc._tcp.risk-grpc.poc.svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range addrs {
+		got = append(got, a.Addr+"@"+a.ServerName)
+	}
+	if want := "risk-0.risk-grpc.poc.svc:9094@risk-grpc.poc.svc risk-1.risk-grpc.poc.svc:9094@risk-grpc.poc.svc"; strings.Join(got, " ") != want {
+		t.Errorf("Resolve = %v, want %s", got, want)
+	}
+}
+
+func TestDialOptionsRejectsBadTargets(t *testing.T) {
+	for _, target := range []string{"static:///", "static:///risk-grpc", "static:///a:1,b:0", "srv:///", "file://"} {
+		if _, err := DialOptions(target, Options{}); err == nil {
+			t.Errorf("DialOptions(%q): want error", target)
+		}
+	}
+	if _, err := DialOptions("risk-grpc:9094", Options{Balancer: "random"}); err == nil {
+		t.Error("unknown balancer accepted")
+	}
+	if _, err := DialOptions("dns:///risk-grpc:9094", Options{Balancer: PickFirst}); err != nil {
+		t.Error(err)
+	}
+}
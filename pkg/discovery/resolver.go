@@ -0,0 +1,309 @@
+// pkg/discovery/resolver.go
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/resolver"
+)
+
+var (
+	endpointsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "payment",
+		Subsystem: "discovery",
+		Name:      "endpoints",
+		Help:      "Jumlah endpoint hasil lookup terakhir per target.",
+	}, []string{"target"})
+	lookupErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "discovery",
+		Name:      "lookup_errors_total",
+		Help:      "Lookup SRV / baca file endpoint yang gagal; endpoint lama tetap dipakai.",
+	}, []string{"target"})
+)
+
+// lookupSRV: diganti di test.
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+const (
+	defaultRefresh = 30 * time.Second
+	// minResolveGap: grpc-go memanggil ResolveNow setiap kali endpoint
+	// gagal; lookup ulang dibatasi supaya DNS tidak dibanjiri.
+	minResolveGap = time.Second
+)
+
+// Scheme target yang di-resolve package ini.
+const (
+	SchemeStatic = "static"
+	SchemeSRV    = "srv"
+	SchemeFile   = "file"
+)
+
+// source: endpoint satu target static/srv/file.
+type source struct {
+	lookup    func(ctx context.Context) ([]resolver.Address, error)
+	authority func() string
+	watch     bool // lookup ulang tiap Refresh
+}
+
+// parse: source untuk target static/srv/file; nil untuk target lain
+// (host:port, dns:///, unix:) yang ditangani grpc-go sendiri.
+func parse(target string) (*source, error) {
+	scheme, _, ok := strings.Cut(target, "://")
+	if !ok {
+		return nil, nil
+	}
+	switch scheme {
+	case SchemeStatic, SchemeSRV, SchemeFile:
+	default:
+		return nil, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", target, err)
+	}
+	src, err := newSource(u)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", target, err)
+	}
+	return src, nil
+}
+
+func newSource(u *url.URL) (*source, error) {
+	switch u.Scheme {
+	case SchemeStatic:
+		addrs, err := parseList(strings.Split(strings.TrimPrefix(u.Path, "/"), ","), u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &source{
+			lookup: func(context.Context) ([]resolver.Address, error) { return slices.Clone(addrs), nil },
+			authority: func() string {
+				if u.Host != "" {
+					return u.Host
+				}
+				return addrs[0].ServerName
+			},
+		}, nil
+
+	case SchemeSRV:
+		name := strings.Trim(u.Path, "/")
+		if name == "" {
+			return nil, errors.New("srv: record name required (srv:///_grpc._tcp.service.namespace)")
+		}
+		return &source{
+			lookup: func(ctx context.Context) ([]resolver.Address, error) {
+				_, srvs, err := lookupSRV(ctx, "", "", name)
+				if err != nil {
+					return nil, err
+				}
+				addrs := make([]resolver.Address, 0, len(srvs))
+				for _, s := range srvs {
+					addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(strings.TrimSuffix(s.Target, "."), strconv.Itoa(int(s.Port)))})
+				}
+				// urutan SRV diacak per lookup (weight); urutkan supaya
+				// lookup ulang tanpa perubahan tidak memicu UpdateState
+				slices.SortFunc(addrs, func(a, b resolver.Address) int { return strings.Compare(a.Addr, b.Addr) })
+				return addrs, nil
+			},
+			authority: func() string {
+				if u.Host != "" {
+					return u.Host
+				}
+				// _grpc._tcp.risk-grpc.poc.svc → risk-grpc.poc.svc
+				host := name
+				for strings.HasPrefix(host, "_") {
+					_, host, _ = strings.Cut(host, ".")
+				}
+				return host
+			},
+			watch: true,
+		}, nil
+
+	case SchemeFile:
+		if u.Path == "" {
+			return nil, errors.New("file: path required (file:///etc/payment/risk.endpoints)")
+		}
+		return &source{
+			lookup: func(context.Context) ([]resolver.Address, error) {
+				b, err := os.ReadFile(u.Path)
+				if err != nil {
+					return nil, err
+				}
+				var lines []string
+				sc := bufio.NewScanner(bytes.NewReader(b))
+				for sc.Scan() {
+					line, _, _ := strings.Cut(sc.Text(), "#")
+					if line = strings.TrimSpace(line); line != "" {
+						lines = append(lines, line)
+					}
+				}
+				return parseList(lines, u.Host)
+			},
+			authority: func() string {
+				if u.Host != "" {
+					return u.Host
+				}
+				return "localhost"
+			},
+			watch: true,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown scheme %q", u.Scheme)
+}
+
+// parseList: host:port per entri. Tanpa authority, nama TLS tiap endpoint =
+// host-nya sendiri.
+func parseList(list []string, authority string) ([]resolver.Address, error) {
+	var addrs []resolver.Address
+	for _, a := range list {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(a)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || host == "" {
+			return nil, fmt.Errorf("invalid endpoint %q", a)
+		}
+		addr := resolver.Address{Addr: a}
+		if authority == "" {
+			addr.ServerName = host
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no endpoints")
+	}
+	return addrs, nil
+}
+
+func builders(refresh time.Duration) []resolver.Builder {
+	if refresh <= 0 {
+		refresh = defaultRefresh
+	}
+	return []resolver.Builder{
+		builder{SchemeStatic, refresh},
+		builder{SchemeSRV, refresh},
+		builder{SchemeFile, refresh},
+	}
+}
+
+type builder struct {
+	scheme  string
+	refresh time.Duration
+}
+
+func (b builder) Scheme() string { return b.scheme }
+
+func (b builder) Build(t resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	src, err := newSource(&t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", t.String(), err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &watcher{
+		target: t.String(), src: src, cc: cc, refresh: b.refresh,
+		now: make(chan struct{}, 1), cancel: cancel, done: make(chan struct{}),
+	}
+	go w.run(ctx)
+	return w, nil
+}
+
+// OverrideAuthority: :authority dan nama TLS default (resolver.AuthorityOverrider).
+func (b builder) OverrideAuthority(t resolver.Target) string {
+	src, err := newSource(&t.URL)
+	if err != nil {
+		return t.Endpoint()
+	}
+	return src.authority()
+}
+
+// watcher: lookup saat Build, lalu (srv, file) tiap refresh atau saat
+// grpc-go meminta ResolveNow. Lookup yang gagal setelah pernah sukses
+// tidak menghapus endpoint yang ada.
+type watcher struct {
+	target  string
+	src     *source
+	cc      resolver.ClientConn
+	refresh time.Duration
+
+	now    chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.done)
+	var last []resolver.Address
+	for {
+		at := time.Now()
+		addrs, err := w.src.lookup(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			lookupErrors.WithLabelValues(w.target).Inc()
+			if last == nil {
+				w.cc.ReportError(fmt.Errorf("discovery %s: %w", w.target, err))
+			} else {
+				slog.Warn("discovery: lookup failed, keeping endpoints", "target", w.target, "endpoints", len(last), "error", err)
+			}
+		case !slices.Equal(addrs, last):
+			last = addrs
+			endpointsGauge.WithLabelValues(w.target).Set(float64(len(addrs)))
+			slog.Info("discovery: endpoints updated", "target", w.target, "endpoints", len(addrs))
+			_ = w.cc.UpdateState(resolver.State{Addresses: addrs})
+		}
+
+		if !w.src.watch {
+			<-ctx.Done()
+			return
+		}
+		t := time.NewTimer(w.refresh)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		case <-w.now:
+			t.Stop()
+			if wait := minResolveGap - time.Since(at); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+}
+
+func (w *watcher) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case w.now <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) Close() {
+	w.cancel()
+	<-w.done
+}
@@ -1,15 +1,160 @@
 // payment-gateway-poc/pkg/errors/errors.go
-// payment-gateway-poc/pkg/errors/errors.go
+// Package errors: katalog kode error domain yang stabil (INSUFFICIENT_FUNDS,
+// ACCOUNT_BLOCKED, FX_RATE_STALE, RISK_DENIED, ...) supaya client bisa
+// bercabang pada kode, bukan pada teks pesan.
+//
+// Service gRPC mengembalikan E; grpc-go mengubahnya menjadi status dengan
+// kode gRPC dari katalog plus detail errdetails.ErrorInfo{Reason: kode}.
+// Di sisi pemanggil From membaca kembali kode tersebut, dan gateway
+// menulisnya sebagai RFC 7807 application/problem+json (http.go).
+//
+// Import dengan alias (perrors) agar tidak bentrok dengan package errors
+// standar.
 package errors
 
-import "fmt"
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain: errdetails.ErrorInfo.Domain untuk semua kode di katalog.
+const Domain = "payment-gateway-poc"
+
+// Kode generik: kegagalan tanpa arti bisnis, setara kode gRPC.
+const (
+	InvalidArgument  = "INVALID_ARGUMENT"
+	Unauthenticated  = "UNAUTHENTICATED"
+	PermissionDenied = "PERMISSION_DENIED"
+	NotFound         = "NOT_FOUND"
+	Conflict         = "CONFLICT"
+	RateLimited      = "RATE_LIMITED"
+	PayloadTooLarge  = "PAYLOAD_TOO_LARGE"
+	UnsupportedMedia = "UNSUPPORTED_MEDIA_TYPE"
+	Unavailable      = "UNAVAILABLE"
+	DeadlineExceeded = "DEADLINE_EXCEEDED"
+	Unimplemented    = "UNIMPLEMENTED"
+	Internal         = "INTERNAL"
+)
+
+// Kode domain.
+const (
+	InsufficientFunds   = "INSUFFICIENT_FUNDS"
+	AccountNotFound     = "ACCOUNT_NOT_FOUND"
+	AccountBlocked      = "ACCOUNT_BLOCKED"
+	ReservationNotFound = "RESERVATION_NOT_FOUND"
+	UnsupportedCurrency = "UNSUPPORTED_CURRENCY"
+	FXRateStale         = "FX_RATE_STALE"
+	FXRateUnavailable   = "FX_RATE_UNAVAILABLE"
+	RiskDenied          = "RISK_DENIED"
+	UnknownMerchant     = "UNKNOWN_MERCHANT"
+	MerchantMismatch    = "MERCHANT_MISMATCH"
+	DuplicatePayment    = "DUPLICATE_PAYMENT"
+	PaymentNotFound     = "PAYMENT_NOT_FOUND"
+
+	IdempotencyKeyConflict = "IDEMPOTENCY_KEY_CONFLICT"
+	RequestInProgress      = "REQUEST_IN_PROGRESS"
+)
 
+type spec struct {
+	grpc  codes.Code
+	http  int
+	title string
+}
+
+// catalogue: kode → status gRPC, status HTTP, dan title problem+json.
+// Kode yang sudah dipakai client tidak boleh diganti artinya; tambah kode
+// baru bila perlu.
+var catalogue = map[string]spec{
+	InvalidArgument:  {codes.InvalidArgument, http.StatusBadRequest, "Invalid request"},
+	Unauthenticated:  {codes.Unauthenticated, http.StatusUnauthorized, "Authentication required"},
+	PermissionDenied: {codes.PermissionDenied, http.StatusForbidden, "Permission denied"},
+	NotFound:         {codes.NotFound, http.StatusNotFound, "Not found"},
+	Conflict:         {codes.Aborted, http.StatusConflict, "Conflict"},
+	RateLimited:      {codes.ResourceExhausted, http.StatusTooManyRequests, "Rate limit exceeded"},
+	PayloadTooLarge:  {codes.ResourceExhausted, http.StatusRequestEntityTooLarge, "Request body too large"},
+	UnsupportedMedia: {codes.InvalidArgument, http.StatusUnsupportedMediaType, "Unsupported media type"},
+	Unavailable:      {codes.Unavailable, http.StatusServiceUnavailable, "Service unavailable"},
+	DeadlineExceeded: {codes.DeadlineExceeded, http.StatusGatewayTimeout, "Deadline exceeded"},
+	Unimplemented:    {codes.Unimplemented, http.StatusNotImplemented, "Not implemented"},
+	Internal:         {codes.Internal, http.StatusInternalServerError, "Internal error"},
+
+	InsufficientFunds:   {codes.FailedPrecondition, http.StatusUnprocessableEntity, "Insufficient funds"},
+	AccountNotFound:     {codes.NotFound, http.StatusNotFound, "Account not found"},
+	AccountBlocked:      {codes.FailedPrecondition, http.StatusUnprocessableEntity, "Account blocked"},
+	ReservationNotFound: {codes.NotFound, http.StatusNotFound, "Reservation not found"},
+	UnsupportedCurrency: {codes.InvalidArgument, http.StatusBadRequest, "Unsupported currency"},
+	FXRateStale:         {codes.FailedPrecondition, http.StatusServiceUnavailable, "FX rate stale"},
+	FXRateUnavailable:   {codes.NotFound, http.StatusUnprocessableEntity, "FX rate unavailable"},
+	RiskDenied:          {codes.PermissionDenied, http.StatusUnprocessableEntity, "Payment denied by risk check"},
+	UnknownMerchant:     {codes.PermissionDenied, http.StatusForbidden, "Unknown merchant"},
+	MerchantMismatch:    {codes.PermissionDenied, http.StatusForbidden, "Merchant mismatch"},
+	DuplicatePayment:    {codes.AlreadyExists, http.StatusConflict, "Duplicate payment"},
+	PaymentNotFound:     {codes.NotFound, http.StatusNotFound, "Payment not found"},
+
+	IdempotencyKeyConflict: {codes.AlreadyExists, http.StatusConflict, "Idempotency key reused with a different request"},
+	RequestInProgress:      {codes.Aborted, http.StatusConflict, "Request in progress"},
+}
+
+// generic: kode gRPC tanpa ErrorInfo (service lain, grpc-go sendiri) →
+// kode generik.
+var generic = map[codes.Code]string{
+	codes.InvalidArgument:    InvalidArgument,
+	codes.OutOfRange:         InvalidArgument,
+	codes.FailedPrecondition: InvalidArgument,
+	codes.Unauthenticated:    Unauthenticated,
+	codes.PermissionDenied:   PermissionDenied,
+	codes.NotFound:           NotFound,
+	codes.AlreadyExists:      Conflict,
+	codes.Aborted:            Conflict,
+	codes.ResourceExhausted:  RateLimited,
+	codes.Unavailable:        Unavailable,
+	codes.DeadlineExceeded:   DeadlineExceeded,
+	codes.Unimplemented:      Unimplemented,
+}
+
+func lookup(code string) spec {
+	if s, ok := catalogue[code]; ok {
+		return s
+	}
+	return catalogue[Internal]
+}
+
+// Known: code ada di katalog.
+func Known(code string) bool {
+	_, ok := catalogue[code]
+	return ok
+}
+
+// GRPCCode / HTTPStatus / Title: pemetaan kode katalog; kode tak dikenal
+// diperlakukan sebagai INTERNAL.
+func GRPCCode(code string) codes.Code { return lookup(code).grpc }
+func HTTPStatus(code string) int      { return lookup(code).http }
+func Title(code string) string        { return lookup(code).title }
+
+// E: error dengan kode katalog. Message dikirim ke client (jangan isi
+// detail internal); Err hanya untuk log dan errors.Is/As.
 type E struct {
-	Code    string
-	Message string
-	Err     error
+	Code     string
+	Message  string
+	Err      error
+	Metadata map[string]string // ErrorInfo.Metadata / problem+json "metadata"
+	Fields   []FieldError      // problem+json "fields" (validasi request)
 }
 
+// FieldError: satu field request yang tidak valid.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e FieldError) String() string { return e.Field + ": " + e.Reason }
+
 func (e E) Error() string {
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %s (%v)", e.Code, e.Message, e.Err)
@@ -17,6 +162,74 @@ func (e E) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+func (e E) Unwrap() error { return e.Err }
+
+// With: salinan e dengan metadata k=v tambahan (account_id, reason, ...).
+func (e E) With(k, v string) E {
+	md := make(map[string]string, len(e.Metadata)+1)
+	for mk, mv := range e.Metadata {
+		md[mk] = mv
+	}
+	md[k] = v
+	e.Metadata = md
+	return e
+}
+
+// WithFields: salinan e dengan daftar field yang tidak valid.
+func (e E) WithFields(fs []FieldError) E {
+	e.Fields = fs
+	return e
+}
+
+// GRPCStatus: dipakai grpc-go (status.FromError) saat E dikembalikan dari
+// handler: kode gRPC katalog + ErrorInfo{Reason: Code}.
+func (e E) GRPCStatus() *status.Status {
+	st := status.New(GRPCCode(e.Code), e.Message)
+	if ds, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: e.Code, Domain: Domain, Metadata: e.Metadata,
+	}); err == nil {
+		st = ds
+	}
+	return st
+}
+
+// New: E dengan pesan fmt.Sprintf(format, args...).
+func New(code, format string, args ...any) E {
+	return E{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap: E yang membungkus err; pesan ke client hanya msg.
 func Wrap(code, msg string, err error) error {
 	return E{Code: code, Message: msg, Err: err}
 }
+
+// From: err apa pun → E. Urutan: E di rantai error, status gRPC dengan
+// ErrorInfo domain ini, status gRPC biasa (kode generik), error context,
+// selain itu INTERNAL tanpa membocorkan pesan asli. nil → E kosong.
+func From(err error) E {
+	if err == nil {
+		return E{}
+	}
+	var e E
+	if stderrors.As(err, &e) {
+		return e
+	}
+	if st, ok := status.FromError(err); ok {
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain && Known(info.GetReason()) {
+				return E{Code: info.GetReason(), Message: st.Message(), Err: err, Metadata: info.GetMetadata()}
+			}
+		}
+		if code, ok := generic[st.Code()]; ok {
+			return E{Code: code, Message: st.Message(), Err: err}
+		}
+		return E{Code: Internal, Message: "internal error", Err: err}
+	}
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return E{Code: DeadlineExceeded, Message: "deadline exceeded", Err: err}
+	}
+	return E{Code: Internal, Message: "internal error", Err: err}
+}
+
+// CodeOf: kode katalog err; "" bila err nil.
+func CodeOf(err error) string { return From(err).Code }
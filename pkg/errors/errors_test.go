@@ -0,0 +1,67 @@
+// payment-gateway-poc/pkg/errors/errors_test.go
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// E dari handler gRPC sampai di pemanggil dengan kode dan metadata utuh.
+func TestGRPCRoundTrip(t *testing.T) {
+	sent := New(InsufficientFunds, "balance too low").With("account_id", "ACC-1")
+	// grpc-go: error handler → status (server) → error (client)
+	wire := status.Convert(sent).Err()
+	if status.Code(wire) != codes.FailedPrecondition {
+		t.Fatalf("grpc code %v", status.Code(wire))
+	}
+	got := From(wire)
+	if got.Code != InsufficientFunds || got.Message != "balance too low" || got.Metadata["account_id"] != "ACC-1" {
+		t.Fatalf("From: %+v", got)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{status.Error(codes.NotFound, "no such account"), NotFound},
+		{status.Error(codes.Unavailable, "connection refused"), Unavailable},
+		{status.Error(codes.Unknown, "pq: relation does not exist"), Internal},
+		{context.DeadlineExceeded, DeadlineExceeded},
+		{fmt.Errorf("boom"), Internal},
+	} {
+		if got := From(tc.err); got.Code != tc.want {
+			t.Errorf("From(%v) = %s, want %s", tc.err, got.Code, tc.want)
+		}
+	}
+	if got := From(status.Error(codes.Unknown, "pq: secret")); got.Message != "internal error" {
+		t.Errorf("internal message leaked: %q", got.Message)
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/payments", nil)
+	Write(rec, req, Wrap(FXRateStale, "rate USD/IDR is stale", status.Error(codes.Internal, "x")))
+
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := Problem{
+		Type: TypeBase + "fx-rate-stale", Title: "FX rate stale", Status: http.StatusServiceUnavailable,
+		Detail: "rate USD/IDR is stale", Instance: "/api/payments", Code: FXRateStale,
+	}
+	if fmt.Sprint(p) != fmt.Sprint(want) {
+		t.Errorf("problem %+v, want %+v", p, want)
+	}
+}
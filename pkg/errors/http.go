@@ -0,0 +1,54 @@
+// payment-gateway-poc/pkg/errors/http.go
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ContentType: media type RFC 7807.
+const ContentType = "application/problem+json"
+
+// TypeBase: prefix URI "type" problem; diikuti kode dalam huruf kecil,
+// mis. .../errors/insufficient-funds.
+const TypeBase = "https://payment-gateway-poc.example/errors/"
+
+// Problem: body RFC 7807. code, metadata, dan fields adalah extension
+// member: code sama dengan ErrorInfo.Reason di gRPC, jadi client REST dan
+// gRPC bercabang pada nilai yang sama; fields = error validasi per field.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Fields   []FieldError      `json:"fields,omitempty"`
+}
+
+// Problem: e sebagai problem+json; instance diisi pemanggil.
+func (e E) Problem() Problem {
+	return Problem{
+		Type:     TypeBase + strings.ReplaceAll(strings.ToLower(e.Code), "_", "-"),
+		Title:    Title(e.Code),
+		Status:   HTTPStatus(e.Code),
+		Detail:   e.Message,
+		Code:     e.Code,
+		Metadata: e.Metadata,
+		Fields:   e.Fields,
+	}
+}
+
+// Write: err (lihat From) sebagai application/problem+json dengan status
+// HTTP dari katalog; instance = path request.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := From(err).Problem()
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
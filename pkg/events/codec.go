@@ -0,0 +1,189 @@
+// payment-gateway-poc/pkg/events/codec.go
+// Package events encodes and decodes the payment events on Kafka.
+// Producers always write protobuf (proto/gen/events/v1); consumers also
+// accept the JSON maps written by producers older than schema version 1.
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+// SchemaVersion is written into every event header. Consumers reject events
+// with a higher version; legacy JSON is reported as version 0.
+const SchemaVersion = 1
+
+const (
+	TypePaymentRequested = "PaymentRequested"
+	TypePaymentSettled   = "PaymentSettled"
+	TypePaymentFailed    = "PaymentFailed"
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("events: unsupported schema version")
+	ErrUnexpectedType     = errors.New("events: unexpected event type")
+)
+
+func newHeader(eventType, producer string) *eventsv1.EventHeader {
+	return &eventsv1.EventHeader{
+		SchemaVersion: SchemaVersion,
+		EventType:     eventType,
+		EventId:       uuid.NewString(),
+		OccurredAtMs:  time.Now().UnixMilli(),
+		Producer:      producer,
+	}
+}
+
+// isJSON: JSON lama selalu object; protobuf dengan header di field 1 selalu
+// diawali 0x0A, jadi byte pertama cukup untuk membedakan.
+func isJSON(b []byte) bool {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	return len(b) > 0 && b[0] == '{'
+}
+
+func readHeader(b []byte) (*eventsv1.EventHeader, error) {
+	var h eventsv1.HeaderOnly
+	if err := proto.Unmarshal(b, &h); err != nil {
+		return nil, fmt.Errorf("events: decode header: %w", err)
+	}
+	hdr := h.GetHeader()
+	if hdr == nil {
+		return nil, errors.New("events: missing header")
+	}
+	if hdr.GetSchemaVersion() > SchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, hdr.GetSchemaVersion())
+	}
+	return hdr, nil
+}
+
+// ===== payments.request =====
+
+// EncodeRequested fills in the header and marshals the event.
+func EncodeRequested(e *eventsv1.PaymentRequested, producer string) ([]byte, error) {
+	e.Header = newHeader(TypePaymentRequested, producer)
+	return proto.Marshal(e)
+}
+
+// legacyRequest: map JSON yang dulu dipublish gateway.
+type legacyRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	SenderID       string `json:"sender_id"`
+	ReceiverID     string `json:"receiver_id"`
+	AmountIDR      int64  `json:"amount_idr"`
+	CurrencyInput  string `json:"currency_input"`
+	TxDate         string `json:"tx_date"`
+}
+
+func DecodeRequested(b []byte) (*eventsv1.PaymentRequested, error) {
+	if isJSON(b) {
+		var in legacyRequest
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, fmt.Errorf("events: decode legacy request: %w", err)
+		}
+		return &eventsv1.PaymentRequested{
+			Header:         &eventsv1.EventHeader{EventType: TypePaymentRequested},
+			IdempotencyKey: in.IdempotencyKey,
+			SenderId:       in.SenderID,
+			ReceiverId:     in.ReceiverID,
+			AmountIdr:      in.AmountIDR,
+			CurrencyInput:  in.CurrencyInput,
+			TxDate:         in.TxDate,
+		}, nil
+	}
+
+	hdr, err := readHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if hdr.GetEventType() != TypePaymentRequested {
+		return nil, fmt.Errorf("%w: %q", ErrUnexpectedType, hdr.GetEventType())
+	}
+	var e eventsv1.PaymentRequested
+	if err := proto.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("events: decode PaymentRequested: %w", err)
+	}
+	return &e, nil
+}
+
+// ===== payments.result =====
+
+// Result is either *eventsv1.PaymentSettled or *eventsv1.PaymentFailed.
+type Result interface {
+	proto.Message
+	GetHeader() *eventsv1.EventHeader
+	GetIdempotencyKey() string
+	GetPaymentId() string
+	GetMerchantId() string
+	GetSenderId() string
+	GetReceiverId() string
+}
+
+// EncodeResult fills in the header and marshals a settled or failed event.
+func EncodeResult(e Result, producer string) ([]byte, error) {
+	switch e := e.(type) {
+	case *eventsv1.PaymentSettled:
+		e.Header = newHeader(TypePaymentSettled, producer)
+	case *eventsv1.PaymentFailed:
+		e.Header = newHeader(TypePaymentFailed, producer)
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, e)
+	}
+	return proto.Marshal(e)
+}
+
+// legacyResult: map JSON yang dulu dipublish worker (bentuk PaymentOut).
+type legacyResult struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	Ref    string `json:"ref"`
+}
+
+// DecodeResult decodes a result event. Legacy JSON carries no idempotency
+// key; the caller already knows it from the Kafka key.
+func DecodeResult(b []byte) (Result, error) {
+	if isJSON(b) {
+		var in legacyResult
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, fmt.Errorf("events: decode legacy result: %w", err)
+		}
+		if strings.HasPrefix(in.Status, "SUCCESS") {
+			return &eventsv1.PaymentSettled{
+				Header:    &eventsv1.EventHeader{EventType: TypePaymentSettled},
+				PaymentId: in.Ref,
+				Status:    in.Status,
+			}, nil
+		}
+		return &eventsv1.PaymentFailed{
+			Header:    &eventsv1.EventHeader{EventType: TypePaymentFailed},
+			PaymentId: in.Ref,
+			Reason:    in.Reason,
+		}, nil
+	}
+
+	hdr, err := readHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	var e Result
+	switch hdr.GetEventType() {
+	case TypePaymentSettled:
+		e = &eventsv1.PaymentSettled{}
+	case TypePaymentFailed:
+		e = &eventsv1.PaymentFailed{}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnexpectedType, hdr.GetEventType())
+	}
+	if err := proto.Unmarshal(b, e); err != nil {
+		return nil, fmt.Errorf("events: decode %s: %w", hdr.GetEventType(), err)
+	}
+	return e, nil
+}
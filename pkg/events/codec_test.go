@@ -0,0 +1,100 @@
+// payment-gateway-poc/pkg/events/codec_test.go
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+// Kontrak producer ↔ consumer: apa yang ditulis gateway/worker harus
+// terbaca di sisi lain, termasuk JSON dari producer lama.
+
+func TestRequestedRoundTrip(t *testing.T) {
+	b, err := EncodeRequested(&eventsv1.PaymentRequested{
+		IdempotencyKey: "k-1", SenderId: "ACC-1", ReceiverId: "ACC-2",
+		AmountIdr: 150_000, CurrencyInput: "USD", TxDate: "2025-01-02T03:04:05Z",
+	}, "api-gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeRequested(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetHeader().GetSchemaVersion() != SchemaVersion || got.GetHeader().GetEventType() != TypePaymentRequested {
+		t.Fatalf("bad header: %+v", got.GetHeader())
+	}
+	if got.GetIdempotencyKey() != "k-1" || got.GetAmountIdr() != 150_000 || got.GetCurrencyInput() != "USD" {
+		t.Fatalf("bad payload: %+v", got)
+	}
+}
+
+func TestRequestedLegacyJSON(t *testing.T) {
+	// persis map yang dulu dipublish handlers.PaymentsHandler
+	legacy := []byte(`{"amount_idr":150000,"currency_input":"USD","idempotency_key":"k-1","receiver_id":"ACC-2","sender_id":"ACC-1","tx_date":"2025-01-02T03:04:05Z"}`)
+	got, err := DecodeRequested(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetHeader().GetSchemaVersion() != 0 {
+		t.Fatalf("legacy JSON should report version 0, got %d", got.GetHeader().GetSchemaVersion())
+	}
+	if got.GetSenderId() != "ACC-1" || got.GetReceiverId() != "ACC-2" || got.GetAmountIdr() != 150_000 {
+		t.Fatalf("bad payload: %+v", got)
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	for _, in := range []Result{
+		&eventsv1.PaymentSettled{IdempotencyKey: "k-1", PaymentId: "p-1", Status: "SUCCESS"},
+		&eventsv1.PaymentFailed{IdempotencyKey: "k-2", Reason: "insufficient_funds"},
+	} {
+		b, err := EncodeResult(in, "payments-worker")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeResult(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, in) {
+			t.Fatalf("round trip mismatch:\n got %v\nwant %v", got, in)
+		}
+	}
+}
+
+func TestResultLegacyJSON(t *testing.T) {
+	got, err := DecodeResult([]byte(`{"status":"SUCCESS","ref":"RSV-k-1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := got.(*eventsv1.PaymentSettled); !ok || s.GetStatus() != "SUCCESS" || s.GetPaymentId() != "RSV-k-1" {
+		t.Fatalf("unexpected %T %v", got, got)
+	}
+
+	got, err = DecodeResult([]byte(`{"status":"FAILED","reason":"insufficient_funds"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := got.(*eventsv1.PaymentFailed); !ok || f.GetReason() != "insufficient_funds" {
+		t.Fatalf("unexpected %T %v", got, got)
+	}
+}
+
+func TestRejectsNewerSchemaAndWrongType(t *testing.T) {
+	b, _ := proto.Marshal(&eventsv1.PaymentRequested{
+		Header: &eventsv1.EventHeader{SchemaVersion: SchemaVersion + 1, EventType: TypePaymentRequested},
+	})
+	if _, err := DecodeRequested(b); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	b, _ = EncodeResult(&eventsv1.PaymentFailed{IdempotencyKey: "k-1"}, "payments-worker")
+	if _, err := DecodeRequested(b); !errors.Is(err, ErrUnexpectedType) {
+		t.Fatalf("expected ErrUnexpectedType, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// pkg/health/health.go
+// Package health: readiness check yang bisa dipasang per service (ping DB,
+// metadata Kafka, grpc.health.v1 upstream, umur data). pkg/server
+// menjalankannya berkala dan memetakan hasilnya ke /readyz dan
+// grpc.health.v1; liveness sengaja tidak memakai check ini supaya
+// dependency yang mati tidak membuat pod di-restart berulang.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Check: nil = dependency sehat. ctx membawa batas waktu satu putaran.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// Millis: lama check, untuk melihat dependency yang mulai lambat.
+	Millis int64 `json:"ms"`
+}
+
+// Report: bentuk JSON /readyz (dan /healthz gateway).
+type Report struct {
+	OK      bool      `json:"ok"`
+	Service string    `json:"service"`
+	TS      time.Time `json:"ts"`
+	// Reason: "starting" (belum ada hasil check), "draining" (shutdown),
+	// atau kosong.
+	Reason string   `json:"reason,omitempty"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Checker: kumpulan check bernama; aman dipakai dari banyak goroutine.
+type Checker struct {
+	mu     sync.Mutex
+	names  []string
+	checks []Check
+}
+
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+func (c *Checker) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.checks)
+}
+
+// Run: semua check paralel, masing-masing dibatasi timeout; OK bila semua
+// lolos. Urutan Checks = urutan Add.
+func (c *Checker) Run(ctx context.Context, timeout time.Duration) Report {
+	c.mu.Lock()
+	names, checks := c.names, c.checks
+	c.mu.Unlock()
+
+	rep := Report{OK: true, TS: time.Now().UTC(), Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := run(cctx, check)
+			rep.Checks[i] = Result{Name: names[i], OK: err == nil, Millis: time.Since(start).Milliseconds()}
+			if err != nil {
+				rep.Checks[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	for _, r := range rep.Checks {
+		rep.OK = rep.OK && r.OK
+	}
+	return rep
+}
+
+// run: check yang mengabaikan ctx tetap dianggap gagal saat timeout.
+func run(ctx context.Context, check Check) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- check(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ======== check bawaan ========
+
+// Kafka: minta metadata ke broker pertama yang bisa dihubungi; bila topics
+// diisi, topic tersebut juga harus ada.
+func Kafka(brokers []string, topics ...string) Check {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, b := range brokers {
+			conn, err := kafka.DialContext(ctx, "tcp", b)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if dl, ok := ctx.Deadline(); ok {
+				_ = conn.SetDeadline(dl)
+			}
+			if len(topics) > 0 {
+				_, err = conn.ReadPartitions(topics...)
+			} else {
+				_, err = conn.Brokers()
+			}
+			conn.Close()
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, fmt.Errorf("%s: metadata: %w", b, err))
+		}
+		if len(errs) == 0 {
+			return errors.New("no brokers configured")
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// GRPC: grpc.health.v1 Check ke upstream. service "" = status server
+// keseluruhan. Upstream tanpa health service (Unimplemented, mis.
+// payments-rs) dianggap sehat karena sudah menjawab.
+func GRPC(conn grpc.ClientConnInterface, service string) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if status.Code(err) == codes.Unimplemented {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if s := resp.GetStatus(); s != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", s)
+		}
+		return nil
+	}
+}
+
+// Fresh: gagal bila last() lebih tua dari maxAge (kurs FX, snapshot data);
+// last() nol = belum pernah ada data.
+func Fresh(last func() time.Time, maxAge time.Duration) Check {
+	return func(context.Context) error {
+		t := last()
+		if t.IsZero() {
+			return errors.New("no data yet")
+		}
+		if age := time.Since(t); age > maxAge {
+			return fmt.Errorf("stale: last update %s ago (max %s)", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,182 @@
+// pkg/logging/logging.go
+// Package logging: log terstruktur (log/slog, JSON) untuk semua service.
+// Setup memasang logger default yang menambahkan ke setiap baris:
+//
+//   - service
+//   - trace_id/span_id dari span OpenTelemetry di context
+//   - field yang dibawa context lewat With: correlation_id, idempotency_key,
+//     payment_id, ...
+//
+// Field context hanya terbaca lewat varian *Context (slog.InfoContext,
+// slog.LogAttrs, ...). correlation_id, idempotency_key dan payment_id ikut
+// ke service lain lewat metadata gRPC dan header Kafka (propagate.go),
+// sehingga satu payment bisa di-grep dari gateway sampai settlement.
+// Level berlaku untuk seluruh proses dan bisa diganti saat runtime lewat
+// LevelHandler. Output package log juga diteruskan ke logger ini.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/example/payment-gateway-poc/pkg/config"
+)
+
+// level: satu untuk semua logger di proses ini, diubah oleh Setup dan
+// LevelHandler.
+var level = new(slog.LevelVar)
+
+func init() {
+	// sebelum Setup (config belum dimuat) log tetap JSON
+	slog.SetDefault(New(os.Stderr, "", "json"))
+}
+
+// Setup: logger default dari cfg; panggil sekali saat start (server.New).
+// Level kosong = info, format selain "text" = JSON.
+func Setup(service string, cfg config.Log) error {
+	var lv slog.Level
+	if cfg.Level != "" {
+		if err := lv.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return fmt.Errorf("logging: %w", err)
+		}
+	}
+	level.Set(lv)
+	slog.SetDefault(New(os.Stderr, service, cfg.Format))
+	return nil
+}
+
+// New: logger ke w dengan level proses dan field context; Setup memakainya
+// untuk logger default, test untuk menangkap log ke buffer.
+func New(w io.Writer, service, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewJSONHandler(w, opts)
+	if format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	}
+	if service != "" {
+		h = h.WithAttrs([]slog.Attr{slog.String("service", service)})
+	}
+	return slog.New(ctxHandler{h})
+}
+
+// Fatal: log level error lalu keluar, pengganti log.Fatalf.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// ======== field di context ========
+
+type fieldsKey struct{}
+
+// With: ctx turunan yang membawa field tambahan untuk setiap log dengan
+// ctx tersebut; args seperti slog.Info ("key", value, ... atau slog.Attr).
+// Key yang sudah ada diganti.
+func With(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+	fs := append([]slog.Attr(nil), fields(ctx)...)
+	r.Attrs(func(a slog.Attr) bool {
+		for i := range fs {
+			if fs[i].Key == a.Key {
+				fs[i] = a
+				return true
+			}
+		}
+		fs = append(fs, a)
+		return true
+	})
+	return context.WithValue(ctx, fieldsKey{}, fs)
+}
+
+func fields(ctx context.Context) []slog.Attr {
+	fs, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	return fs
+}
+
+// field: nilai string field key di ctx; "" bila tidak ada.
+func field(ctx context.Context, key string) string {
+	for _, a := range fields(ctx) {
+		if a.Key == key {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+// ctxHandler: menambahkan trace dan field context ke setiap record.
+type ctxHandler struct{ slog.Handler }
+
+func (h ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()))
+	}
+	r.AddAttrs(fields(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h ctxHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return ctxHandler{h.Handler.WithAttrs(as)}
+}
+
+func (h ctxHandler) WithGroup(name string) slog.Handler {
+	return ctxHandler{h.Handler.WithGroup(name)}
+}
+
+// ======== level runtime ========
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler: GET → {"level":"info"}; PUT {"level":"debug"} mengganti
+// level seluruh proses sampai restart (kembali ke LOG_LEVEL). Error
+// berbentuk {"error": ..., "detail": ...} seperti API gateway.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var in levelBody
+			var lv slog.Level
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&in); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad_json"})
+				return
+			}
+			if err := lv.UnmarshalText([]byte(in.Level)); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": "unknown_level", "detail": "level must be debug, info, warn or error"})
+				return
+			}
+			if old := level.Level(); old != lv {
+				level.Set(lv)
+				slog.WarnContext(r.Context(), "log level changed",
+					"from", levelName(old), "to", levelName(lv))
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method_not_allowed"})
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, levelBody{Level: levelName(level.Level())})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func levelName(l slog.Level) string { return strings.ToLower(l.String()) }
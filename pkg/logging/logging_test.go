@@ -0,0 +1,177 @@
+// pkg/logging/logging_test.go
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// capture: logger default ke buffer selama test; tiap baris JSON di-decode.
+func capture(t *testing.T) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	old := level.Level()
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, "test", "json"))
+	t.Cleanup(func() {
+		level.Set(old)
+		slog.SetDefault(prev)
+	})
+	return func() []map[string]any {
+		var lines []map[string]any
+		for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var m map[string]any
+			if err := json.Unmarshal([]byte(l), &m); err != nil {
+				t.Fatalf("not JSON: %q", l)
+			}
+			lines = append(lines, m)
+		}
+		return lines
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	lines := capture(t)
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+	ctx = With(ctx, FieldPaymentID, "p-1", FieldIdempotencyKey, "M-1:k")
+	ctx = With(ctx, FieldPaymentID, "p-2") // diganti, bukan duplikat
+
+	slog.InfoContext(ctx, "payment settled", "status", "SUCCESS")
+	slog.Info("no context")
+
+	got := lines()
+	if len(got) != 2 {
+		t.Fatalf("got %d lines", len(got))
+	}
+	for k, want := range map[string]any{
+		"msg": "payment settled", "service": "test", "status": "SUCCESS",
+		"payment_id": "p-2", "idempotency_key": "M-1:k",
+		"trace_id": tid.String(), "span_id": sid.String(),
+	} {
+		if got[0][k] != want {
+			t.Errorf("%s = %v, want %v", k, got[0][k], want)
+		}
+	}
+	if _, ok := got[1]["payment_id"]; ok {
+		t.Errorf("field leaked into log without ctx: %v", got[1])
+	}
+}
+
+// Field yang sama sampai di sisi lain lewat metadata gRPC dan header Kafka.
+func TestPropagation(t *testing.T) {
+	ctx := With(context.Background(),
+		FieldCorrelationID, "c-1", FieldIdempotencyKey, "M-1:k", FieldPaymentID, "p-1", "merchant_id", "M-1")
+	check := func(name string, got context.Context) {
+		t.Helper()
+		for k, want := range map[string]string{
+			FieldCorrelationID: "c-1", FieldIdempotencyKey: "M-1:k", FieldPaymentID: "p-1", "merchant_id": "",
+		} {
+			if v := field(got, k); v != want {
+				t.Errorf("%s: %s = %q, want %q", name, k, v, want)
+			}
+		}
+	}
+
+	out, _ := metadata.FromOutgoingContext(toMetadata(ctx))
+	check("grpc", fromMetadata(metadata.NewIncomingContext(context.Background(), out)))
+
+	var msg kafka.Message
+	Inject(ctx, &msg)
+	Inject(ctx, &msg) // publish ulang tidak menggandakan header
+	if len(msg.Headers) != 3 {
+		t.Errorf("headers: %v", msg.Headers)
+	}
+	check("kafka", Extract(context.Background(), msg))
+
+	// pemanggil tanpa metadata tetap dapat correlation id
+	if CorrelationID(fromMetadata(context.Background())) == "" {
+		t.Error("no correlation id generated")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	lines := capture(t)
+	var seen string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = CorrelationID(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	for _, tc := range []struct{ in, want string }{
+		{"abc-123", "abc-123"},
+		{"", ""},         // dibuat baru
+		{"bad id\n", ""}, // tidak dipakai
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/payments", nil)
+		if tc.in != "" {
+			req.Header.Set(HeaderCorrelationID, tc.in)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		got := rec.Header().Get(HeaderCorrelationID)
+		ok := got == tc.want
+		if tc.want == "" {
+			ok = got != "" && got != tc.in
+		}
+		if !ok || got != seen {
+			t.Errorf("in %q: header %q, handler saw %q", tc.in, got, seen)
+		}
+	}
+	access := lines()[0]
+	if access["msg"] != "http request" || access["status"] != float64(http.StatusAccepted) ||
+		access[FieldCorrelationID] != "abc-123" {
+		t.Errorf("access log: %v", access)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	capture(t)
+	level.Set(slog.LevelInfo)
+	srv := httptest.NewServer(LevelHandler())
+	defer srv.Close()
+
+	put := func(body string) int {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := put(`{"level":"verbose"}`); code != http.StatusBadRequest {
+		t.Errorf("unknown level: %d", code)
+	}
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("debug enabled before PUT")
+	}
+	if code := put(`{"level":"debug"}`); code != http.StatusOK {
+		t.Fatalf("PUT: %d", code)
+	}
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug still disabled after PUT")
+	}
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var got levelBody
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil || got.Level != "debug" {
+		t.Errorf("GET: %+v %v", got, err)
+	}
+}
@@ -0,0 +1,229 @@
+// pkg/logging/propagate.go
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Field yang diteruskan antar service.
+const (
+	FieldCorrelationID  = "correlation_id"
+	FieldIdempotencyKey = "idempotency_key"
+	FieldPaymentID      = "payment_id"
+)
+
+// HeaderCorrelationID: header HTTP request (opsional, dari client) dan
+// response gateway.
+const HeaderCorrelationID = "X-Correlation-ID"
+
+// propagated: field context → kunci metadata gRPC / header Kafka.
+var propagated = []struct{ field, key string }{
+	{FieldCorrelationID, "x-correlation-id"},
+	{FieldIdempotencyKey, "x-idempotency-key"},
+	{FieldPaymentID, "x-payment-id"},
+}
+
+// CorrelationID: correlation id di ctx; "" bila tidak ada.
+func CorrelationID(ctx context.Context) string { return field(ctx, FieldCorrelationID) }
+
+// validID: nilai dari luar dipakai hanya bila pendek dan ASCII tercetak
+// tanpa spasi (aman untuk header, metadata gRPC, dan log).
+func validID(s string) bool {
+	if s == "" || len(s) > 256 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] <= ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// ======== HTTP ========
+
+// Middleware: correlation id dari header X-Correlation-ID atau dibuat baru,
+// dikembalikan di response dan dibawa context; satu access log per request
+// (endpoint ops di level debug).
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderCorrelationID)
+		if !validID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(HeaderCorrelationID, id)
+		ctx := With(r.Context(), FieldCorrelationID, id)
+
+		mt := httpsnoop.CaptureMetrics(next, w, r.WithContext(ctx))
+
+		lv := slog.LevelInfo
+		switch {
+		case mt.Code >= 500:
+			lv = slog.LevelError
+		case quiet(r.URL.Path):
+			lv = slog.LevelDebug
+		}
+		slog.LogAttrs(ctx, lv, "http request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", mt.Code),
+			slog.Int64("bytes", mt.Written),
+			slog.Float64("duration_ms", durationMS(mt.Duration)))
+	})
+}
+
+func quiet(path string) bool {
+	switch path {
+	case "/metrics", "/healthz", "/livez", "/readyz":
+		return true
+	}
+	return false
+}
+
+func durationMS(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+
+// ======== gRPC ========
+
+// fromMetadata: field dari metadata masuk; correlation id dibuat baru bila
+// pemanggil tidak mengirimnya.
+func fromMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var args []any
+	for _, p := range propagated {
+		if v := md.Get(p.key); len(v) > 0 && validID(v[0]) {
+			args = append(args, p.field, v[0])
+		}
+	}
+	ctx = With(ctx, args...)
+	if CorrelationID(ctx) == "" {
+		ctx = With(ctx, FieldCorrelationID, uuid.NewString())
+	}
+	return ctx
+}
+
+// toMetadata: field di ctx → metadata keluar.
+func toMetadata(ctx context.Context) context.Context {
+	var kv []string
+	for _, p := range propagated {
+		if v := field(ctx, p.field); validID(v) {
+			kv = append(kv, p.key, v)
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// logRPC: RPC sukses di level debug; error server (Internal, Unavailable,
+// ...) di level error, error lain (validasi, saldo kurang) di info.
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	lv := slog.LevelDebug
+	switch code {
+	case codes.OK:
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		lv = slog.LevelError
+	default:
+		lv = slog.LevelInfo
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", durationMS(time.Since(start))),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	slog.LogAttrs(ctx, lv, "grpc request", attrs...)
+}
+
+// UnaryServerInterceptor: field dari metadata ke context handler, satu log per RPC.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = fromMetadata(ctx)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context { return s.ctx }
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := fromMetadata(ss.Context())
+		start := time.Now()
+		err := handler(srv, serverStream{ss, ctx})
+		logRPC(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor / StreamClientInterceptor: field di context →
+// metadata keluar.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(toMetadata(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(toMetadata(ctx), desc, cc, method, opts...)
+	}
+}
+
+// ======== Kafka ========
+
+// Inject: field di ctx → header msg (publish payments.request/result).
+func Inject(ctx context.Context, msg *kafka.Message) {
+	for _, p := range propagated {
+		v := field(ctx, p.field)
+		if v == "" {
+			continue
+		}
+		set := false
+		for i, h := range msg.Headers {
+			if h.Key == p.key {
+				msg.Headers[i].Value, set = []byte(v), true
+			}
+		}
+		if !set {
+			msg.Headers = append(msg.Headers, kafka.Header{Key: p.key, Value: []byte(v)})
+		}
+	}
+}
+
+// Extract: header msg → field di ctx (consumer).
+func Extract(ctx context.Context, msg kafka.Message) context.Context {
+	var args []any
+	for _, p := range propagated {
+		for _, h := range msg.Headers {
+			if h.Key == p.key && validID(string(h.Value)) {
+				args = append(args, p.field, string(h.Value))
+			}
+		}
+	}
+	if len(args) == 0 {
+		return ctx
+	}
+	return With(ctx, args...)
+}
@@ -27,10 +27,20 @@ var (
         },
         []string{"service", "status"},
     )
+
+    // Request per merchant (hanya request yang ter-autentikasi)
+    MerchantRequestsTotal = prometheus.NewCounterVec(
+        prometheus.CounterOpts{
+            Namespace: "payment",
+            Name:      "merchant_requests_total",
+            Help:      "Total request per merchant",
+        },
+        []string{"service", "merchant", "status"},
+    )
 )
 
 func init() {
-    prometheus.MustRegister(PaymentRequestsTotal, PaymentRequestDuration)
+    prometheus.MustRegister(PaymentRequestsTotal, PaymentRequestDuration, MerchantRequestsTotal)
 }
 
 // Helper biar rapi dipanggil dari handler
@@ -40,3 +50,6 @@ func IncRequest(service, status, method string) {
 func ObserveDuration(service, status string, seconds float64) {
     PaymentRequestDuration.WithLabelValues(service, status).Observe(seconds)
 }
+func IncMerchantRequest(service, merchant, status string) {
+    MerchantRequestsTotal.WithLabelValues(service, merchant, status).Inc()
+}
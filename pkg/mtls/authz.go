@@ -0,0 +1,140 @@
+// pkg/mtls/authz.go
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var denied = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "mtls",
+		Name:      "authz_denied_total",
+		Help:      "gRPC calls rejected by the identity policy",
+	},
+	[]string{"method", "identity"},
+)
+
+// TrustDomain: prefix URI SAN identitas service.
+const TrustDomain = "payment-gateway-poc"
+
+// Identitas yang dipakai di repo ini.
+const (
+	IdentityGateway  = "api-gateway"
+	IdentityOps      = "ops" // CLI seeding/admin
+	IdentityWallet   = "wallet-grpc"
+	IdentityFx       = "fx-grpc"
+	IdentityRisk     = "risk-grpc"
+	IdentityPayments = "payments-grpc"
+)
+
+// AnyIdentity: semua peer dengan cert valid dari CA.
+const AnyIdentity = "*"
+
+// IdentityURI: spiffe://payment-gateway-poc/<name>.
+func IdentityURI(name string) *url.URL {
+	return &url.URL{Scheme: "spiffe", Host: TrustDomain, Path: "/" + name}
+}
+
+// IdentityOf: nama service dari URI SAN cert; fallback ke CommonName.
+func IdentityOf(cert *x509.Certificate) string {
+	for _, u := range cert.URIs {
+		if u.Scheme == "spiffe" && u.Host == TrustDomain {
+			return strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	return cert.Subject.CommonName
+}
+
+// PeerIdentity: identitas pemanggil gRPC yang sudah lolos mTLS.
+func PeerIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return IdentityOf(info.State.VerifiedChains[0][0]), true
+}
+
+// Policy: full method ("/risk.v1.RiskService/Evaluate") atau prefix service
+// ("/risk.v1.RiskService/") → identitas yang boleh memanggil. Method yang
+// tidak tercantum ditolak.
+type Policy map[string][]string
+
+// DefaultPolicy: hanya gateway yang memanggil service bisnis; service admin
+// (seed) hanya untuk ops; health check boleh semua.
+var DefaultPolicy = Policy{
+	"/risk.v1.RiskService/":         {IdentityGateway},
+	"/fx.v1.FxService/Convert":      {IdentityGateway},
+	"/fx.v1.FxService/UpdateRates":  {IdentityGateway, IdentityOps}, // FxAdminService di gateway mendorong rate
+	"/wallet.v1.WalletService/":     {IdentityGateway},
+	"/payments.v1.PaymentsService/": {IdentityGateway},
+	"/wallet.v1.Admin/":             {IdentityOps},
+	"/fx.v1.Admin/":                 {IdentityOps},
+	"/fx.v1.FxAdminService/":        {IdentityOps},
+	"/risk.v1.Admin/":               {IdentityOps},
+	"/grpc.health.v1.Health/":       {AnyIdentity},
+}
+
+func (p Policy) allowed(method, identity string) bool {
+	ids, ok := p[method]
+	if !ok {
+		if i := strings.LastIndex(method, "/"); i > 0 {
+			ids, ok = p[method[:i+1]]
+		}
+	}
+	if !ok {
+		return false
+	}
+	for _, id := range ids {
+		if id == identity || id == AnyIdentity {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) authorize(ctx context.Context, method string) error {
+	id, ok := PeerIdentity(ctx)
+	if !ok {
+		denied.WithLabelValues(method, "").Inc()
+		return status.Error(codes.Unauthenticated, "mtls: no verified client certificate")
+	}
+	if !p.allowed(method, id) {
+		denied.WithLabelValues(method, id).Inc()
+		return status.Errorf(codes.PermissionDenied, "mtls: %q may not call %s", id, method)
+	}
+	return nil
+}
+
+func (p Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := p.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (p Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := p.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,266 @@
+// pkg/mtls/mtls.go
+// Package mtls: mutual TLS untuk semua trafik gRPC antar service. Sertifikat
+// tiap service membawa identitasnya (URI SAN spiffe://payment-gateway-poc/<nama>);
+// server memverifikasi client dengan CA yang sama dan interceptor Authz
+// membatasi method mana yang boleh dipanggil identitas mana.
+//
+// Cert, key, dan CA dibaca dari file dan dibaca ulang bila berubah, jadi
+// rotasi cukup dengan menimpa file tanpa restart.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	reloads = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "mtls",
+			Name:      "reloads_total",
+			Help:      "Certificate reloads by result",
+		},
+		[]string{"result"},
+	)
+	certExpiry = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "payment",
+		Subsystem: "mtls",
+		Name:      "cert_expiry_timestamp_seconds",
+		Help:      "NotAfter of the currently loaded certificate",
+	})
+)
+
+// Config: path file PEM. Tanpa CertFile, gRPC tetap plaintext (dev lokal).
+type Config struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+	// ReloadInterval: seberapa sering mtime file dicek, default 30s.
+	ReloadInterval time.Duration
+}
+
+// FromEnv: TLS_CERT_FILE, TLS_KEY_FILE, TLS_CA_FILE, TLS_RELOAD_INTERVAL.
+func FromEnv() Config {
+	c := Config{
+		CertFile: os.Getenv("TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("TLS_KEY_FILE"),
+		CAFile:   os.Getenv("TLS_CA_FILE"),
+	}
+	if d, err := time.ParseDuration(os.Getenv("TLS_RELOAD_INTERVAL")); err == nil {
+		c.ReloadInterval = d
+	}
+	return c
+}
+
+func (c Config) Enabled() bool { return c.CertFile != "" }
+
+func (c Config) validate() error {
+	if c.CertFile == "" || c.KeyFile == "" || c.CAFile == "" {
+		return errors.New("mtls: TLS_CERT_FILE, TLS_KEY_FILE and TLS_CA_FILE must all be set")
+	}
+	return nil
+}
+
+// material: satu set cert + CA yang sedang dipakai.
+type material struct {
+	cert *tls.Certificate
+	pool *x509.CertPool
+}
+
+// Source memegang cert/CA terkini dan membacanya ulang saat file berubah.
+type Source struct {
+	cfg     Config
+	current atomic.Pointer[material]
+
+	mu      sync.Mutex
+	modTime time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewSource membaca file sekali (error bila gagal) lalu mengecek perubahan
+// di background sampai Close.
+func NewSource(cfg Config) (*Source, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = 30 * time.Second
+	}
+	s := &Source{cfg: cfg, stop: make(chan struct{})}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	go s.watch()
+	return s, nil
+}
+
+func (s *Source) Close() { s.once.Do(func() { close(s.stop) }) }
+
+func (s *Source) watch() {
+	t := time.NewTicker(s.cfg.ReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			if !s.changed() {
+				continue
+			}
+			// gagal baca (mis. file baru setengah ditulis) → tetap pakai
+			// material lama, coba lagi di tick berikutnya
+			if err := s.Reload(); err != nil {
+				slog.Warn("mtls: reload failed", "error", err)
+			}
+		}
+	}
+}
+
+// changed: mtime terbaru dari ketiga file berbeda dari yang terakhir dimuat.
+func (s *Source) changed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return latestModTime(s.cfg) != s.modTime
+}
+
+func latestModTime(c Config) time.Time {
+	var latest time.Time
+	for _, f := range []string{c.CertFile, c.KeyFile, c.CAFile} {
+		if fi, err := os.Stat(f); err == nil && fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest
+}
+
+// Reload membaca ulang cert, key, dan CA sekarang juga.
+func (s *Source) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mod := latestModTime(s.cfg)
+	m, err := load(s.cfg)
+	if err != nil {
+		reloads.WithLabelValues("error").Inc()
+		return err
+	}
+	s.current.Store(m)
+	s.modTime = mod
+	reloads.WithLabelValues("ok").Inc()
+	certExpiry.Set(float64(m.cert.Leaf.NotAfter.Unix()))
+	return nil
+}
+
+func load(c Config) (*material, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("mtls: load key pair: %w", err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("mtls: parse certificate: %w", err)
+		}
+	}
+	caPEM, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("mtls: read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("mtls: no certificates in %s", c.CAFile)
+	}
+	return &material{cert: &cert, pool: pool}, nil
+}
+
+// ServerTLS: client wajib menunjukkan cert yang ditandatangani CA. Config
+// dibuat per handshake supaya CA hasil reload langsung berlaku.
+func (s *Source) ServerTLS() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			m := s.current.Load()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*m.cert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    m.pool,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// ClientTLS: verifikasi chain + hostname dilakukan sendiri di
+// VerifyConnection, karena RootCAs di tls.Config tidak bisa diganti setelah
+// credentials dibuat.
+func (s *Source) ClientTLS() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return s.current.Load().cert, nil
+		},
+		InsecureSkipVerify: true, // diganti VerifyConnection di bawah
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("mtls: server presented no certificate")
+			}
+			inter := x509.NewCertPool()
+			for _, c := range cs.PeerCertificates[1:] {
+				inter.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         s.current.Load().pool,
+				Intermediates: inter,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			return err
+		},
+	}
+}
+
+// ServerOptions: credentials + interceptor Authz untuk grpc.NewServer.
+// Tanpa TLS_CERT_FILE → tidak ada opsi (plaintext, tanpa authz), karena
+// identitas hanya bisa dibuktikan lewat cert.
+func ServerOptions(cfg Config, policy Policy) ([]grpc.ServerOption, *Source, error) {
+	if !cfg.Enabled() {
+		slog.Warn("mtls: TLS_CERT_FILE not set, gRPC server runs in plaintext")
+		return nil, nil, nil
+	}
+	src, err := NewSource(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []grpc.ServerOption{
+		grpc.Creds(credentials.NewTLS(src.ServerTLS())),
+		grpc.ChainUnaryInterceptor(policy.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(policy.StreamServerInterceptor()),
+	}, src, nil
+}
+
+// DialOption: transport credentials untuk grpc.Dial; plaintext bila TLS
+// tidak dikonfigurasi. Source boleh dipakai bersama beberapa koneksi.
+func DialOption(cfg Config) (grpc.DialOption, *Source, error) {
+	if !cfg.Enabled() {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil, nil
+	}
+	src, err := NewSource(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(src.ClientTLS())), src, nil
+}
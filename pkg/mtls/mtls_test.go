@@ -0,0 +1,152 @@
+// pkg/mtls/mtls_test.go
+package mtls
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type testPKI struct {
+	dir    string
+	ca     *DevCA
+	caFile string
+}
+
+func newPKI(t *testing.T) *testPKI {
+	t.Helper()
+	ca, err := NewDevCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.PEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &testPKI{dir: dir, ca: ca, caFile: caFile}
+}
+
+func (p *testPKI) issue(t *testing.T, name string) Config {
+	t.Helper()
+	c, err := p.ca.WriteFiles(p.dir, name, p.caFile, time.Hour, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ReloadInterval = 20 * time.Millisecond
+	return c
+}
+
+// serve: health server di balik mTLS dengan policy; Check hanya untuk
+// gateway, Watch untuk siapa saja.
+func serve(t *testing.T, cfg Config) string {
+	t.Helper()
+	policy := Policy{
+		"/grpc.health.v1.Health/Check": {IdentityGateway},
+		"/grpc.health.v1.Health/Watch": {AnyIdentity},
+	}
+	opts, src, err := ServerOptions(cfg, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { srv.Stop(); src.Close() })
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return "localhost:" + port
+}
+
+func check(t *testing.T, addr string, cfg Config) error {
+	t.Helper()
+	opt, src, err := DialOption(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src != nil {
+		defer src.Close()
+	}
+	conn, err := grpc.NewClient(addr, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestAuthzByIdentity(t *testing.T) {
+	pki := newPKI(t)
+	addr := serve(t, pki.issue(t, IdentityRisk))
+
+	if err := check(t, addr, pki.issue(t, IdentityGateway)); err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	if err := check(t, addr, pki.issue(t, IdentityOps)); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("ops: got %v, want PermissionDenied", err)
+	}
+}
+
+func TestRejectsForeignCA(t *testing.T) {
+	pki := newPKI(t)
+	addr := serve(t, pki.issue(t, IdentityRisk))
+
+	other := newPKI(t)
+	if err := check(t, addr, other.issue(t, IdentityGateway)); status.Code(err) != codes.Unavailable {
+		t.Fatalf("foreign CA: got %v, want Unavailable (handshake failure)", err)
+	}
+	if err := check(t, addr, Config{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("plaintext: got %v, want Unavailable", err)
+	}
+}
+
+func TestSourceReloadsRotatedCert(t *testing.T) {
+	pki := newPKI(t)
+	cfg := pki.issue(t, IdentityGateway)
+	src, err := NewSource(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	before := src.current.Load().cert.Leaf.SerialNumber
+
+	// pastikan mtime berbeda dari yang pertama
+	time.Sleep(20 * time.Millisecond)
+	if _, err := pki.ca.WriteFiles(pki.dir, IdentityGateway, pki.caFile, time.Hour, "localhost"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for src.current.Load().cert.Leaf.SerialNumber.Cmp(before) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPolicyPrefixMatch(t *testing.T) {
+	if !DefaultPolicy.allowed("/risk.v1.RiskService/Evaluate", IdentityGateway) {
+		t.Fatal("gateway should call RiskService.Evaluate")
+	}
+	if DefaultPolicy.allowed("/risk.v1.RiskService/Evaluate", IdentityFx) {
+		t.Fatal("fx-grpc must not call RiskService.Evaluate")
+	}
+	if DefaultPolicy.allowed("/unknown.v1.Svc/Do", IdentityGateway) {
+		t.Fatal("unlisted methods must be denied")
+	}
+}
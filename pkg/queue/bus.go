@@ -0,0 +1,34 @@
+// pkg/queue/bus.go
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Bus: semua yang dibutuhkan gateway & worker dari message broker.
+// Implementasi: KafkaBus (produksi) dan MemoryBus (in-process, untuk test
+// atau menjalankan gateway + worker tanpa Kafka).
+type Bus interface {
+	// Publish kirim payment request (key = sender_id).
+	Publish(ctx context.Context, key, payload []byte) error
+	// WaitResult tunggu result dengan key tertentu (idempotency key).
+	WaitResult(ctx context.Context, key []byte, timeout time.Duration) (value []byte, ok bool, err error)
+	// Subscribe baca request sebagai consumer group (dipakai worker).
+	Subscribe(group string) Subscription
+	// Reply kirim result untuk key tertentu.
+	Reply(ctx context.Context, key, payload []byte) error
+	// WatchResults panggil fn untuk setiap result yang dilihat instance ini
+	// (dipakai stream status). fn tidak boleh blocking.
+	WatchResults(fn func(key, payload []byte)) (stop func())
+	Close() error
+}
+
+// Subscription cocok dengan worker.Reader: offset di-commit oleh pemanggil.
+type Subscription interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
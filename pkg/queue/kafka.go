@@ -0,0 +1,298 @@
+// pkg/queue/kafka.go
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+)
+
+// KafkaBus: satu writer per topic untuk semua request, satu consumer result
+// di background yang membagikan result ke request yang menunggu. Worker
+// tidak butuh consumer result, jadi consumer baru jalan lewat
+// StartResultConsumer (gateway) atau saat WaitResult pertama.
+type KafkaBus struct {
+	Brokers      []string
+	RequestTopic string
+	ResultTopic  string
+	// ResultGroup: consumer group payments.result (KAFKA_RESULT_GROUP),
+	// harus stabil dan unik per replica (mis. nama pod StatefulSet).
+	// Kosong = tanpa consumer group: setiap partisi dibaca langsung mulai
+	// dari waktu StartResultConsumer (lihat startAt), jadi restart tidak
+	// meninggalkan group yatim di broker.
+	ResultGroup string
+
+	reqWriter *kafka.Writer
+	resWriter *kafka.Writer
+	results   *router
+
+	startOnce sync.Once
+	startAt   time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+const (
+	readerBackoffBase = 100 * time.Millisecond
+	readerBackoffMax  = 10 * time.Second
+)
+
+var _ Bus = (*KafkaBus)(nil)
+
+func NewKafka(brokers []string, reqTopic, resTopic string) *KafkaBus {
+	return &KafkaBus{
+		Brokers:      brokers,
+		RequestTopic: reqTopic,
+		ResultTopic:  resTopic,
+		reqWriter: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        reqTopic,
+			Balancer:     &kafka.Hash{}, // key = sender_id → partisi tetap per akun
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		resWriter: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        resTopic,
+			Balancer:     &kafka.LeastBytes{},
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		results: newRouter(),
+	}
+}
+
+// Publish/Reply: traceparent dan field log (correlation id, idempotency
+// key, payment id) ikut di header message, consumer (payments-worker,
+// webhook-worker) melanjutkan trace dan log yang sama.
+func (b *KafkaBus) Publish(ctx context.Context, key, payload []byte) error {
+	return write(ctx, b.reqWriter, kafka.Message{Key: key, Value: payload})
+}
+
+func (b *KafkaBus) Reply(ctx context.Context, key, payload []byte) error {
+	return write(ctx, b.resWriter, kafka.Message{Key: key, Value: payload, Time: time.Now()})
+}
+
+func write(ctx context.Context, w *kafka.Writer, m kafka.Message) error {
+	logging.Inject(ctx, &m)
+	ctx, span := tracing.StartPublish(ctx, w.Topic, &m)
+	defer span.End()
+	err := w.WriteMessages(ctx, m)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// WaitResult: tunggu message di topic result dg key yang sama
+func (b *KafkaBus) WaitResult(ctx context.Context, key []byte, timeout time.Duration) (value []byte, ok bool, err error) {
+	b.StartResultConsumer()
+	return b.results.wait(ctx, string(key), timeout)
+}
+
+func (b *KafkaBus) WatchResults(fn func(key, payload []byte)) func() {
+	b.StartResultConsumer()
+	return b.results.watch(fn)
+}
+
+// StartResultConsumer: panggil saat startup gateway, sebelum menerima
+// traffic, supaya join consumer group sudah selesai dan posisi awal reader
+// tanpa group (startAt) tidak lebih baru dari request pertama.
+func (b *KafkaBus) StartResultConsumer() {
+	b.startOnce.Do(b.startConsumer)
+}
+
+func (b *KafkaBus) Subscribe(group string) Subscription {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  b.Brokers,
+		Topic:    b.RequestTopic,
+		GroupID:  group,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+}
+
+// startConsumer: reader tanpa group diposisikan ke offset startAt, bukan
+// LastOffset. Posisi LastOffset baru diambil saat reader tersambung ke
+// broker; result yang diproduksi sebelum itu (cold start gateway, request
+// pertama sudah masuk) terlewat dan WaitResult sync jatuh ke 202. Mundur
+// resultTTL supaya result yang datang sebelum WaitResult tetap tertangkap.
+func (b *KafkaBus) startConsumer() {
+	b.startAt = time.Now().Add(-resultTTL)
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	go b.consume(ctx)
+}
+
+func (b *KafkaBus) consume(ctx context.Context) {
+	defer close(b.done)
+
+	sweep := time.NewTicker(resultTTL)
+	defer sweep.Stop()
+
+	msgs := make(chan kafka.Message)
+	var readers sync.WaitGroup
+	defer readers.Wait()
+	read := func(cfg kafka.ReaderConfig) {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			b.read(ctx, cfg, msgs)
+		}()
+	}
+
+	base := kafka.ReaderConfig{
+		Brokers:     b.Brokers,
+		Topic:       b.ResultTopic,
+		StartOffset: kafka.LastOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+	}
+	if b.ResultGroup != "" {
+		base.GroupID = b.ResultGroup
+		read(base)
+	} else {
+		// tiap gateway harus melihat semua result: baca semua partisi
+		partitions, ok := b.resultPartitions(ctx)
+		if !ok {
+			return
+		}
+		for _, p := range partitions {
+			cfg := base
+			cfg.Partition = p
+			read(cfg)
+		}
+	}
+
+	for {
+		select {
+		case m := <-msgs:
+			b.results.deliver(string(m.Key), m.Value)
+		case now := <-sweep.C:
+			b.results.expire(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// read: satu reader sampai ctx dibatalkan. Error baca (broker restart,
+// rebalance) tidak menghentikan consumer; reader dibuat ulang dengan backoff,
+// kalau tidak WaitResult sync hanya timeout tanpa pesan.
+func (b *KafkaBus) read(ctx context.Context, cfg kafka.ReaderConfig, out chan<- kafka.Message) {
+	delay := readerBackoffBase
+	next := int64(-1)
+	for {
+		r := kafka.NewReader(cfg)
+		if cfg.GroupID == "" {
+			if err := b.seek(ctx, r, next); err != nil {
+				r.Close()
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Warn("queue: result reader seek failed, retrying", "partition", cfg.Partition, "backoff", delay.String(), "error", err)
+				if !sleep(ctx, delay) {
+					return
+				}
+				delay = min(delay*2, readerBackoffMax)
+				continue
+			}
+		}
+		for {
+			m, err := r.ReadMessage(ctx)
+			if err != nil {
+				r.Close()
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Warn("queue: result reader failed, retrying", "partition", cfg.Partition, "backoff", delay.String(), "error", err)
+				break
+			}
+			delay = readerBackoffBase
+			next = m.Offset + 1
+			select {
+			case out <- m:
+			case <-ctx.Done():
+				r.Close()
+				return
+			}
+		}
+		if !sleep(ctx, delay) {
+			return
+		}
+		delay = min(delay*2, readerBackoffMax)
+	}
+}
+
+// seek: posisikan reader partisi. Pertama kali ke offset startAt (butuh
+// broker; gagal = reader dibuat ulang, bukan jatuh ke LastOffset), setelah
+// reconnect lanjut dari message setelah yang terakhir diterima.
+func (b *KafkaBus) seek(ctx context.Context, r *kafka.Reader, next int64) error {
+	if next >= 0 {
+		return r.SetOffset(next)
+	}
+	return r.SetOffsetAt(ctx, b.startAt)
+}
+
+// resultPartitions: daftar partisi topic result, diulang dengan backoff
+// selama broker belum bisa dihubungi.
+func (b *KafkaBus) resultPartitions(ctx context.Context) ([]int, bool) {
+	delay := readerBackoffBase
+	for {
+		var err error
+		for _, broker := range b.Brokers {
+			var ps []kafka.Partition
+			ps, err = kafka.DefaultDialer.LookupPartitions(ctx, "tcp", broker, b.ResultTopic)
+			if err == nil && len(ps) > 0 {
+				ids := make([]int, len(ps))
+				for i, p := range ps {
+					ids[i] = p.ID
+				}
+				return ids, true
+			}
+			if err == nil {
+				err = fmt.Errorf("topic %s has no partitions", b.ResultTopic)
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		slog.Warn("queue: lookup result partitions failed, retrying", "backoff", delay.String(), "error", err)
+		if !sleep(ctx, delay) {
+			return nil, false
+		}
+		delay = min(delay*2, readerBackoffMax)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close: hentikan consumer lalu tutup reader & writer.
+func (b *KafkaBus) Close() error {
+	var errs []error
+	b.startOnce.Do(func() {}) // jangan start consumer setelah Close
+	if b.cancel != nil {
+		b.cancel()
+		<-b.done
+	}
+	errs = append(errs, b.reqWriter.Close(), b.resWriter.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,186 @@
+// pkg/queue/memory.go
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+)
+
+// MemoryBus: bus in-process tanpa Kafka. Request disimpan sebagai log,
+// tiap consumer group punya cursor sendiri, result langsung diteruskan ke
+// router. Cocok untuk unit test dan dev lokal.
+//
+// Entri yang sudah di-fetch semua group dibuang dari log; sebelum ada
+// subscriber log dibatasi Retention entri (yang tertua dibuang, seperti
+// retention Kafka).
+type MemoryBus struct {
+	RequestTopic string
+	Retention    int // default defaultRetention
+
+	mu      sync.Mutex
+	log     []kafka.Message
+	base    int64            // offset log[0]
+	cursors map[string]int64 // group → offset berikutnya
+	notify  chan struct{}    // ditutup & diganti setiap ada publish
+
+	results *router
+	stop    chan struct{}
+	once    sync.Once
+}
+
+var _ Bus = (*MemoryBus)(nil)
+
+const defaultRetention = 10000
+
+func NewMemory(reqTopic string) *MemoryBus {
+	b := &MemoryBus{
+		RequestTopic: reqTopic,
+		cursors:      map[string]int64{},
+		notify:       make(chan struct{}),
+		results:      newRouter(),
+		stop:         make(chan struct{}),
+	}
+	go func() {
+		t := time.NewTicker(resultTTL)
+		defer t.Stop()
+		for {
+			select {
+			case now := <-t.C:
+				b.results.expire(now)
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, key, payload []byte) error {
+	m := kafka.Message{
+		Topic: b.RequestTopic,
+		Key:   append([]byte(nil), key...),
+		Value: append([]byte(nil), payload...),
+		Time:  time.Now(),
+	}
+	logging.Inject(ctx, &m)
+	_, span := tracing.StartPublish(ctx, b.RequestTopic, &m)
+	defer span.End()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m.Offset = b.base + int64(len(b.log))
+	b.log = append(b.log, m)
+	retention := b.Retention
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	if len(b.log) > retention {
+		b.trim(m.Offset + 1 - int64(retention))
+	}
+	close(b.notify)
+	b.notify = make(chan struct{})
+	return nil
+}
+
+// trim: buang entri dengan offset < upto. Dipanggil dengan mu terkunci.
+func (b *MemoryBus) trim(upto int64) {
+	n := int(upto - b.base)
+	if n <= 0 {
+		return
+	}
+	clear(b.log[:n]) // lepaskan payload dari backing array
+	b.log = b.log[n:]
+	b.base = upto
+}
+
+// Len: jumlah entri yang masih disimpan.
+func (b *MemoryBus) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.log)
+}
+
+func (b *MemoryBus) WaitResult(ctx context.Context, key []byte, timeout time.Duration) ([]byte, bool, error) {
+	return b.results.wait(ctx, string(key), timeout)
+}
+
+func (b *MemoryBus) Reply(ctx context.Context, key, payload []byte) error {
+	b.results.deliver(string(key), append([]byte(nil), payload...))
+	return nil
+}
+
+func (b *MemoryBus) WatchResults(fn func(key, payload []byte)) func() {
+	return b.results.watch(fn)
+}
+
+// Subscribe: group baru mulai dari entri tertua yang masih disimpan dan
+// sejak itu menahan trim sampai ikut fetch.
+func (b *MemoryBus) Subscribe(group string) Subscription {
+	b.mu.Lock()
+	if _, ok := b.cursors[group]; !ok {
+		b.cursors[group] = b.base
+	}
+	b.mu.Unlock()
+	return &memorySub{bus: b, group: group}
+}
+
+func (b *MemoryBus) Close() error {
+	b.once.Do(func() { close(b.stop) })
+	return nil
+}
+
+// memorySub: consumer yang berbagi cursor per group (competing consumers).
+type memorySub struct {
+	bus   *MemoryBus
+	group string
+}
+
+func (s *memorySub) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		s.bus.mu.Lock()
+		off := max(s.bus.cursors[s.group], s.bus.base)
+		if off < s.bus.base+int64(len(s.bus.log)) {
+			m := s.bus.log[off-s.bus.base]
+			s.bus.cursors[s.group] = off + 1
+			s.bus.trim(s.bus.acked())
+			s.bus.mu.Unlock()
+			return m, nil
+		}
+		wait := s.bus.notify
+		s.bus.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-s.bus.stop:
+			return kafka.Message{}, context.Canceled
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		}
+	}
+}
+
+// acked: offset terendah yang belum di-fetch semua group. Dipanggil dengan
+// mu terkunci.
+func (b *MemoryBus) acked() int64 {
+	low := int64(-1)
+	for _, off := range b.cursors {
+		if low < 0 || off < low {
+			low = off
+		}
+	}
+	return low
+}
+
+// CommitMessages: tidak ada broker; message yang sudah di-fetch tidak
+// dikirim ulang.
+func (s *memorySub) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return nil
+}
+
+func (s *memorySub) Close() error { return nil }
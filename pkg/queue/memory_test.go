@@ -0,0 +1,58 @@
+// pkg/queue/memory_test.go
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestMemoryBusTrimsLog(t *testing.T) {
+	b := NewMemory("payments.request")
+	b.Retention = 3
+	defer b.Close()
+	ctx := context.Background()
+
+	// belum ada subscriber: hanya Retention entri terakhir yang disimpan
+	for i := 0; i < 5; i++ {
+		if err := b.Publish(ctx, []byte("k"), []byte(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := b.Len(); n != 3 {
+		t.Fatalf("Len = %d, want 3", n)
+	}
+
+	a, w := b.Subscribe("worker"), b.Subscribe("webhook")
+	m, err := a.FetchMessage(ctx)
+	if err != nil || m.Offset != 2 || string(m.Value) != "2" {
+		t.Fatalf("first fetch: offset %d value %q err %v", m.Offset, m.Value, err)
+	}
+	// group kedua belum fetch: entri tetap disimpan
+	if n := b.Len(); n != 3 {
+		t.Fatalf("Len = %d, want 3 while webhook group lags", n)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := w.FetchMessage(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := b.Len(); n != 2 {
+		t.Fatalf("Len = %d, want 2", n)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := a.FetchMessage(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := b.Len(); n != 0 {
+		t.Fatalf("Len = %d, want 0 after every group fetched", n)
+	}
+
+	if err := b.Publish(ctx, []byte("k"), []byte("5")); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := w.FetchMessage(ctx); err != nil || m.Offset != 5 {
+		t.Fatalf("after trim: offset %d err %v", m.Offset, err)
+	}
+}
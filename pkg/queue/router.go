@@ -0,0 +1,122 @@
+// pkg/queue/router.go
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// resultTTL: berapa lama result tanpa penunggu disimpan. Worker bisa lebih
+// cepat dari WaitResult (publish dulu, baru tunggu), jadi result yang datang
+// duluan ditahan sebentar.
+const resultTTL = 10 * time.Second
+
+type early struct {
+	value []byte
+	at    time.Time
+}
+
+// router membagikan result ke request yang menunggu berdasarkan key, dan
+// ke semua watcher (stream status) tanpa melihat key.
+type router struct {
+	mu       sync.Mutex
+	waiters  map[string][]chan []byte
+	early    map[string]early
+	watchers map[int]func(key, value []byte)
+	nextID   int
+}
+
+func newRouter() *router {
+	return &router{
+		waiters:  map[string][]chan []byte{},
+		early:    map[string]early{},
+		watchers: map[int]func(key, value []byte){},
+	}
+}
+
+func (r *router) watch(fn func(key, value []byte)) (stop func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.watchers[id] = fn
+	return func() {
+		r.mu.Lock()
+		delete(r.watchers, id)
+		r.mu.Unlock()
+	}
+}
+
+func (r *router) wait(ctx context.Context, k string, timeout time.Duration) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ch := make(chan []byte, 1)
+
+	r.mu.Lock()
+	if e, found := r.early[k]; found {
+		delete(r.early, k)
+		r.mu.Unlock()
+		return e.value, true, nil
+	}
+	r.waiters[k] = append(r.waiters[k], ch)
+	r.mu.Unlock()
+
+	select {
+	case v := <-ch:
+		return v, true, nil
+	case <-ctx.Done():
+		r.remove(k, ch)
+		return nil, false, ctx.Err()
+	}
+}
+
+func (r *router) remove(k string, ch chan []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ws := r.waiters[k]
+	for i, w := range ws {
+		if w == ch {
+			ws = append(ws[:i], ws[i+1:]...)
+			break
+		}
+	}
+	if len(ws) == 0 {
+		delete(r.waiters, k)
+	} else {
+		r.waiters[k] = ws
+	}
+}
+
+func (r *router) deliver(k string, v []byte) {
+	r.mu.Lock()
+	watchers := make([]func(key, value []byte), 0, len(r.watchers))
+	for _, fn := range r.watchers {
+		watchers = append(watchers, fn)
+	}
+	if ws, found := r.waiters[k]; found {
+		delete(r.waiters, k)
+		for _, ch := range ws {
+			ch <- v
+		}
+	} else {
+		// belum ada yang menunggu (atau milik instance lain): simpan sebentar
+		r.early[k] = early{value: v, at: time.Now()}
+	}
+	r.mu.Unlock()
+
+	for _, fn := range watchers {
+		fn([]byte(k), v)
+	}
+}
+
+func (r *router) expire(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, e := range r.early {
+		if now.Sub(e.at) > resultTTL {
+			delete(r.early, k)
+		}
+	}
+}
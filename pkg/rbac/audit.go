@@ -0,0 +1,39 @@
+// pkg/rbac/audit.go
+package rbac
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// AuditEntry: satu panggilan Admin, diizinkan atau tidak.
+type AuditEntry struct {
+	Time       time.Time `json:"ts"`
+	Method     string    `json:"method"`
+	Subject    string    `json:"subject,omitempty"` // dari token; kosong bila token tidak valid
+	Roles      []string  `json:"roles,omitempty"`
+	Peer       string    `json:"peer,omitempty"`        // identitas mTLS / alamat
+	ArgsDigest string    `json:"args_digest,omitempty"` // sha256 argumen, bukan argumennya
+	Outcome    string    `json:"outcome"`               // kode gRPC, mis. OK, PermissionDenied
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Auditor: tujuan audit log. Record tidak boleh menggagalkan RPC.
+type Auditor interface {
+	Record(ctx context.Context, e AuditEntry)
+}
+
+// LogAuditor: satu baris log "audit" per entry (field entry di bawah
+// "audit", plus correlation/trace id dari ctx).
+type LogAuditor struct{}
+
+func (LogAuditor) Record(ctx context.Context, e AuditEntry) {
+	slog.InfoContext(ctx, "audit", "audit", e)
+}
+
+// AuditFunc: adaptor fungsi → Auditor (mis. untuk test).
+type AuditFunc func(ctx context.Context, e AuditEntry)
+
+func (f AuditFunc) Record(ctx context.Context, e AuditEntry) { f(ctx, e) }
@@ -0,0 +1,164 @@
+// pkg/rbac/interceptor.go
+package rbac
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+)
+
+var adminCalls = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "admin",
+		Name:      "calls_total",
+		Help:      "Admin RPCs by method and outcome",
+	},
+	[]string{"method", "outcome"},
+)
+
+// Permissions: full method → role yang boleh memanggil. Method yang tidak
+// ada di sini tidak diperiksa oleh rbac (bukan RPC Admin).
+type Permissions map[string][]string
+
+// DefaultPermissions: saldo dan rate milik treasury, rule milik risk;
+// ops boleh seed akun untuk bootstrap environment.
+var DefaultPermissions = Permissions{
+	"/wallet.v1.Admin/SeedAccounts":            {RoleOps, RoleTreasury},
+	"/fx.v1.Admin/SeedRates":                   {RoleTreasury},
+	"/fx.v1.FxAdminService/RefreshRandomRates": {RoleTreasury},
+	"/risk.v1.Admin/SeedRules":                 {RoleRiskAnalyst},
+}
+
+// Guard: interceptor untuk RPC Admin.
+type Guard struct {
+	Secret      []byte
+	Permissions Permissions
+	Audit       Auditor
+	Now         func() time.Time
+}
+
+// NewGuardFromEnv: ADMIN_TOKEN_SECRET, permission default, audit ke log.
+// Tanpa secret semua RPC Admin ditolak.
+func NewGuardFromEnv() *Guard {
+	return &Guard{
+		Secret:      []byte(os.Getenv("ADMIN_TOKEN_SECRET")),
+		Permissions: DefaultPermissions,
+		Audit:       LogAuditor{},
+	}
+}
+
+type claimsKey struct{}
+
+// ClaimsFrom: claims pemanggil Admin yang sudah diverifikasi.
+func ClaimsFrom(ctx context.Context) (*Claims, bool) {
+	c, ok := ctx.Value(claimsKey{}).(*Claims)
+	return c, ok
+}
+
+func (g *Guard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+// authorize: Unauthenticated bila token hilang/tidak valid, PermissionDenied
+// bila role tidak cukup.
+func (g *Guard) authorize(ctx context.Context, method string, roles []string) (*Claims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var raw string
+	for _, v := range md.Get("authorization") {
+		if t, ok := strings.CutPrefix(v, "Bearer "); ok {
+			raw = t
+			break
+		}
+	}
+	if raw == "" {
+		return nil, status.Error(codes.Unauthenticated, "admin token required")
+	}
+	c, err := Verify(g.Secret, raw, g.now())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	for _, r := range roles {
+		if c.HasRole(r) {
+			return c, nil
+		}
+	}
+	return c, status.Errorf(codes.PermissionDenied, "%s requires one of roles %v", method, roles)
+}
+
+func (g *Guard) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		roles, ok := g.Permissions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		start := g.now()
+		claims, err := g.authorize(ctx, info.FullMethod, roles)
+		var resp any
+		if err == nil {
+			resp, err = handler(context.WithValue(ctx, claimsKey{}, claims), req)
+		}
+
+		e := AuditEntry{
+			Time:       start.UTC(),
+			Method:     info.FullMethod,
+			Peer:       peerName(ctx),
+			ArgsDigest: digest(req),
+			Outcome:    status.Code(err).String(),
+			DurationMs: g.now().Sub(start).Milliseconds(),
+		}
+		if claims != nil {
+			e.Subject, e.Roles = claims.Subject, claims.Roles
+		}
+		if err != nil {
+			e.Error = status.Convert(err).Message()
+		}
+		adminCalls.WithLabelValues(info.FullMethod, e.Outcome).Inc()
+		if g.Audit != nil {
+			g.Audit.Record(ctx, e)
+		}
+		return resp, err
+	}
+}
+
+// digest: sha256 dari encoding proto deterministik; argumen sendiri tidak
+// disimpan karena bisa berisi saldo/akun.
+func digest(req any) string {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func peerName(ctx context.Context) string {
+	if id, ok := mtls.PeerIdentity(ctx); ok {
+		return id
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,503 @@
+// pkg/server/server.go
+// Package server: runtime bersama semua service. Satu Server memegang
+// log JSON (server.log, lihat pkg/logging), tracing OpenTelemetry
+// (server.tracing), listener gRPC (span per RPC, interceptor log →
+// recovery → metrics → mTLS authz → milik service, plus grpc.health.v1),
+// listener admin (/metrics, /livez, /readyz, /loglevel, opsional
+// /debug/pprof/), listener HTTP tambahan, job background (consumer Kafka),
+// readiness check berkala, hook lifecycle, dan shutdown bertahap saat
+// SIGINT/SIGTERM:
+//
+//  1. readiness jadi NOT_SERVING / 503 supaya LB berhenti mengirim request;
+//     listener HTTP di-Shutdown dan gRPC di-GracefulStop bersamaan; lewat
+//     DrainTimeout sisa koneksi diputus paksa (Stop/Close)
+//  2. context job dibatalkan, ditunggu selesai (sisa waktu drain)
+//  3. hook OnStop dijalankan terbalik (tutup bus, pool DB, ...)
+//  4. listener admin terakhir, supaya /metrics tetap bisa di-scrape selama
+//     drain; lalu span yang tersisa dikirim ke exporter
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+
+	gp "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+)
+
+// Hook: dijalankan saat start (sebelum listener melayani) atau stop.
+type Hook func(ctx context.Context) error
+
+type Options struct {
+	Name   string
+	Config config.Server
+	// TLS: mTLS untuk listener gRPC; kosong = plaintext.
+	TLS config.TLS
+	// Policy: authz mTLS per identitas; nil = mtls.DefaultPolicy.
+	Policy mtls.Policy
+	// Unary/Stream: interceptor milik service, dijalankan setelah log,
+	// recovery, metrics, dan authz mTLS.
+	Unary  []grpc.UnaryServerInterceptor
+	Stream []grpc.StreamServerInterceptor
+	// GRPCOptions: opsi tambahan grpc.NewServer (ukuran pesan, keepalive, ...).
+	GRPCOptions []grpc.ServerOption
+}
+
+type Server struct {
+	Name string
+	// GRPC: daftarkan service di sini sebelum Run; nil bila grpc_addr kosong.
+	GRPC *grpc.Server
+	// Admin: mux listener metrics_addr; service boleh menambah handler.
+	Admin *http.ServeMux
+	// Health: grpc.health.v1, sudah terdaftar di GRPC. Status "" dan semua
+	// service di GRPC mengikuti hasil readiness check; server gRPC lain
+	// (gateway h2c) boleh mendaftarkannya juga.
+	Health *grpchealth.Server
+
+	cfg    config.Server
+	certs  *mtls.Source
+	checks health.Checker
+	flush  func(context.Context) error // kirim span tersisa, paling akhir
+	// request di handler Drained; idle ditutup saat active = 0 selama drain
+	mu       sync.Mutex
+	active   int
+	draining bool
+	idle     chan struct{}
+	ready    health.Report
+	https    []*http.Server
+	jobs     []func(context.Context) error
+	onStart  []Hook
+	onStop   []Hook
+}
+
+func New(opts Options) (*Server, error) {
+	if err := logging.Setup(opts.Name, opts.Config.Log); err != nil {
+		return nil, fmt.Errorf("server: %w", err)
+	}
+	flush, err := tracing.Init(context.Background(), opts.Name, opts.Config.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("server: %w", err)
+	}
+	s := &Server{
+		Name:   opts.Name,
+		Admin:  http.NewServeMux(),
+		Health: grpchealth.NewServer(),
+		cfg:    opts.Config,
+		ready:  health.Report{Reason: "starting"},
+		flush:  flush,
+	}
+	s.Admin.Handle("/metrics", promhttp.Handler())
+	s.Admin.HandleFunc("/livez", s.Livez)
+	s.Admin.HandleFunc("/readyz", s.Readyz)
+	s.Admin.Handle("/loglevel", logging.LevelHandler())
+	if opts.Config.Pprof {
+		s.Admin.HandleFunc("/debug/pprof/", pprof.Index)
+		s.Admin.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		s.Admin.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		s.Admin.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		s.Admin.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	if opts.Config.GRPCAddr == "" {
+		return s, nil
+	}
+
+	policy := opts.Policy
+	if policy == nil {
+		policy = mtls.DefaultPolicy
+	}
+	tlsOpts, certs, err := mtls.ServerOptions(opts.TLS.MTLS(), policy)
+	if err != nil {
+		return nil, fmt.Errorf("server: mtls: %w", err)
+	}
+	s.certs = certs
+
+	grpcOpts := []grpc.ServerOption{
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), s.recoverUnary, gp.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor(), s.recoverStream, gp.StreamServerInterceptor),
+	}
+	grpcOpts = append(grpcOpts, tlsOpts...)
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(opts.Unary...),
+		grpc.ChainStreamInterceptor(opts.Stream...),
+	)
+	s.GRPC = grpc.NewServer(append(grpcOpts, opts.GRPCOptions...)...)
+	healthpb.RegisterHealthServer(s.GRPC, s.Health)
+	return s, nil
+}
+
+// Check: readiness check (lihat pkg/health), dijalankan tiap
+// health_interval selama server hidup. Daftarkan sebelum Run.
+func (s *Server) Check(name string, check health.Check) { s.checks.Add(name, check) }
+
+// Livez: proses hidup dan bisa melayani HTTP; tidak menjalankan check,
+// dependency yang mati tidak boleh membuat pod di-restart.
+func (s *Server) Livez(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, health.Report{OK: true, Service: s.Name, TS: time.Now().UTC()})
+}
+
+// Readyz: hasil readiness check terakhir; 503 sebelum check pertama
+// selesai, saat ada check gagal, dan selama drain.
+func (s *Server) Readyz(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	rep := s.ready
+	if s.draining {
+		rep = health.Report{Reason: "draining"}
+	}
+	s.mu.Unlock()
+	rep.Service = s.Name
+	if rep.TS.IsZero() {
+		rep.TS = time.Now().UTC()
+	}
+	writeReport(w, rep)
+}
+
+func writeReport(w http.ResponseWriter, rep health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !rep.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(rep)
+}
+
+// watch: readiness check berkala sampai ctx selesai (awal drain).
+func (s *Server) watch(ctx context.Context) {
+	if s.checks.Len() == 0 {
+		s.setReady(health.Report{OK: true, TS: time.Now().UTC()})
+		return
+	}
+	s.setReady(health.Report{Reason: "starting"})
+	t := time.NewTicker(s.cfg.HealthInterval)
+	defer t.Stop()
+	for {
+		s.setReady(s.checks.Run(ctx, s.cfg.HealthInterval))
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Server) setReady(rep health.Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return // Health.Shutdown sudah dipanggil
+	}
+	if rep.Reason == "" && (rep.OK != s.ready.OK || s.ready.Reason != "") {
+		if rep.OK {
+			slog.Info("ready")
+		} else {
+			for _, c := range rep.Checks {
+				if !c.OK {
+					slog.Warn("not ready", "check", c.Name, "error", c.Error)
+				}
+			}
+		}
+	}
+	s.ready = rep
+
+	st := healthpb.HealthCheckResponse_SERVING
+	if !rep.OK {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	s.Health.SetServingStatus("", st)
+	if s.GRPC != nil {
+		for name := range s.GRPC.GetServiceInfo() {
+			s.Health.SetServingStatus(name, st)
+		}
+	}
+}
+
+// HTTP: listener HTTP tambahan (REST gateway, receiver webhook) yang ikut
+// di-drain saat shutdown.
+func (s *Server) HTTP(addr string, h http.Handler) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	s.https = append(s.https, srv)
+	return srv
+}
+
+// Drained: http.Server.Shutdown tidak menunggu koneksi yang di-hijack,
+// termasuk h2c (gRPC lewat grpc.Server.ServeHTTP di port HTTP gateway;
+// GracefulStop juga tidak bisa dipakai di sana). Handler yang dibungkus
+// ikut ditunggu saat drain; request baru selama drain dapat 503
+// (gRPC: Unavailable) supaya client pindah ke replica lain.
+func (s *Server) Drained(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if s.draining {
+			s.mu.Unlock()
+			w.Header().Set("Connection", "close")
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		s.active++
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			if s.active--; s.active == 0 && s.idle != nil {
+				close(s.idle)
+				s.idle = nil
+			}
+			s.mu.Unlock()
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Go: job background selama server hidup; ctx dibatalkan saat shutdown.
+// Job yang berhenti dengan error (selain context.Canceled) ikut
+// menghentikan server.
+func (s *Server) Go(job func(ctx context.Context) error) { s.jobs = append(s.jobs, job) }
+
+func (s *Server) OnStart(h Hook) { s.onStart = append(s.onStart, h) }
+
+// OnStop: dijalankan terbalik dari urutan pendaftaran, setelah drain.
+func (s *Server) OnStop(h Hook) { s.onStop = append(s.onStop, h) }
+
+// Run melayani sampai ctx selesai, SIGINT/SIGTERM, atau salah satu
+// listener/job gagal; lalu shutdown bertahap. Error pertama dikembalikan.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// listen dulu: port bentrok gagal sebelum hook start jalan
+	var grpcLis net.Listener
+	if s.GRPC != nil {
+		gp.Register(s.GRPC)
+		lis, err := net.Listen("tcp", s.cfg.GRPCAddr)
+		if err != nil {
+			return fmt.Errorf("server: listen gRPC %s: %w", s.cfg.GRPCAddr, err)
+		}
+		grpcLis = lis
+	}
+	httpLis := make([]net.Listener, len(s.https))
+	for i, srv := range s.https {
+		lis, err := net.Listen("tcp", srv.Addr)
+		if err != nil {
+			closeAll(grpcLis, httpLis)
+			return fmt.Errorf("server: listen HTTP %s: %w", srv.Addr, err)
+		}
+		httpLis[i] = lis
+	}
+	var admin *http.Server
+	if s.cfg.MetricsAddr != "" {
+		admin = &http.Server{Addr: s.cfg.MetricsAddr, Handler: s.Admin, ReadHeaderTimeout: 10 * time.Second}
+		lis, err := net.Listen("tcp", admin.Addr)
+		if err != nil {
+			closeAll(grpcLis, httpLis)
+			return fmt.Errorf("server: listen metrics %s: %w", admin.Addr, err)
+		}
+		go s.serveHTTP(admin, lis, nil)
+		slog.Info("serving admin", "addr", admin.Addr)
+	}
+
+	for _, h := range s.onStart {
+		if err := h(ctx); err != nil {
+			closeAll(grpcLis, httpLis)
+			if admin != nil {
+				_ = admin.Close()
+			}
+			return fmt.Errorf("server: start: %w", err)
+		}
+	}
+
+	watchCtx, stopWatch := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopWatch()
+	go s.watch(watchCtx)
+
+	errc := make(chan error, 1+len(s.https)+len(s.jobs))
+	if grpcLis != nil {
+		go func() {
+			if err := s.GRPC.Serve(grpcLis); err != nil {
+				errc <- fmt.Errorf("serve gRPC: %w", err)
+			}
+		}()
+		slog.Info("serving gRPC", "addr", s.cfg.GRPCAddr)
+	}
+	for i, srv := range s.https {
+		go s.serveHTTP(srv, httpLis[i], errc)
+		slog.Info("serving HTTP", "addr", srv.Addr)
+	}
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+	var jobs sync.WaitGroup
+	for _, job := range s.jobs {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			if err := job(jobCtx); err != nil && !errors.Is(err, context.Canceled) {
+				errc <- fmt.Errorf("job: %w", err)
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", "drain_timeout", s.cfg.DrainTimeout.String())
+	case runErr = <-errc:
+		slog.Error("shutting down", "error", runErr)
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.cfg.DrainTimeout)
+	defer cancel()
+	stopWatch()
+	s.drain(drainCtx)
+
+	cancelJobs()
+	jobsDone := make(chan struct{})
+	go func() { jobs.Wait(); close(jobsDone) }()
+	select {
+	case <-jobsDone:
+	case <-drainCtx.Done():
+		select {
+		case <-jobsDone:
+		default:
+			slog.Warn("jobs still running after drain timeout")
+		}
+	}
+
+	var stopErrs []error
+	for i := len(s.onStop) - 1; i >= 0; i-- {
+		stopErrs = append(stopErrs, s.onStop[i](drainCtx))
+	}
+	if s.certs != nil {
+		s.certs.Close()
+	}
+	if admin != nil {
+		_ = admin.Shutdown(drainCtx)
+	}
+	if err := s.flush(drainCtx); err != nil {
+		slog.Warn("tracing: flush failed", "error", err)
+	}
+	slog.Info("stopped")
+	return errors.Join(runErr, errors.Join(stopErrs...))
+}
+
+// drain: HTTP Shutdown dan gRPC GracefulStop paralel, paksa berhenti bila
+// ctx habis.
+func (s *Server) drain(ctx context.Context) {
+	s.mu.Lock()
+	s.draining = true
+	// NOT_SERVING untuk semua service; client dengan health checking
+	// berhenti memilih instance ini
+	s.Health.Shutdown()
+	done := make(chan struct{})
+	if s.active == 0 {
+		close(done)
+	} else {
+		s.idle = done
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			slog.Warn("drain timeout with requests still in flight")
+		}
+	}()
+	for _, srv := range s.https {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("HTTP drain failed", "addr", srv.Addr, "error", err)
+				_ = srv.Close()
+			}
+		}()
+	}
+	if s.GRPC != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done := make(chan struct{})
+			go func() { s.GRPC.GracefulStop(); close(done) }()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				// Stop menutup koneksi; handler yang masih jalan tidak ditunggu
+				slog.Warn("gRPC drain timeout, closing remaining streams")
+				s.GRPC.Stop()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Server) serveHTTP(srv *http.Server, lis net.Listener, errc chan<- error) {
+	err := srv.Serve(lis)
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		return
+	}
+	if errc == nil {
+		slog.Error("HTTP listener failed", "addr", srv.Addr, "error", err)
+		return
+	}
+	errc <- fmt.Errorf("serve HTTP %s: %w", srv.Addr, err)
+}
+
+func closeAll(grpcLis net.Listener, httpLis []net.Listener) {
+	if grpcLis != nil {
+		_ = grpcLis.Close()
+	}
+	for _, l := range httpLis {
+		if l != nil {
+			_ = l.Close()
+		}
+	}
+}
+
+// recover*: panic di handler jadi codes.Internal, proses tetap hidup.
+func (s *Server) recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicked(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (s *Server) recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicked(ss.Context(), info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func panicked(ctx context.Context, method string, p any) error {
+	slog.ErrorContext(ctx, "panic in handler", "method", method,
+		"panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
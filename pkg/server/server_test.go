@@ -0,0 +1,296 @@
+// pkg/server/server_test.go
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/server"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// slow: Check ke service "slow" butuh 300ms (kecuali ctx batal), "panic"
+// membuat handler panic.
+func slow(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+	switch req.(*healthpb.HealthCheckRequest).GetService() {
+	case "slow":
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	case "panic":
+		panic("boom")
+	}
+	return h(ctx, req)
+}
+
+func start(t *testing.T, drain time.Duration, setup func(*server.Server)) (healthpb.HealthClient, context.CancelFunc, <-chan error) {
+	t.Helper()
+	cfg := config.Server{GRPCAddr: freeAddr(t), MetricsAddr: freeAddr(t), Pprof: true,
+		DrainTimeout: drain, HealthInterval: 20 * time.Millisecond}
+	srv, err := server.New(server.Options{Name: "test", Config: cfg, Unary: []grpc.UnaryServerInterceptor{slow}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Health.SetServingStatus("slow", healthpb.HealthCheckResponse_SERVING)
+	if setup != nil {
+		setup(srv)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	started := make(chan struct{})
+	srv.OnStart(func(context.Context) error { close(started); return nil })
+	go func() { done <- srv.Run(ctx) }()
+	<-started
+
+	conn, err := grpc.NewClient(cfg.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn), cancel, done
+}
+
+// eventually: cond dicek ulang sampai 2 detik.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestGracefulStopDrainsInFlight(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	note := func(e string) { mu.Lock(); events = append(events, e); mu.Unlock() }
+	client, cancel, done := start(t, 5*time.Second, func(srv *server.Server) {
+		srv.OnStop(func(context.Context) error { note("stop1"); return nil })
+		srv.OnStop(func(context.Context) error { note("stop2"); return nil })
+		srv.Go(func(ctx context.Context) error { <-ctx.Done(); note("job"); return ctx.Err() })
+	})
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("panic: %v", err)
+	}
+
+	rpc := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "slow"})
+		rpc <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel() // = SIGTERM
+
+	if err := <-rpc; err != nil {
+		t.Fatalf("in-flight RPC cut off: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := strings.Join(events, ","); got != "job,stop2,stop1" {
+		t.Fatalf("lifecycle order %s", got)
+	}
+}
+
+func TestDrainTimeoutForcesStop(t *testing.T) {
+	client, cancel, done := start(t, 50*time.Millisecond, nil)
+	rpc := make(chan error, 1)
+	go func() {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "slow"})
+		rpc <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	begin := time.Now()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if d := time.Since(begin); d > 250*time.Millisecond {
+		t.Fatalf("shutdown took %s, want ~drain timeout", d)
+	}
+	if err := <-rpc; status.Code(err) != codes.Unavailable {
+		t.Fatalf("forced stop: %v", err)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var srv *server.Server
+	client, cancel, done := start(t, time.Second, func(s *server.Server) {
+		srv = s
+		s.Check("db", func(context.Context) error {
+			if down.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+	})
+	grpcStatus := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.GetStatus()
+	}
+	readyz := func() (int, health.Report) {
+		rec := httptest.NewRecorder()
+		srv.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var rep health.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, rep
+	}
+
+	eventually(t, "failing check reported", func() bool {
+		_, rep := readyz()
+		return len(rep.Checks) == 1 && rep.Checks[0].Error == "connection refused"
+	})
+	if code, _ := readyz(); code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz with db down: %d", code)
+	}
+	if st := grpcStatus(healthpb.Health_ServiceDesc.ServiceName); st != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("gRPC health with db down: %s", st)
+	}
+	rec := httptest.NewRecorder()
+	srv.Livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("livez must not depend on checks: %d", rec.Code)
+	}
+
+	down.Store(false)
+	eventually(t, "ready", func() bool {
+		code, _ := readyz()
+		return code == http.StatusOK && grpcStatus("") == healthpb.HealthCheckResponse_SERVING
+	})
+
+	// setelah drain mulai: 503 walau check lolos
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if code, rep := readyz(); code != http.StatusServiceUnavailable || rep.Reason != "draining" {
+		t.Fatalf("readyz while draining: %d %+v", code, rep)
+	}
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	cfg := config.Server{MetricsAddr: freeAddr(t), Pprof: true, DrainTimeout: time.Second, HealthInterval: time.Second}
+	srv, err := server.New(server.Options{Name: "test", Config: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.GRPC != nil {
+		t.Fatal("gRPC server without grpc_addr")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+	defer func() { cancel(); <-done }()
+
+	for _, path := range []string{"/metrics", "/livez", "/debug/pprof/"} {
+		var resp *http.Response
+		for i := 0; i < 50; i++ { // listener admin jalan di goroutine
+			if resp, err = http.Get("http://" + cfg.MetricsAddr + path); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestDrainedHTTP(t *testing.T) {
+	srv, err := server.New(server.Options{Name: "test", Config: config.Server{DrainTimeout: 5 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entered := make(chan struct{})
+	finished := false
+	addr := freeAddr(t)
+	srv.HTTP(addr, srv.Drained(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// h2c: koneksi di-hijack, Shutdown tidak menunggu
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		close(entered)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+		finished = true
+	})))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	res := make(chan error, 1)
+	go func() {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get("http://" + addr)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					err = fmt.Errorf("status %d", resp.StatusCode)
+				}
+				res <- err
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		res <- errors.New("server not reachable")
+	}()
+	<-entered
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !finished {
+		t.Fatal("Run returned before the hijacked request finished")
+	}
+	if err := <-res; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+// pkg/tenant/tenant.go
+// Package tenant: id tenant (= merchant id) yang sedang dilayani, dibawa di
+// context dan diteruskan ke service lain lewat metadata gRPC x-tenant-id.
+// Tenant kosong berarti namespace bersama (akun demo tanpa merchant).
+package tenant
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+)
+
+// MetadataKey: header gRPC pembawa tenant.
+const MetadataKey = "x-tenant-id"
+
+type ctxKey struct{}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// ID: tenant dari context; "" bila tidak ada.
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Key: namespace key milik client (idempotency key, dsb.) per tenant, supaya
+// dua merchant boleh memakai key yang sama tanpa saling bertabrakan.
+func Key(id, key string) string {
+	if id == "" {
+		return key
+	}
+	return id + ":" + key
+}
+
+// ClientKey: kebalikan Key, key seperti dikirim client. Dipakai di semua
+// yang keluar ke merchant (status, stream, webhook) supaya namespace
+// internal tidak bocor.
+func ClientKey(id, key string) string {
+	return strings.TrimPrefix(key, Key(id, ""))
+}
+
+// UnaryClientInterceptor: tenant di context → metadata keluar.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := ID(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor: metadata masuk → tenant di context. x-tenant-id
+// hanya dipercaya dari peer mTLS dengan identitas gateway (yang mengambilnya
+// dari API key); dari pemanggil lain → PermissionDenied, karena header itu
+// bisa diisi siapa saja. allowPlaintext: tanpa mTLS sama sekali header tetap
+// diterima, hanya untuk dev lokal; peer TLS selain gateway tetap ditolak.
+func UnaryServerInterceptor(allowPlaintext bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		v := md.Get(MetadataKey)
+		if len(v) == 0 {
+			return handler(ctx, req)
+		}
+		id, verified := mtls.PeerIdentity(ctx)
+		if verified && id != mtls.IdentityGateway || !verified && !allowPlaintext {
+			return nil, status.Errorf(codes.PermissionDenied, "%s is only accepted from %s", MetadataKey, mtls.IdentityGateway)
+		}
+		return handler(WithID(ctx, v[0]), req)
+	}
+}
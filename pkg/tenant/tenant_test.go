@@ -0,0 +1,64 @@
+// pkg/tenant/tenant_test.go
+package tenant
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+)
+
+// from: context server dengan x-tenant-id; identity "" = tanpa mTLS.
+func from(identity string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "M-1"))
+	if identity == "" {
+		return peer.NewContext(ctx, &peer.Peer{})
+	}
+	cert := &x509.Certificate{URIs: []*url.URL{mtls.IdentityURI(identity)}}
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: info})
+}
+
+func TestUnaryServerInterceptorTrustsOnlyGateway(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, _ any) (any, error) { got = ID(ctx); return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/wallet.v1.WalletService/GetAccount"}
+
+	for _, tc := range []struct {
+		name           string
+		identity       string
+		allowPlaintext bool
+		want           codes.Code
+	}{
+		{"gateway", mtls.IdentityGateway, false, codes.OK},
+		{"other service", mtls.IdentityRisk, false, codes.PermissionDenied},
+		{"other service, plaintext allowed", mtls.IdentityOps, true, codes.PermissionDenied},
+		{"plaintext", "", false, codes.PermissionDenied},
+		{"plaintext allowed", "", true, codes.OK},
+	} {
+		got = ""
+		_, err := UnaryServerInterceptor(tc.allowPlaintext)(from(tc.identity), nil, info, handler)
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: %v, want %s", tc.name, err, tc.want)
+		}
+		if tc.want == codes.OK && got != "M-1" {
+			t.Errorf("%s: tenant %q, want M-1", tc.name, got)
+		}
+	}
+
+	// tanpa header: namespace bersama, siapa pun pemanggilnya
+	got = "x"
+	if _, err := UnaryServerInterceptor(false)(context.Background(), nil, info, handler); err != nil || got != "" {
+		t.Fatalf("no header: tenant %q err %v", got, err)
+	}
+}
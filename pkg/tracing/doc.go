@@ -1,3 +0,0 @@
-// payment-gateway-poc/pkg/tracing/doc.go
-// Package tracing: init OpenTelemetry (placeholder)
-package tracing
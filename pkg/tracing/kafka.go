@@ -0,0 +1,72 @@
+// pkg/tracing/kafka.go
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headers: propagation.TextMapCarrier di atas header kafka.Message.
+type headers struct{ msg *kafka.Message }
+
+func (h headers) Get(key string) string {
+	for _, hd := range h.msg.Headers {
+		if hd.Key == key {
+			return string(hd.Value)
+		}
+	}
+	return ""
+}
+
+func (h headers) Set(key, value string) {
+	for i, hd := range h.msg.Headers {
+		if hd.Key == key {
+			h.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	h.msg.Headers = append(h.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (h headers) Keys() []string {
+	keys := make([]string, len(h.msg.Headers))
+	for i, hd := range h.msg.Headers {
+		keys[i] = hd.Key
+	}
+	return keys
+}
+
+// StartPublish: span producer "<topic> publish"; traceparent ditulis ke
+// header msg. topic terpisah karena kafka.Writer dengan Topic menolak
+// message yang Topic-nya diisi.
+func StartPublish(ctx context.Context, topic string, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("messaging.kafka.message.key", string(msg.Key)),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, headers{msg})
+	return ctx, span
+}
+
+// StartConsume: span consumer "<topic> process" sebagai child span
+// producer dari header msg.
+func StartConsume(ctx context.Context, msg kafka.Message) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headers{&msg})
+	return otel.Tracer(tracerName).Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.kafka.message.key", string(msg.Key)),
+			attribute.Int("messaging.kafka.destination.partition", msg.Partition),
+			attribute.String("messaging.kafka.message.offset", strconv.FormatInt(msg.Offset, 10)),
+		))
+}
@@ -0,0 +1,110 @@
+// pkg/tracing/tracing.go
+// Package tracing: OpenTelemetry untuk semua service. Init memasang
+// TracerProvider dan propagator W3C (traceparent, baggage) global; helper
+// di sini menyambungkan trace lewat HTTP (router gateway), gRPC (stats
+// handler client & server) dan header Kafka, sehingga satu payment bisa
+// diikuti dari POST /api/payments sampai settlement di payments-worker.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"github.com/example/payment-gateway-poc/pkg/config"
+)
+
+const tracerName = "github.com/example/payment-gateway-poc/pkg/tracing"
+
+func init() {
+	// propagator selalu terpasang: tanpa exporter pun traceparent yang
+	// masuk tetap diteruskan ke downstream
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Init: exporter dari cfg ("none"/kosong: span tidak direkam). shutdown
+// mengirim span yang tersisa; panggil paling akhir saat proses berhenti.
+func Init(ctx context.Context, service string, cfg config.Tracing) (shutdown func(context.Context) error, err error) {
+	var exp sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exp, err = stdouttrace.New()
+	case "otlp":
+		exp, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithInsecure())
+	default:
+		err = fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+	return Install(service, exp, cfg.SampleRatio), nil
+}
+
+// Install: TracerProvider global dengan exporter apa saja (test memakai
+// stdouttrace ke buffer). Sampling mengikuti parent; trace baru direkam
+// dengan peluang ratio.
+func Install(service string, exp sdktrace.SpanExporter, ratio float64) (shutdown func(context.Context) error) {
+	res, _ := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", service)))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown
+}
+
+// Start: span internal (settlement, batch, ...) dari tracer global.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// ======== gRPC ========
+
+// health check (probe, readiness gateway tiap HEALTH_INTERVAL) tidak
+// dijadikan span
+var notHealth = otelgrpc.WithFilter(filters.Not(filters.HealthCheck()))
+
+// ServerOption: span per RPC masuk, parent dari metadata traceparent.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(notHealth))
+}
+
+// DialOption: span per RPC keluar, traceparent dikirim sebagai metadata.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler(notHealth))
+}
+
+// ======== HTTP ========
+
+// Middleware: span per request dengan nama = template route mux
+// (/api/payments/{id}); endpoint ops tidak ditrace.
+func Middleware(service string) mux.MiddlewareFunc {
+	return otelmux.Middleware(service, otelmux.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/livez", "/readyz":
+			return false
+		}
+		return true
+	}))
+}
@@ -4,12 +4,13 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v3.21.12
+// 	protoc        (unknown)
 // source: fx/v1/fx.proto
 
 package fxv1
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -289,7 +290,7 @@ var File_fx_v1_fx_proto protoreflect.FileDescriptor
 
 const file_fx_v1_fx_proto_rawDesc = "" +
 	"\n" +
-	"\x0efx/v1/fx.proto\x12\x05fx.v1\"f\n" +
+	"\x0efx/v1/fx.proto\x12\x05fx.v1\x1a\x1cgoogle/api/annotations.proto\"f\n" +
 	"\x04Rate\x12#\n" +
 	"\rbase_currency\x18\x01 \x01(\tR\fbaseCurrency\x12%\n" +
 	"\x0equote_currency\x18\x02 \x01(\tR\rquoteCurrency\x12\x12\n" +
@@ -305,10 +306,10 @@ const file_fx_v1_fx_proto_rawDesc = "" +
 	"toCurrency\x12\x16\n" +
 	"\x06amount\x18\x03 \x01(\x01R\x06amount\")\n" +
 	"\x0fConvertResponse\x12\x16\n" +
-	"\x06amount\x18\x01 \x01(\x01R\x06amount2\x8b\x01\n" +
-	"\tFxService\x12D\n" +
-	"\vUpdateRates\x12\x19.fx.v1.UpdateRatesRequest\x1a\x1a.fx.v1.UpdateRatesResponse\x128\n" +
-	"\aConvert\x12\x15.fx.v1.ConvertRequest\x1a\x16.fx.v1.ConvertResponseB=Z;github.com/example/payment-gateway-poc/proto/gen/fx/v1;fxv1b\x06proto3"
+	"\x06amount\x18\x01 \x01(\x01R\x06amount2\xd1\x01\n" +
+	"\tFxService\x12]\n" +
+	"\vUpdateRates\x12\x19.fx.v1.UpdateRatesRequest\x1a\x1a.fx.v1.UpdateRatesResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/fx/rates\x12e\n" +
+	"\aConvert\x12\x15.fx.v1.ConvertRequest\x1a\x16.fx.v1.ConvertResponse\"+\x82\xd3\xe4\x93\x02%Z\x13:\x01*\"\x0e/v1/fx:convert\x12\x0e/v1/fx/convertB=Z;github.com/example/payment-gateway-poc/proto/gen/fx/v1;fxv1b\x06proto3"
 
 var (
 	file_fx_v1_fx_proto_rawDescOnce sync.Once
@@ -4,7 +4,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.21.12
+// - protoc             (unknown)
 // source: fx/v1/fx.proto
 
 package fxv1
@@ -29,6 +29,8 @@ const (
 // FxServiceClient is the client API for FxService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type FxServiceClient interface {
 	UpdateRates(ctx context.Context, in *UpdateRatesRequest, opts ...grpc.CallOption) (*UpdateRatesResponse, error)
 	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
@@ -65,6 +67,8 @@ func (c *fxServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts
 // FxServiceServer is the server API for FxService service.
 // All implementations must embed UnimplementedFxServiceServer
 // for forward compatibility.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type FxServiceServer interface {
 	UpdateRates(context.Context, *UpdateRatesRequest) (*UpdateRatesResponse, error)
 	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
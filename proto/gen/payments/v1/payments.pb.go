@@ -5,13 +5,14 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v3.21.12
+// 	protoc        (unknown)
 // source: payments/v1/payments.proto
 
 package paymentsv1
 
 import (
 	v1 "github.com/example/payment-gateway-poc/proto/gen/common/v1"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -304,7 +305,7 @@ var File_payments_v1_payments_proto protoreflect.FileDescriptor
 
 const file_payments_v1_payments_proto_rawDesc = "" +
 	"\n" +
-	"\x1apayments/v1/payments.proto\x12\vpayments.v1\x1a\x16common/v1/common.proto\"\xe4\x01\n" +
+	"\x1apayments/v1/payments.proto\x12\vpayments.v1\x1a\x16common/v1/common.proto\x1a\x1cgoogle/api/annotations.proto\"\xe4\x01\n" +
 	"\x14CreatePaymentRequest\x12!\n" +
 	"\famount_minor\x18\x01 \x01(\x03R\vamountMinor\x12/\n" +
 	"\bcurrency\x18\x02 \x01(\x0e2\x13.common.v1.CurrencyR\bcurrency\x12%\n" +
@@ -328,10 +329,10 @@ const file_payments_v1_payments_proto_rawDesc = "" +
 	"\x14LogAndSettleResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
-	"\x0ereservation_id\x18\x03 \x01(\tR\rreservationId2\xbe\x01\n" +
-	"\x0fPaymentsService\x12V\n" +
-	"\rCreatePayment\x12!.payments.v1.CreatePaymentRequest\x1a\".payments.v1.CreatePaymentResponse\x12S\n" +
-	"\fLogAndSettle\x12 .payments.v1.LogAndSettleRequest\x1a!.payments.v1.LogAndSettleResponseBIZGgithub.com/example/payment-gateway-poc/proto/gen/payments/v1;paymentsv1b\x06proto3"
+	"\x0ereservation_id\x18\x03 \x01(\tR\rreservationId2\xf7\x01\n" +
+	"\x0fPaymentsService\x12o\n" +
+	"\rCreatePayment\x12!.payments.v1.CreatePaymentRequest\x1a\".payments.v1.CreatePaymentResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/payments\x12s\n" +
+	"\fLogAndSettle\x12 .payments.v1.LogAndSettleRequest\x1a!.payments.v1.LogAndSettleResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/payments:settleBIZGgithub.com/example/payment-gateway-poc/proto/gen/payments/v1;paymentsv1b\x06proto3"
 
 var (
 	file_payments_v1_payments_proto_rawDescOnce sync.Once
@@ -5,7 +5,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.21.12
+// - protoc             (unknown)
 // source: payments/v1/payments.proto
 
 package paymentsv1
@@ -30,6 +30,8 @@ const (
 // PaymentsServiceClient is the client API for PaymentsService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type PaymentsServiceClient interface {
 	CreatePayment(ctx context.Context, in *CreatePaymentRequest, opts ...grpc.CallOption) (*CreatePaymentResponse, error)
 	LogAndSettle(ctx context.Context, in *LogAndSettleRequest, opts ...grpc.CallOption) (*LogAndSettleResponse, error)
@@ -66,6 +68,8 @@ func (c *paymentsServiceClient) LogAndSettle(ctx context.Context, in *LogAndSett
 // PaymentsServiceServer is the server API for PaymentsService service.
 // All implementations must embed UnimplementedPaymentsServiceServer
 // for forward compatibility.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type PaymentsServiceServer interface {
 	CreatePayment(context.Context, *CreatePaymentRequest) (*CreatePaymentResponse, error)
 	LogAndSettle(context.Context, *LogAndSettleRequest) (*LogAndSettleResponse, error)
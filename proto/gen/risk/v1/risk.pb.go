@@ -4,12 +4,13 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v3.21.12
+// 	protoc        (unknown)
 // source: risk/v1/risk.proto
 
 package riskv1
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -156,7 +157,7 @@ var File_risk_v1_risk_proto protoreflect.FileDescriptor
 
 const file_risk_v1_risk_proto_rawDesc = "" +
 	"\n" +
-	"\x12risk/v1/risk.proto\x12\arisk.v1\"\xb2\x01\n" +
+	"\x12risk/v1/risk.proto\x12\arisk.v1\x1a\x1cgoogle/api/annotations.proto\"\xb2\x01\n" +
 	"\fScoreRequest\x12\x1b\n" +
 	"\tsender_id\x18\x01 \x01(\tR\bsenderId\x12\x1f\n" +
 	"\vreceiver_id\x18\x02 \x01(\tR\n" +
@@ -167,9 +168,9 @@ const file_risk_v1_risk_proto_rawDesc = "" +
 	"\vtx_date_iso\x18\x05 \x01(\tR\ttxDateIso\"@\n" +
 	"\x10EvaluateResponse\x12\x14\n" +
 	"\x05allow\x18\x01 \x01(\bR\x05allow\x12\x16\n" +
-	"\x06reason\x18\x02 \x01(\tR\x06reason2K\n" +
-	"\vRiskService\x12<\n" +
-	"\bEvaluate\x12\x15.risk.v1.ScoreRequest\x1a\x19.risk.v1.EvaluateResponseBAZ?github.com/example/payment-gateway-poc/proto/gen/risk/v1;riskv1b\x06proto3"
+	"\x06reason\x18\x02 \x01(\tR\x06reason2i\n" +
+	"\vRiskService\x12Z\n" +
+	"\bEvaluate\x12\x15.risk.v1.ScoreRequest\x1a\x19.risk.v1.EvaluateResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/risk:evaluateBAZ?github.com/example/payment-gateway-poc/proto/gen/risk/v1;riskv1b\x06proto3"
 
 var (
 	file_risk_v1_risk_proto_rawDescOnce sync.Once
@@ -4,7 +4,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.21.12
+// - protoc             (unknown)
 // source: risk/v1/risk.proto
 
 package riskv1
@@ -28,6 +28,8 @@ const (
 // RiskServiceClient is the client API for RiskService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type RiskServiceClient interface {
 	Evaluate(ctx context.Context, in *ScoreRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
 }
@@ -53,6 +55,8 @@ func (c *riskServiceClient) Evaluate(ctx context.Context, in *ScoreRequest, opts
 // RiskServiceServer is the server API for RiskService service.
 // All implementations must embed UnimplementedRiskServiceServer
 // for forward compatibility.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type RiskServiceServer interface {
 	Evaluate(context.Context, *ScoreRequest) (*EvaluateResponse, error)
 	mustEmbedUnimplementedRiskServiceServer()
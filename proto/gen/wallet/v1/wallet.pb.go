@@ -3,13 +3,14 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.9
-// 	protoc        v3.21.12
+// 	protoc        (unknown)
 // source: wallet/v1/wallet.proto
 
 package walletv1
 
 import (
 	v1 "github.com/example/payment-gateway-poc/proto/gen/common/v1"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -543,7 +544,7 @@ var File_wallet_v1_wallet_proto protoreflect.FileDescriptor
 
 const file_wallet_v1_wallet_proto_rawDesc = "" +
 	"\n" +
-	"\x16wallet/v1/wallet.proto\x12\twallet.v1\x1a\x16common/v1/common.proto\"0\n" +
+	"\x16wallet/v1/wallet.proto\x12\twallet.v1\x1a\x16common/v1/common.proto\x1a\x1cgoogle/api/annotations.proto\"0\n" +
 	"\x18GetRandomAccountsRequest\x12\x14\n" +
 	"\x05count\x18\x01 \x01(\rR\x05count\"<\n" +
 	"\x19GetRandomAccountsResponse\x12\x1f\n" +
@@ -579,15 +580,15 @@ const file_wallet_v1_wallet_proto_rawDesc = "" +
 	"\x0ereservation_id\x18\x01 \x01(\tR\rreservationId\"9\n" +
 	"\x0fCaptureResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x16\n" +
-	"\x06reason\x18\x02 \x01(\tR\x06reason2\x89\x03\n" +
-	"\rWalletService\x12^\n" +
-	"\x11GetRandomAccounts\x12#.wallet.v1.GetRandomAccountsRequest\x1a$.wallet.v1.GetRandomAccountsResponse\x12I\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason2\x81\x05\n" +
+	"\rWalletService\x12\x82\x01\n" +
+	"\x11GetRandomAccounts\x12#.wallet.v1.GetRandomAccountsRequest\x1a$.wallet.v1.GetRandomAccountsResponse\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/wallet/random-accounts\x12s\n" +
 	"\n" +
-	"GetAccount\x12\x1c.wallet.v1.GetAccountRequest\x1a\x1d.wallet.v1.GetAccountResponse\x12I\n" +
+	"GetAccount\x12\x1c.wallet.v1.GetAccountRequest\x1a\x1d.wallet.v1.GetAccountResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /v1/wallet/accounts/{account_id}\x12{\n" +
 	"\n" +
-	"GetBalance\x12\x1c.wallet.v1.GetBalanceRequest\x1a\x1d.wallet.v1.GetBalanceResponse\x12@\n" +
-	"\aReserve\x12\x19.wallet.v1.ReserveRequest\x1a\x1a.wallet.v1.ReserveResponse\x12@\n" +
-	"\aCapture\x12\x19.wallet.v1.CaptureRequest\x1a\x1a.wallet.v1.CaptureResponseBEZCgithub.com/example/payment-gateway-poc/proto/gen/wallet/v1;walletv1b\x06proto3"
+	"GetBalance\x12\x1c.wallet.v1.GetBalanceRequest\x1a\x1d.wallet.v1.GetBalanceResponse\"0\x82\xd3\xe4\x93\x02*\x12(/v1/wallet/accounts/{account_id}/balance\x12z\n" +
+	"\aReserve\x12\x19.wallet.v1.ReserveRequest\x1a\x1a.wallet.v1.ReserveResponse\"8\x82\xd3\xe4\x93\x022:\x01*\"-/v1/wallet/accounts/{account_id}/reservations\x12}\n" +
+	"\aCapture\x12\x19.wallet.v1.CaptureRequest\x1a\x1a.wallet.v1.CaptureResponse\";\x82\xd3\xe4\x93\x025:\x01*\"0/v1/wallet/reservations/{reservation_id}:captureBEZCgithub.com/example/payment-gateway-poc/proto/gen/wallet/v1;walletv1b\x06proto3"
 
 var (
 	file_wallet_v1_wallet_proto_rawDescOnce sync.Once
@@ -3,7 +3,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.21.12
+// - protoc             (unknown)
 // source: wallet/v1/wallet.proto
 
 package walletv1
@@ -31,6 +31,8 @@ const (
 // WalletServiceClient is the client API for WalletService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type WalletServiceClient interface {
 	GetRandomAccounts(ctx context.Context, in *GetRandomAccountsRequest, opts ...grpc.CallOption) (*GetRandomAccountsResponse, error)
 	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*GetAccountResponse, error)
@@ -100,6 +102,8 @@ func (c *walletServiceClient) Capture(ctx context.Context, in *CaptureRequest, o
 // WalletServiceServer is the server API for WalletService service.
 // All implementations must embed UnimplementedWalletServiceServer
 // for forward compatibility.
+//
+// REST (gateway /v1, lihat services/api-gateway/transcode)
 type WalletServiceServer interface {
 	GetRandomAccounts(context.Context, *GetRandomAccountsRequest) (*GetRandomAccountsResponse, error)
 	GetAccount(context.Context, *GetAccountRequest) (*GetAccountResponse, error)
@@ -4,14 +4,16 @@ package admin
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
-	"os"
 	"time"
 
+	"github.com/example/payment-gateway-poc/pkg/discovery"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
 	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type AdminServer struct {
@@ -19,13 +21,9 @@ type AdminServer struct {
 	fxAddr string
 }
 
-func NewAdminServer() *AdminServer {
-	addr := os.Getenv("FX_ADDR")
-	if addr == "" {
-		// fallback: service name + port di docker compose
-		addr = "fx-grpc:9102"
-	}
-	return &AdminServer{fxAddr: addr}
+// NewAdminServer: fxAddr = config.Upstreams.Fx.
+func NewAdminServer(fxAddr string) *AdminServer {
+	return &AdminServer{fxAddr: fxAddr}
 }
 
 func (s *AdminServer) RefreshRandomRates(ctx context.Context, req *fxv1.RefreshRandomRatesRequest) (*fxv1.RefreshRandomRatesResponse, error) {
@@ -52,22 +50,27 @@ func (s *AdminServer) RefreshRandomRates(ctx context.Context, req *fxv1.RefreshR
 		{BaseCurrency: "SGD", QuoteCurrency: "USD", Rate: sgdUsd},
 	}
 
-	conn, err := grpc.Dial(s.fxAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, certs, err := mtls.DialOption(mtls.FromEnv())
 	if err != nil {
-		return nil, fmt.Errorf("connect FX %s: %w", s.fxAddr, err)
+		return nil, fmt.Errorf("mtls: %w", err)
 	}
-	defer conn.Close()
-
-	fxClient := fxv1.NewFxServiceClient(conn)
-
-	_, err = fxClient.UpdateRates(ctx, &fxv1.UpdateRatesRequest{Rates: outRates})
+	if certs != nil {
+		defer certs.Close()
+	}
+	// kurs disimpan per proses fx-grpc: kirim ke setiap replica, bukan ke
+	// satu endpoint pilihan balancer
+	endpoints, err := discovery.Resolve(ctx, s.fxAddr)
 	if err != nil {
-		return nil, fmt.Errorf("call UpdateRates: %w", err)
+		return nil, fmt.Errorf("resolve FX %s: %w", s.fxAddr, err)
+	}
+	for _, ep := range endpoints {
+		if err := pushRates(ctx, ep.Addr, ep.ServerName, creds, outRates); err != nil {
+			return nil, err
+		}
 	}
 
-	log.Printf("[FxAdmin] Pushed %d rates to FX (%s): USD/IDR=%.2f, SGD/IDR=%.2f, USD/SGD=%.4f",
-		len(outRates), s.fxAddr, usdIdr, sgdIdr, usdSgd,
-	)
+	slog.InfoContext(ctx, "fx admin: pushed random rates", "count", len(outRates), "fx_addr", s.fxAddr,
+		"replicas", len(endpoints), "usd_idr", usdIdr, "sgd_idr", sgdIdr, "usd_sgd", usdSgd)
 
 	// 4) Balas ke caller
 	return &fxv1.RefreshRandomRatesResponse{
@@ -76,3 +79,17 @@ func (s *AdminServer) RefreshRandomRates(ctx context.Context, req *fxv1.RefreshR
 		Pushed:  outRates,
 	}, nil
 }
+
+func pushRates(ctx context.Context, addr, serverName string, creds grpc.DialOption, rates []*fxv1.Rate) error {
+	conn, err := grpc.Dial(addr, creds, grpc.WithAuthority(serverName), tracing.DialOption(),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor()))
+	if err != nil {
+		return fmt.Errorf("connect FX %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if _, err := fxv1.NewFxServiceClient(conn).UpdateRates(ctx, &fxv1.UpdateRatesRequest{Rates: rates}); err != nil {
+		return fmt.Errorf("call UpdateRates on %s: %w", addr, err)
+	}
+	return nil
+}
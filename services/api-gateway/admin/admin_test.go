@@ -0,0 +1,143 @@
+// services/api-gateway/admin/admin_test.go
+package admin
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+	"github.com/example/payment-gateway-poc/pkg/server"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+)
+
+// fakeFx: FxService yang hanya mencatat UpdateRates.
+type fakeFx struct {
+	fxv1.UnimplementedFxServiceServer
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeFx) UpdateRates(context.Context, *fxv1.UpdateRatesRequest) (*fxv1.UpdateRatesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return &fxv1.UpdateRatesResponse{Success: true}, nil
+}
+
+func (f *fakeFx) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// FxAdminService lewat pkg/server dengan Guard, seperti di main gateway.
+func TestRefreshRandomRatesRequiresTreasury(t *testing.T) {
+	fx := &fakeFx{}
+	fxLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fxSrv := grpc.NewServer()
+	fxv1.RegisterFxServiceServer(fxSrv, fx)
+	go fxSrv.Serve(fxLis)
+	t.Cleanup(fxSrv.Stop)
+
+	secret := []byte("test-secret")
+	var mu sync.Mutex
+	var audit []rbac.AuditEntry
+	guard := &rbac.Guard{
+		Secret:      secret,
+		Permissions: rbac.DefaultPermissions,
+		Audit: rbac.AuditFunc(func(_ context.Context, e rbac.AuditEntry) {
+			mu.Lock()
+			audit = append(audit, e)
+			mu.Unlock()
+		}),
+	}
+	cfg := config.Server{GRPCAddr: freeAddr(t), DrainTimeout: time.Second, HealthInterval: time.Second}
+	srv, err := server.New(server.Options{Name: "test", Config: cfg,
+		Unary: []grpc.UnaryServerInterceptor{guard.UnaryServerInterceptor()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fxv1.RegisterFxAdminServiceServer(srv.GRPC, NewAdminServer(fxLis.Addr().String()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	started := make(chan struct{})
+	srv.OnStart(func(context.Context) error { close(started); return nil })
+	go func() { done <- srv.Run(ctx) }()
+	<-started
+	t.Cleanup(func() { cancel(); <-done })
+
+	conn, err := grpc.NewClient(cfg.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := fxv1.NewFxAdminServiceClient(conn)
+
+	call := func(roles ...string) error {
+		ctx := context.Background()
+		if roles != nil {
+			tok, err := rbac.Issue(secret, "alice", roles, time.Minute, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tok)
+		}
+		_, err := client.RefreshRandomRates(ctx, &fxv1.RefreshRandomRatesRequest{})
+		return err
+	}
+
+	if err := call(); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: %v, want Unauthenticated", err)
+	}
+	if err := call(rbac.RoleOps); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("ops: %v, want PermissionDenied", err)
+	}
+	if n := fx.count(); n != 0 {
+		t.Fatalf("UpdateRates called %d times before an authorized call", n)
+	}
+	if err := call(rbac.RoleTreasury); err != nil {
+		t.Fatalf("treasury: %v", err)
+	}
+	if n := fx.count(); n != 1 {
+		t.Fatalf("UpdateRates called %d times, want 1", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"Unauthenticated", "PermissionDenied", "OK"}
+	if len(audit) != len(want) {
+		t.Fatalf("audit entries %+v, want %d", audit, len(want))
+	}
+	for i, e := range audit {
+		if e.Method != fxv1.FxAdminService_RefreshRandomRates_FullMethodName || e.Outcome != want[i] {
+			t.Errorf("audit[%d] = %s %s, want %s", i, e.Method, e.Outcome, want[i])
+		}
+	}
+	if audit[2].Subject != "alice" {
+		t.Errorf("audit subject %q, want alice", audit[2].Subject)
+	}
+}
@@ -0,0 +1,49 @@
+// services/api-gateway/auth/grpc.go
+package auth
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryClientInterceptor menandatangani panggilan gRPC ke port gateway
+// dengan skema yang sama seperti REST: method POST, path = nama method gRPC
+// (/payments.v1.PaymentsService/CreatePayment), body = frame gRPC yang
+// dikirim (tanpa kompresi). Header signature dikirim sebagai metadata.
+func UnaryClientInterceptor(keyID, secret string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return fmt.Errorf("auth: cannot sign %T", req)
+		}
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("auth: sign: %w", err)
+		}
+		frame := make([]byte, 5+len(b))
+		binary.BigEndian.PutUint32(frame[1:5], uint32(len(b)))
+		copy(frame[5:], b)
+
+		nonce, err := randomHex(16)
+		if err != nil {
+			return err
+		}
+		ts := time.Now().Unix()
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			strings.ToLower(HeaderKeyID), keyID,
+			strings.ToLower(HeaderTimestamp), strconv.FormatInt(ts, 10),
+			strings.ToLower(HeaderNonce), nonce,
+			strings.ToLower(HeaderSignature), Signature(secret, StringToSign(http.MethodPost, method, ts, nonce, frame)),
+		)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
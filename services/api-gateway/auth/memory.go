@@ -0,0 +1,80 @@
+// services/api-gateway/auth/memory.go
+package auth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryKeys: KeyStore in-process untuk dev/test.
+type MemoryKeys struct {
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+var _ KeyStore = (*MemoryKeys)(nil)
+
+func NewMemoryKeys() *MemoryKeys { return &MemoryKeys{keys: map[string]*Key{}} }
+
+func (s *MemoryKeys) Create(ctx context.Context, k *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k.CreatedAt = time.Now().UTC()
+	cp := *k
+	s.keys[k.ID] = &cp
+	return nil
+}
+
+func (s *MemoryKeys) Get(ctx context.Context, id string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *k
+	return &cp, nil
+}
+
+func (s *MemoryKeys) List(ctx context.Context, merchantID string) ([]Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Key
+	for _, k := range s.keys {
+		if k.MerchantID == merchantID {
+			out = append(out, *k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *MemoryKeys) Expire(ctx context.Context, merchantID, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok || k.MerchantID != merchantID {
+		return ErrNotFound
+	}
+	if k.ExpiresAt == nil || at.Before(*k.ExpiresAt) {
+		at = at.UTC()
+		k.ExpiresAt = &at
+	}
+	return nil
+}
+
+func (s *MemoryKeys) Revoke(ctx context.Context, merchantID, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok || k.MerchantID != merchantID {
+		return ErrNotFound
+	}
+	if k.RevokedAt == nil {
+		at = at.UTC()
+		k.RevokedAt = &at
+	}
+	return nil
+}
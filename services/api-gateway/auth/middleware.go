@@ -0,0 +1,178 @@
+// services/api-gateway/auth/middleware.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+var failures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "gateway",
+		Name:      "auth_failures_total",
+		Help:      "Rejected signed requests by reason",
+	},
+	[]string{"reason"},
+)
+
+// Mode: GATEWAY_AUTH.
+type Mode string
+
+const (
+	ModeRequired Mode = "required" // request tanpa signature ditolak
+	ModeOptional Mode = "optional" // tanpa signature → anonim; signature tetap diverifikasi
+	ModeOff      Mode = "off"
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case ModeRequired, ModeOptional, ModeOff:
+		return m, nil
+	case "":
+		return ModeRequired, nil
+	}
+	return "", fmt.Errorf("auth: unknown mode %q", s)
+}
+
+// Verifier memeriksa signature request merchant.
+type Verifier struct {
+	Keys      KeyStore
+	Nonces    NonceCache
+	Mode      Mode
+	Tolerance time.Duration // selisih timestamp maksimum, default 5 menit
+	// Public: route yang tidak perlu signature (health, metrics, UI statis).
+	Public func(r *http.Request) bool
+}
+
+const maxSignedBody = 1 << 20
+
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v.Mode == ModeOff || (v.Public != nil && v.Public(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get(HeaderSignature) == "" && v.Mode == ModeOptional {
+			next.ServeHTTP(w, r)
+			return
+		}
+		merchant, reason := v.verify(r)
+		if reason != "" {
+			failures.WithLabelValues(reason).Inc()
+			w.Header().Set("WWW-Authenticate", `PGW-HMAC-SHA256 headers="X-Key-Id X-Timestamp X-Nonce X-Signature"`)
+			unauthenticated(w, r, "request signature rejected: "+reason, reason)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithMerchant(r.Context(), merchant)))
+	})
+}
+
+// verify mengembalikan merchant id, atau alasan penolakan.
+func (v *Verifier) verify(r *http.Request) (string, string) {
+	keyID := r.Header.Get(HeaderKeyID)
+	sig := r.Header.Get(HeaderSignature)
+	nonce := r.Header.Get(HeaderNonce)
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if keyID == "" || sig == "" || err != nil {
+		return "", "missing_signature"
+	}
+	if len(nonce) < 16 || len(nonce) > 128 {
+		return "", "bad_nonce"
+	}
+	tol := v.Tolerance
+	if tol <= 0 {
+		tol = 5 * time.Minute
+	}
+	if d := time.Since(time.Unix(ts, 0)); d > tol || d < -tol {
+		return "", "stale_timestamp"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	key, err := v.Keys.Get(ctx, keyID)
+	if errors.Is(err, ErrNotFound) {
+		return "", "unknown_key"
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "auth: get key failed", "key_id", keyID, "error", err)
+		return "", "key_store_unavailable"
+	}
+	if !key.Usable(time.Now()) {
+		return "", "key_revoked"
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+	if err != nil || len(body) > maxSignedBody {
+		return "", "body_too_large"
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	want := Signature(key.Secret, StringToSign(r.Method, r.URL.RequestURI(), ts, nonce, body))
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return "", "bad_signature"
+	}
+
+	// nonce dicatat setelah signature valid, supaya request palsu tidak
+	// bisa "menghabiskan" nonce milik merchant
+	seen, err := v.Nonces.Seen(ctx, keyID+":"+nonce, 2*tol)
+	if err != nil {
+		slog.ErrorContext(ctx, "auth: nonce store failed", "error", err)
+		return "", "nonce_store_unavailable"
+	}
+	if seen {
+		return "", "replayed_request"
+	}
+	return key.MerchantID, ""
+}
+
+// AdminOnly: Authorization: Bearer <ADMIN_TOKEN> untuk manajemen key.
+// Token kosong → route admin dimatikan.
+func AdminOnly(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			unauthenticated(w, r, "admin token required", "admin_token_required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MerchantOrAdmin: request merchant yang sudah diverifikasi Verifier, atau
+// Authorization: Bearer <ADMIN_TOKEN> (ops). Anonim → 401.
+func MerchantOrAdmin(token string, next http.Handler) http.Handler {
+	admin := AdminOnly(token, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := MerchantID(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") == "" {
+			unauthenticated(w, r, "merchant signature or admin token required", "authentication_required")
+			return
+		}
+		admin.ServeHTTP(w, r)
+	})
+}
+
+// unauthenticated: 401 problem+json UNAUTHENTICATED; reason (bad_signature,
+// admin_token_required, ...) di metadata.
+func unauthenticated(w http.ResponseWriter, r *http.Request, msg, reason string) {
+	perrors.Write(w, r, perrors.New(perrors.Unauthenticated, "%s", msg).With("reason", reason))
+}
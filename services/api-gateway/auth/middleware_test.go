@@ -0,0 +1,131 @@
+// services/api-gateway/auth/middleware_test.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+func newTestVerifier(t *testing.T) (*Verifier, *Key) {
+	t.Helper()
+	keys := NewMemoryKeys()
+	k, err := NewKey("m_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Create(context.Background(), k); err != nil {
+		t.Fatal(err)
+	}
+	return &Verifier{Keys: keys, Nonces: NewMemoryNonces(), Mode: ModeRequired}, k
+}
+
+// do mengirim req lewat middleware; merchant kosong bila handler tidak jalan.
+func do(v *Verifier, req *http.Request) (int, string) {
+	var merchant string
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		merchant, _ = MerchantID(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code, merchant
+}
+
+func signed(t *testing.T, k *Key, body string, now time.Time) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/payments?x=1", bytes.NewBufferString(body))
+	if err := SignRequest(req, k.ID, k.Secret, []byte(body), now); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestMiddlewareAcceptsSignedRequest(t *testing.T) {
+	v, k := newTestVerifier(t)
+	code, merchant := do(v, signed(t, k, `{"amount":1}`, time.Now()))
+	if code != http.StatusNoContent || merchant != "m_1" {
+		t.Fatalf("got %d merchant=%q", code, merchant)
+	}
+}
+
+func TestMiddlewareRejects(t *testing.T) {
+	v, k := newTestVerifier(t)
+
+	tampered := signed(t, k, `{"amount":1}`, time.Now())
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"amount":9}`)).Body
+
+	stale := signed(t, k, `{}`, time.Now().Add(-10*time.Minute))
+
+	unsigned := httptest.NewRequest(http.MethodPost, "/api/payments", nil)
+
+	for name, req := range map[string]*http.Request{
+		"tampered body": tampered,
+		"stale":         stale,
+		"unsigned":      unsigned,
+	} {
+		if code, _ := do(v, req); code != http.StatusUnauthorized {
+			t.Errorf("%s: got %d, want 401", name, code)
+		}
+	}
+}
+
+func TestRejectionIsProblemJSON(t *testing.T) {
+	v, _ := newTestVerifier(t)
+	rec := httptest.NewRecorder()
+	v.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/payments", nil))
+	var p perrors.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || rec.Code != http.StatusUnauthorized ||
+		p.Code != perrors.Unauthenticated || p.Metadata["reason"] != "missing_signature" {
+		t.Fatalf("%d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareRejectsReplay(t *testing.T) {
+	v, k := newTestVerifier(t)
+	first := signed(t, k, `{}`, time.Now())
+	replay := first.Clone(context.Background())
+	replay.Body = http.NoBody
+
+	if code, _ := do(v, first); code != http.StatusNoContent {
+		t.Fatalf("first: got %d", code)
+	}
+	if code, _ := do(v, replay); code != http.StatusUnauthorized {
+		t.Fatalf("replay: got %d, want 401", code)
+	}
+}
+
+func TestRotateKeepsOldKeyDuringGrace(t *testing.T) {
+	v, old := newTestVerifier(t)
+	ctx := context.Background()
+	nk, err := Rotate(ctx, v.Keys, "m_1", old.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []*Key{old, nk} {
+		if code, _ := do(v, signed(t, k, `{}`, time.Now())); code != http.StatusNoContent {
+			t.Fatalf("key %s: got %d", k.ID, code)
+		}
+	}
+	if err := v.Keys.Revoke(ctx, "m_1", old.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := do(v, signed(t, old, `{}`, time.Now())); code != http.StatusUnauthorized {
+		t.Fatalf("revoked key: got %d, want 401", code)
+	}
+}
+
+func TestOptionalModeAllowsAnonymous(t *testing.T) {
+	v, _ := newTestVerifier(t)
+	v.Mode = ModeOptional
+	code, merchant := do(v, httptest.NewRequest(http.MethodGet, "/api/payments/p1", nil))
+	if code != http.StatusNoContent || merchant != "" {
+		t.Fatalf("got %d merchant=%q", code, merchant)
+	}
+}
@@ -0,0 +1,44 @@
+// services/api-gateway/auth/nonce.go
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceCache mencatat nonce yang sudah dipakai selama window toleransi
+// timestamp; request yang diputar ulang dalam window itu ditolak.
+type NonceCache interface {
+	// Seen mengembalikan true bila key sudah pernah dicatat; bila belum,
+	// key dicatat sampai ttl.
+	Seen(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonces: NonceCache in-process (satu replica).
+type MemoryNonces struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryNonces() *MemoryNonces { return &MemoryNonces{seen: map[string]time.Time{}} }
+
+func (c *MemoryNonces) Seen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) > ttl {
+		for k, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	if exp, ok := c.seen[key]; ok && now.Before(exp) {
+		return true, nil
+	}
+	c.seen[key] = now.Add(ttl)
+	return false, nil
+}
@@ -0,0 +1,138 @@
+// services/api-gateway/auth/postgres.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresKeys: KeyStore bersama semua replica gateway.
+type PostgresKeys struct {
+	pool *pgxpool.Pool
+}
+
+var _ KeyStore = (*PostgresKeys)(nil)
+
+func NewPostgresKeys(ctx context.Context, pool *pgxpool.Pool) (*PostgresKeys, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &PostgresKeys{pool: pool}, nil
+}
+
+func (s *PostgresKeys) Create(ctx context.Context, k *Key) error {
+	return s.pool.QueryRow(ctx, `
+		INSERT INTO merchant_api_keys (key_id, merchant_id, secret) VALUES ($1, $2, $3)
+		RETURNING created_at
+	`, k.ID, k.MerchantID, k.Secret).Scan(&k.CreatedAt)
+}
+
+func (s *PostgresKeys) Get(ctx context.Context, id string) (*Key, error) {
+	var k Key
+	err := s.pool.QueryRow(ctx, `
+		SELECT key_id, merchant_id, secret, created_at, expires_at, revoked_at
+		FROM merchant_api_keys WHERE key_id = $1
+	`, id).Scan(&k.ID, &k.MerchantID, &k.Secret, &k.CreatedAt, &k.ExpiresAt, &k.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query key: %w", err)
+	}
+	return &k, nil
+}
+
+func (s *PostgresKeys) List(ctx context.Context, merchantID string) ([]Key, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT key_id, merchant_id, secret, created_at, expires_at, revoked_at
+		FROM merchant_api_keys WHERE merchant_id = $1 ORDER BY created_at
+	`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("query keys: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Key, error) {
+		var k Key
+		err := row.Scan(&k.ID, &k.MerchantID, &k.Secret, &k.CreatedAt, &k.ExpiresAt, &k.RevokedAt)
+		return k, err
+	})
+}
+
+func (s *PostgresKeys) Expire(ctx context.Context, merchantID, id string, at time.Time) error {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE merchant_api_keys SET expires_at = LEAST(COALESCE(expires_at, $3), $3)
+		WHERE key_id = $1 AND merchant_id = $2
+	`, id, merchantID, at)
+	if err != nil {
+		return fmt.Errorf("expire key: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresKeys) Revoke(ctx context.Context, merchantID, id string, at time.Time) error {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE merchant_api_keys SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE key_id = $1 AND merchant_id = $2
+	`, id, merchantID, at)
+	if err != nil {
+		return fmt.Errorf("revoke key: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PostgresNonces: NonceCache bersama, supaya replay ke replica lain juga
+// tertolak. Tabelnya dibuat oleh NewPostgresKeys.
+type PostgresNonces struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNonces(pool *pgxpool.Pool) *PostgresNonces { return &PostgresNonces{pool: pool} }
+
+func (c *PostgresNonces) Seen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	// nonce kedaluwarsa boleh dipakai lagi: timpa, bukan tolak
+	cmd, err := c.pool.Exec(ctx, `
+		INSERT INTO auth_nonces (nonce, expires_at)
+		VALUES ($1, now() + $2 * interval '1 millisecond')
+		ON CONFLICT (nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE auth_nonces.expires_at < now()
+	`, key, ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("record nonce: %w", err)
+	}
+	// bersih-bersih sesekali, cukup dari satu request di antara ratusan
+	if rand.IntN(200) == 0 {
+		_, _ = c.pool.Exec(ctx, `DELETE FROM auth_nonces WHERE expires_at < now()`)
+	}
+	return cmd.RowsAffected() == 0, nil
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS merchant_api_keys (
+  key_id      TEXT PRIMARY KEY,
+  merchant_id TEXT        NOT NULL,
+  secret      TEXT        NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at  TIMESTAMPTZ,
+  revoked_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS merchant_api_keys_merchant_idx ON merchant_api_keys (merchant_id);
+
+CREATE UNLOGGED TABLE IF NOT EXISTS auth_nonces (
+  nonce      TEXT PRIMARY KEY,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+`)
+	return err
+}
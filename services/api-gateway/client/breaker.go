@@ -0,0 +1,190 @@
+// services/api-gateway/client/breaker.go
+package clients
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "breaker_state",
+		Help:      "Circuit breaker per upstream service: 0 closed, 1 half-open, 2 open.",
+	}, []string{"service"})
+	breakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "breaker_transitions_total",
+		Help:      "Perpindahan state circuit breaker.",
+	}, []string{"service", "state"})
+	breakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "breaker_rejected_total",
+		Help:      "Call yang ditolak karena circuit open.",
+	}, []string{"service"})
+)
+
+type state int
+
+const (
+	closed state = iota
+	halfOpen
+	open
+)
+
+func (s state) String() string {
+	switch s {
+	case halfOpen:
+		return "half_open"
+	case open:
+		return "open"
+	}
+	return "closed"
+}
+
+// BreakerConfig: lihat config.Clients.
+type BreakerConfig struct {
+	ErrorRate   float64
+	MinRequests int
+	Window      time.Duration
+	Cooldown    time.Duration
+}
+
+const buckets = 10
+
+// breaker: satu per upstream service. Closed menghitung call dan error
+// dalam jendela geser (10 bucket); rasio error >= ErrorRate dari minimal
+// MinRequests call → open. Open menolak semua call selama Cooldown, lalu
+// half-open mengizinkan satu call percobaan: sukses → closed, gagal → open
+// lagi.
+type breaker struct {
+	service string
+	cfg     BreakerConfig
+	now     func() time.Time
+
+	mu       sync.Mutex
+	state    state
+	openedAt time.Time
+	probing  bool
+	counts   [buckets]struct {
+		at            int64 // nomor slot waktu bucket ini
+		total, failed int
+	}
+}
+
+func newBreaker(service string, cfg BreakerConfig, now func() time.Time) *breaker {
+	b := &breaker{service: service, cfg: cfg, now: now}
+	breakerState.WithLabelValues(service).Set(float64(closed))
+	return b
+}
+
+// allow: false = call ditolak tanpa menghubungi upstream.
+func (b *breaker) allow() bool {
+	if b.cfg.MinRequests <= 0 {
+		return true // breaker mati
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case open:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			breakerRejected.WithLabelValues(b.service).Inc()
+			return false
+		}
+		b.set(halfOpen)
+		fallthrough
+	case halfOpen:
+		if b.probing {
+			breakerRejected.WithLabelValues(b.service).Inc()
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record: hasil satu percobaan yang diizinkan allow.
+func (b *breaker) record(err error) {
+	if b.cfg.MinRequests <= 0 {
+		return
+	}
+	// Canceled: pemanggil menyerah (atau hedge yang kalah), bukan salah upstream.
+	canceled, failed := status.Code(err) == codes.Canceled, failure(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case halfOpen:
+		b.probing = false
+		if canceled {
+			return // percobaan berikutnya boleh jadi probe
+		}
+		if failed {
+			b.trip()
+		} else {
+			b.counts = [buckets]struct {
+				at            int64
+				total, failed int
+			}{}
+			b.set(closed)
+		}
+		return
+	case open:
+		return // call lama yang selesai setelah trip
+	}
+	if canceled {
+		return
+	}
+
+	slot := b.now().UnixNano() / int64(b.cfg.Window/buckets)
+	c := &b.counts[slot%buckets]
+	if c.at != slot {
+		c.at, c.total, c.failed = slot, 0, 0
+	}
+	c.total++
+	if failed {
+		c.failed++
+	}
+	var total, fails int
+	for _, c := range b.counts {
+		if slot-c.at < buckets {
+			total += c.total
+			fails += c.failed
+		}
+	}
+	if total >= b.cfg.MinRequests && float64(fails) >= b.cfg.ErrorRate*float64(total) {
+		b.trip()
+	}
+}
+
+func (b *breaker) trip() {
+	b.openedAt = b.now()
+	b.set(open)
+}
+
+func (b *breaker) set(s state) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	breakerState.WithLabelValues(b.service).Set(float64(s))
+	breakerTransitions.WithLabelValues(b.service, s.String()).Inc()
+}
+
+// failure: error yang menandakan upstream bermasalah. Error bisnis
+// (INSUFFICIENT_FUNDS, NotFound, validasi) tidak membuka circuit.
+func failure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown,
+		codes.ResourceExhausted, codes.DataLoss:
+		return true
+	}
+	return false
+}
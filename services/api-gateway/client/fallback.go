@@ -0,0 +1,92 @@
+// services/api-gateway/client/fallback.go
+package clients
+
+import (
+	"sync"
+	"time"
+
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	rv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
+)
+
+// fallback: jawaban pengganti saat circuit open atau upstream tidak
+// tersedia (Unavailable / deadline habis). observe melihat setiap jawaban
+// sukses; serve mengisi reply dan mengembalikan true bila bisa menjawab.
+type fallback interface {
+	observe(req, reply any)
+	serve(req, reply any) bool
+}
+
+// fallbacks: method → nama fallback (Policy.Fallback) → constructor; arg =
+// Policy.FallbackArg. Error bisnis (FX_RATE_STALE, RISK_DENIED, ...) tidak
+// pernah diganti fallback.
+var fallbacks = map[string]map[string]func(arg time.Duration, now func() time.Time) fallback{
+	fxv1.FxService_Convert_FullMethodName: {
+		// cache: kurs terakhir yang sukses per pasangan mata uang, maksimal
+		// berumur arg (default 5m).
+		"cache": func(arg time.Duration, now func() time.Time) fallback {
+			if arg <= 0 {
+				arg = 5 * time.Minute
+			}
+			return &rateCache{maxAge: arg, now: now, rates: map[[2]string]cachedRate{}}
+		},
+	},
+	rv1.RiskService_Evaluate_FullMethodName: {
+		// allow: terima pembayaran tanpa cek risk (fail-open); deny: tolak
+		// (fail-closed, sama seperti tanpa fallback tetapi sebagai RISK_DENIED).
+		"allow": func(time.Duration, func() time.Time) fallback {
+			return riskVerdict{allow: true, reason: "risk_fallback_allow"}
+		},
+		"deny": func(time.Duration, func() time.Time) fallback {
+			return riskVerdict{reason: "risk_unavailable"}
+		},
+	},
+}
+
+type cachedRate struct {
+	rate float64
+	at   time.Time
+}
+
+type rateCache struct {
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	rates map[[2]string]cachedRate
+}
+
+func (c *rateCache) observe(req, reply any) {
+	in, out := req.(*fxv1.ConvertRequest), reply.(*fxv1.ConvertResponse)
+	if in.GetAmount() == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.rates[[2]string{in.GetFromCurrency(), in.GetToCurrency()}] = cachedRate{out.GetAmount() / in.GetAmount(), c.now()}
+	c.mu.Unlock()
+}
+
+func (c *rateCache) serve(req, reply any) bool {
+	in, out := req.(*fxv1.ConvertRequest), reply.(*fxv1.ConvertResponse)
+	c.mu.Lock()
+	r, ok := c.rates[[2]string{in.GetFromCurrency(), in.GetToCurrency()}]
+	c.mu.Unlock()
+	if !ok || c.now().Sub(r.at) > c.maxAge {
+		return false
+	}
+	out.Amount = in.GetAmount() * r.rate
+	return true
+}
+
+type riskVerdict struct {
+	allow  bool
+	reason string
+}
+
+func (riskVerdict) observe(any, any) {}
+
+func (v riskVerdict) serve(_, reply any) bool {
+	out := reply.(*rv1.EvaluateResponse)
+	out.Allow, out.Reason = v.allow, v.reason
+	return true
+}
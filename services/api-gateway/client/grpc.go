@@ -4,11 +4,16 @@ package clients
 
 import (
     "fmt"
-    "os"
+    "time"
 
     "google.golang.org/grpc"
-    "google.golang.org/grpc/credentials/insecure"
 
+    "github.com/example/payment-gateway-poc/pkg/config"
+    "github.com/example/payment-gateway-poc/pkg/discovery"
+    "github.com/example/payment-gateway-poc/pkg/logging"
+    "github.com/example/payment-gateway-poc/pkg/mtls"
+    "github.com/example/payment-gateway-poc/pkg/tenant"
+    "github.com/example/payment-gateway-poc/pkg/tracing"
     fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
     wv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
     rv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
@@ -20,40 +25,74 @@ type GRPC struct {
     Wallet   wv1.WalletServiceClient
     Risk     rv1.RiskServiceClient
     Payments pv1.PaymentsServiceClient
+    // Conns: koneksi per nama lengkap service (wallet.v1.WalletService),
+    // dipakai transcoding REST /v1.
+    Conns    map[string]grpc.ClientConnInterface
     conns    []*grpc.ClientConn
+    certs    *mtls.Source // nil tanpa mTLS
 }
 
-func dial(addr string) (*grpc.ClientConn, error) {
-    return grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// dial: creds = mTLS bila tls.cert_file di-set, selain itu plaintext.
+// Interceptor resilience (timeout, retry, hedge, breaker, fallback per
+// method) paling luar, sehingga setiap percobaan membawa tenant (merchant)
+// sebagai metadata x-tenant-id, trace sebagai traceparent, correlation id /
+// idempotency key / payment id sebagai x-correlation-id dkk. (pkg/logging).
+//
+// addr boleh berisi beberapa replica (dns:///, static:///, srv:///,
+// file:///); load balancing dan health per endpoint dari pkg/discovery.
+func dial(addr string, creds grpc.DialOption, res *resilience, lb discovery.Options) (*grpc.ClientConn, error) {
+    opts, err := discovery.DialOptions(addr, lb)
+    if err != nil { return nil, err }
+    opts = append(opts, creds, tracing.DialOption(),
+        grpc.WithChainUnaryInterceptor(res.unary(), tenant.UnaryClientInterceptor(), logging.UnaryClientInterceptor()),
+        grpc.WithChainStreamInterceptor(logging.StreamClientInterceptor()))
+    return grpc.Dial(addr, opts...)
 }
 
-func NewGRPC() (*GRPC, error) {
-    fxAddr := getenv("FX_ADDR", "fx-grpc:9102")
-    wAddr  := getenv("WALLET_ADDR", "wallet-grpc:9103")
-    rAddr  := getenv("RISK_ADDR", "risk-grpc:9104")
-    pAddr  := getenv("PAYMENTS_ADDR", "payments-grpc:9096")
+// NewGRPC: target upstream dan balancer dari config.Upstreams (FX_ADDR,
+// WALLET_ADDR, RISK_ADDR, PAYMENTS_ADDR, GRPC_LB_POLICY), policy client dari
+// config.Clients.
+func NewGRPC(up config.Upstreams, tls config.TLS, cl config.Clients) (*GRPC, error) {
+    spec := cl.Policies
+    if spec == "" { spec = DefaultPolicies }
+    policies, err := ParsePolicies(spec)
+    if err != nil { return nil, fmt.Errorf("client policies: %w", err) }
+    res := newResilience(policies, BreakerConfig{
+        ErrorRate:   cl.BreakerErrorRate,
+        MinRequests: cl.BreakerMinRequests,
+        Window:      cl.BreakerWindow,
+        Cooldown:    cl.BreakerCooldown,
+    }, time.Now)
+
+    lb := discovery.Options{Balancer: up.Balancer, HealthCheck: up.HealthCheck, Refresh: up.Refresh}
+
+    creds, certs, err := mtls.DialOption(tls.MTLS())
+    if err != nil { return nil, fmt.Errorf("mtls: %w", err) }
 
     conns := make([]*grpc.ClientConn, 0, 4)
 
-    cfx, err := dial(fmt.Sprintf("%s", fxAddr)); if err != nil { return nil, err }; conns = append(conns, cfx)
-    cw , err := dial(fmt.Sprintf("%s", wAddr));  if err != nil { return nil, err }; conns = append(conns, cw)
-    cr , err := dial(fmt.Sprintf("%s", rAddr));  if err != nil { return nil, err }; conns = append(conns, cr)
-    cp , err := dial(fmt.Sprintf("%s", pAddr));  if err != nil { return nil, err }; conns = append(conns, cp)
+    cfx, err := dial(up.Fx, creds, res, lb);       if err != nil { return nil, err }; conns = append(conns, cfx)
+    cw , err := dial(up.Wallet, creds, res, lb);   if err != nil { return nil, err }; conns = append(conns, cw)
+    cr , err := dial(up.Risk, creds, res, lb);     if err != nil { return nil, err }; conns = append(conns, cr)
+    cp , err := dial(up.Payments, creds, res, lb); if err != nil { return nil, err }; conns = append(conns, cp)
 
     return &GRPC{
         Fx:       fxv1.NewFxServiceClient(cfx),
         Wallet:   wv1.NewWalletServiceClient(cw),
         Risk:     rv1.NewRiskServiceClient(cr),
         Payments: pv1.NewPaymentsServiceClient(cp),
+        Conns: map[string]grpc.ClientConnInterface{
+            fxv1.FxService_ServiceDesc.ServiceName:      cfx,
+            wv1.WalletService_ServiceDesc.ServiceName:   cw,
+            rv1.RiskService_ServiceDesc.ServiceName:     cr,
+            pv1.PaymentsService_ServiceDesc.ServiceName: cp,
+        },
         conns:    conns,
+        certs:    certs,
     }, nil
 }
 
 func (g *GRPC) Close() {
     for _, c := range g.conns { _ = c.Close() }
-}
-
-func getenv(k, d string) string {
-    if v := os.Getenv(k); v != "" { return v }
-    return d
+    if g.certs != nil { g.certs.Close() }
 }
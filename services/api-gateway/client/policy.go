@@ -0,0 +1,167 @@
+// services/api-gateway/client/policy.go
+package clients
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	rv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
+	wv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
+)
+
+// Policy: perilaku client untuk satu method gRPC upstream.
+type Policy struct {
+	// Method: "/fx.v1.FxService/Convert", "/fx.v1.FxService/*" atau "*".
+	Method string
+	// Timeout: budget seluruh call termasuk retry dan hedge; deadline
+	// pemanggil yang lebih pendek tetap berlaku. 0 = hanya deadline pemanggil.
+	Timeout time.Duration
+	// Attempt: batas satu percobaan; 0 = sisa budget.
+	Attempt time.Duration
+	// Retries: percobaan ulang setelah Unavailable / timeout percobaan,
+	// dengan backoff jitter. Hanya untuk method idempotent.
+	Retries int
+	// Hedge: bila percobaan belum selesai setelah Hedge, kirim satu lagi
+	// paralel dan pakai yang lebih dulu sukses. Hanya method idempotent.
+	Hedge time.Duration
+	// Fallback: saat circuit open atau upstream tidak tersedia; "" / "fail"
+	// = error UNAVAILABLE (lihat fallbacks).
+	Fallback string
+	// FallbackArg: argumen fallback, mis. umur maksimum kurs "cache:5m".
+	FallbackArg time.Duration
+}
+
+// idempotent: method yang aman dikirim lebih dari sekali (read, atau
+// evaluasi tanpa efek samping). Reserve/Capture/UpdateRates dan PaymentsService
+// tidak pernah di-retry atau di-hedge.
+var idempotent = map[string]bool{
+	fxv1.FxService_Convert_FullMethodName:              true,
+	wv1.WalletService_GetBalance_FullMethodName:        true,
+	wv1.WalletService_GetAccount_FullMethodName:        true,
+	wv1.WalletService_GetRandomAccounts_FullMethodName: true,
+	rv1.RiskService_Evaluate_FullMethodName:            true,
+}
+
+// DefaultPolicies: budget FX + wallet + risk (3 × 1s) di bawah timeout 5s
+// PaymentsHandler, sehingga upstream yang lambat gagal cepat. PaymentsService
+// (settlement sinkron) hanya memakai deadline pemanggil.
+const DefaultPolicies = "/fx.v1.FxService/Convert timeout=1s attempt=400ms retries=2 hedge=200ms;" +
+	"/risk.v1.RiskService/Evaluate timeout=1s attempt=400ms retries=2 hedge=200ms;" +
+	"/wallet.v1.WalletService/* timeout=1s attempt=400ms retries=2"
+
+// ParsePolicies: policy dipisah ";", mis.
+//
+//	/fx.v1.FxService/Convert timeout=800ms attempt=300ms retries=2 hedge=150ms fallback=cache:5m;
+//	/risk.v1.RiskService/* timeout=1s fallback=deny;
+//	* timeout=2s
+//
+// Method paling spesifik menang: method lengkap, lalu "/service/*", lalu
+// "*". retries/hedge pada method lengkap yang tidak idempotent ditolak; pada
+// wildcard diabaikan untuk method seperti itu.
+func ParsePolicies(spec string) ([]Policy, error) {
+	var out []Policy
+	seen := map[string]bool{}
+	for _, raw := range strings.Split(spec, ";") {
+		f := strings.Fields(raw)
+		if len(f) == 0 {
+			continue
+		}
+		p := Policy{Method: f[0]}
+		if p.Method != "*" && (!strings.HasPrefix(p.Method, "/") || strings.Count(p.Method, "/") != 2) {
+			return nil, fmt.Errorf("policy %q: method must be /package.Service/Method, /package.Service/* or *", raw)
+		}
+		if seen[p.Method] {
+			return nil, fmt.Errorf("policy %q: duplicate method", p.Method)
+		}
+		seen[p.Method] = true
+		for _, kv := range f[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("policy %s: %q is not key=value", p.Method, kv)
+			}
+			var err error
+			switch k {
+			case "timeout":
+				p.Timeout, err = parseDuration(v)
+			case "attempt":
+				p.Attempt, err = parseDuration(v)
+			case "hedge":
+				p.Hedge, err = parseDuration(v)
+			case "retries":
+				p.Retries, err = strconv.Atoi(v)
+				if err == nil && (p.Retries < 0 || p.Retries > 5) {
+					err = fmt.Errorf("must be 0..5")
+				}
+			case "fallback":
+				name, arg, hasArg := strings.Cut(v, ":")
+				p.Fallback = name
+				if hasArg {
+					p.FallbackArg, err = parseDuration(arg)
+				}
+			default:
+				err = fmt.Errorf("unknown key")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: %s: %w", p.Method, k, err)
+			}
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("policy %s: %w", p.Method, err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("must be >= 0")
+	}
+	return d, err
+}
+
+func (p Policy) validate() error {
+	exact := p.Method != "*" && !strings.HasSuffix(p.Method, "/*")
+	if exact && !idempotent[p.Method] && (p.Retries > 0 || p.Hedge > 0) {
+		return fmt.Errorf("retries/hedge need an idempotent method")
+	}
+	if p.Timeout > 0 && p.Attempt > p.Timeout {
+		return fmt.Errorf("attempt must be <= timeout")
+	}
+	if p.Fallback == "" || p.Fallback == "fail" {
+		return nil
+	}
+	if !exact || fallbacks[p.Method][p.Fallback] == nil {
+		return fmt.Errorf("fallback %q not available for %s", p.Fallback, p.Method)
+	}
+	return nil
+}
+
+// policyFor: policy paling spesifik untuk method; tanpa yang cocok = Policy
+// kosong (hanya deadline pemanggil).
+func policyFor(ps []Policy, method string) Policy {
+	svc := method[:strings.LastIndex(method, "/")+1] + "*"
+	best, rank := Policy{Method: method}, 0
+	for _, p := range ps {
+		r := 0
+		switch p.Method {
+		case method:
+			r = 3
+		case svc:
+			r = 2
+		case "*":
+			r = 1
+		}
+		if r > rank {
+			best, rank = p, r
+		}
+	}
+	if !idempotent[method] {
+		best.Retries, best.Hedge = 0, 0
+	}
+	return best
+}
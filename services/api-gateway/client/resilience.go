@@ -0,0 +1,238 @@
+// services/api-gateway/client/resilience.go
+package clients
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+var (
+	retriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "retries_total",
+		Help:      "Percobaan ulang call gRPC upstream.",
+	}, []string{"method"})
+	hedgesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "hedges_total",
+		Help:      "Hedged request yang dikirim.",
+	}, []string{"method"})
+	fallbacksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "client",
+		Name:      "fallbacks_total",
+		Help:      "Call yang dijawab fallback karena upstream tidak tersedia.",
+	}, []string{"method", "fallback"})
+)
+
+const (
+	backoffBase = 50 * time.Millisecond
+	backoffMax  = time.Second
+)
+
+// resilience: policy per method (Policy) + breaker per upstream service,
+// dipasang sebagai interceptor unary paling luar sehingga setiap percobaan
+// tetap lewat interceptor tenant/logging/tracing.
+type resilience struct {
+	policies []Policy
+	breaker  BreakerConfig
+	now      func() time.Time
+
+	mu       sync.Mutex
+	methods  map[string]*methodState
+	breakers map[string]*breaker
+}
+
+type methodState struct {
+	policy   Policy
+	breaker  *breaker
+	fallback fallback // nil = tanpa fallback
+}
+
+func newResilience(ps []Policy, cfg BreakerConfig, now func() time.Time) *resilience {
+	return &resilience{
+		policies: ps, breaker: cfg, now: now,
+		methods: map[string]*methodState{}, breakers: map[string]*breaker{},
+	}
+}
+
+func (r *resilience) method(name string) *methodState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.methods[name]; ok {
+		return m
+	}
+	// "/fx.v1.FxService/Convert" → breaker "fx.v1.FxService"
+	svc := strings.TrimPrefix(name[:strings.LastIndex(name, "/")], "/")
+	b, ok := r.breakers[svc]
+	if !ok {
+		b = newBreaker(svc, r.breaker, r.now)
+		r.breakers[svc] = b
+	}
+	m := &methodState{policy: policyFor(r.policies, name), breaker: b}
+	if mk := fallbacks[name][m.policy.Fallback]; mk != nil {
+		m.fallback = mk(m.policy.FallbackArg, r.now)
+	}
+	r.methods[name] = m
+	return m
+}
+
+func (r *resilience) unary() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		m := r.method(method)
+		callCtx := ctx
+		if m.policy.Timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(ctx, m.policy.Timeout)
+			defer cancel()
+		}
+
+		var err error
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				retriesTotal.WithLabelValues(method).Inc()
+				if !sleep(callCtx, backoff(attempt)) {
+					break // budget habis; err = kegagalan terakhir
+				}
+			}
+			if !m.breaker.allow() {
+				err = perrors.New(perrors.Unavailable, "%s: circuit open", m.breaker.service)
+				break
+			}
+			if err = r.attempt(callCtx, m, method, req, reply, cc, invoker, opts); err == nil {
+				if m.fallback != nil {
+					m.fallback.observe(req, reply)
+				}
+				return nil
+			}
+			if attempt >= m.policy.Retries || !retryable(callCtx, err) {
+				break
+			}
+		}
+
+		// ctx pemanggil masih hidup: upstream yang tidak tersedia, bukan
+		// pemanggil yang menyerah.
+		if m.fallback != nil && ctx.Err() == nil && unavailable(err) && m.fallback.serve(req, reply) {
+			fallbacksTotal.WithLabelValues(method, m.policy.Fallback).Inc()
+			slog.WarnContext(ctx, "grpc client: fallback", "method", method, "fallback", m.policy.Fallback, "error", err)
+			return nil
+		}
+		return err
+	}
+}
+
+// attempt: satu percobaan dengan batas Policy.Attempt; dengan Policy.Hedge
+// satu salinan dikirim bila belum ada jawaban setelah Hedge, jawaban sukses
+// pertama menang dan yang lain dibatalkan.
+func (r *resilience) attempt(ctx context.Context, m *methodState, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts []grpc.CallOption) error {
+	if m.policy.Attempt > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.policy.Attempt)
+		defer cancel()
+	}
+	msg, ok := reply.(proto.Message)
+	if m.policy.Hedge <= 0 || !ok {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.breaker.record(err)
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	results := make(chan result, 2)
+	launch := func() {
+		out := proto.Clone(msg)
+		go func() {
+			err := invoker(ctx, method, req, out, cc, opts...)
+			m.breaker.record(err)
+			results <- result{out, err}
+		}()
+	}
+
+	launch()
+	inflight := 1
+	hedge := time.NewTimer(m.policy.Hedge)
+	defer hedge.Stop()
+	hedgeC := hedge.C
+	for {
+		select {
+		case <-hedgeC:
+			hedgeC = nil
+			if m.breaker.allow() {
+				hedgesTotal.WithLabelValues(method).Inc()
+				launch()
+				inflight++
+			}
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				proto.Reset(msg)
+				proto.Merge(msg, res.reply)
+				return nil
+			}
+			if inflight == 0 {
+				return res.err
+			}
+		}
+	}
+}
+
+// retryable: Unavailable, atau batas percobaan habis sementara budget
+// seluruh call masih ada.
+func retryable(ctx context.Context, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return true
+	case codes.DeadlineExceeded:
+		return ctx.Err() == nil
+	}
+	return false
+}
+
+// unavailable: kegagalan yang boleh diganti fallback (termasuk circuit open).
+func unavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// backoff: full jitter, acak di [0, min(backoffMax, backoffBase·2^(n-1))].
+func backoff(n int) time.Duration {
+	d := backoffBase << (n - 1)
+	if d > backoffMax || d <= 0 {
+		d = backoffMax
+	}
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
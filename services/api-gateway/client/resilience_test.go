@@ -0,0 +1,186 @@
+// services/api-gateway/client/resilience_test.go
+package clients
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	rv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
+	wv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
+)
+
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time      { c.mu.Lock(); defer c.mu.Unlock(); return c.t }
+func (c *clock) add(d time.Duration) { c.mu.Lock(); c.t = c.t.Add(d); c.mu.Unlock() }
+
+func setup(t *testing.T, spec string, cfg BreakerConfig) (grpc.UnaryClientInterceptor, *clock) {
+	t.Helper()
+	ps, err := ParsePolicies(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &clock{t: time.Unix(1700000000, 0)}
+	return newResilience(ps, cfg, c.now).unary(), c
+}
+
+// invoker: jawaban berurutan per call; call melebihi daftar memakai yang terakhir.
+func invoker(calls *atomic.Int32, answers ...func(ctx context.Context, reply any) error) grpc.UnaryInvoker {
+	return func(ctx context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		n := int(calls.Add(1)) - 1
+		return answers[min(n, len(answers)-1)](ctx, reply)
+	}
+}
+
+func fail(c codes.Code) func(context.Context, any) error {
+	return func(context.Context, any) error { return status.Error(c, "upstream") }
+}
+
+func ok(context.Context, any) error { return nil }
+
+func TestParsePolicies(t *testing.T) {
+	if _, err := ParsePolicies(DefaultPolicies); err != nil {
+		t.Fatalf("DefaultPolicies: %v", err)
+	}
+	ps, err := ParsePolicies("/fx.v1.FxService/Convert timeout=800ms retries=2 fallback=cache:1m; * timeout=2s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := policyFor(ps, fxv1.FxService_Convert_FullMethodName); p.Retries != 2 || p.FallbackArg != time.Minute {
+		t.Errorf("convert policy %+v", p)
+	}
+	if p := policyFor(ps, wv1.WalletService_Reserve_FullMethodName); p.Timeout != 2*time.Second {
+		t.Errorf("wildcard policy %+v", p)
+	}
+	for _, spec := range []string{
+		"/wallet.v1.WalletService/Reserve retries=1",
+		"/fx.v1.FxService/Convert timeout=1s attempt=2s",
+		"/fx.v1.FxService/Convert fallback=allow",
+		"/risk.v1.RiskService/* fallback=deny",
+		"/fx.v1.FxService/Convert bogus=1",
+		"fx.v1.FxService timeout=1s",
+		"* timeout=1s; * timeout=2s",
+	} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("ParsePolicies(%q): want error", spec)
+		}
+	}
+}
+
+// Unavailable di-retry hanya untuk method idempotent.
+func TestRetryIdempotentOnly(t *testing.T) {
+	call, _ := setup(t, "* timeout=2s retries=2", BreakerConfig{})
+
+	var calls atomic.Int32
+	err := call(context.Background(), wv1.WalletService_GetBalance_FullMethodName, &wv1.GetBalanceRequest{}, &wv1.GetBalanceResponse{}, nil,
+		invoker(&calls, fail(codes.Unavailable), fail(codes.Unavailable), ok))
+	if err != nil || calls.Load() != 3 {
+		t.Fatalf("GetBalance: err %v after %d calls", err, calls.Load())
+	}
+
+	calls.Store(0)
+	err = call(context.Background(), wv1.WalletService_Reserve_FullMethodName, &wv1.ReserveRequest{}, &wv1.ReserveResponse{}, nil,
+		invoker(&calls, fail(codes.Unavailable), ok))
+	if status.Code(err) != codes.Unavailable || calls.Load() != 1 {
+		t.Fatalf("Reserve: err %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	call, clk := setup(t, "", BreakerConfig{ErrorRate: 0.5, MinRequests: 4, Window: 10 * time.Second, Cooldown: 5 * time.Second})
+	method := rv1.RiskService_Evaluate_FullMethodName
+	evaluate := func(answer func(context.Context, any) error) (error, int32) {
+		var calls atomic.Int32
+		err := call(context.Background(), method, &rv1.ScoreRequest{}, &rv1.EvaluateResponse{}, nil, invoker(&calls, answer))
+		return err, calls.Load()
+	}
+
+	// error bisnis tidak dihitung sebagai kegagalan upstream
+	for i := 0; i < 4; i++ {
+		evaluate(fail(codes.PermissionDenied))
+	}
+	for i := 0; i < 4; i++ {
+		evaluate(fail(codes.Unavailable))
+	}
+	err, n := evaluate(ok)
+	if n != 0 || perrors.CodeOf(err) != perrors.Unavailable {
+		t.Fatalf("open breaker: err %v, %d calls", err, n)
+	}
+
+	clk.add(5 * time.Second) // half-open: satu probe; gagal → open lagi
+	if _, n := evaluate(fail(codes.Unavailable)); n != 1 {
+		t.Fatalf("half-open probe: %d calls", n)
+	}
+	if _, n := evaluate(ok); n != 0 {
+		t.Fatal("breaker closed after failed probe")
+	}
+
+	clk.add(5 * time.Second) // probe sukses → closed
+	for i := 0; i < 3; i++ {
+		if err, n := evaluate(ok); err != nil || n != 1 {
+			t.Fatalf("after recovery: err %v, %d calls", err, n)
+		}
+	}
+}
+
+// Percobaan pertama menggantung; hedge menjawab lebih dulu.
+func TestHedge(t *testing.T) {
+	call, _ := setup(t, "/fx.v1.FxService/Convert timeout=2s hedge=20ms", BreakerConfig{})
+
+	var calls atomic.Int32
+	hang := func(ctx context.Context, _ any) error { <-ctx.Done(); return status.FromContextError(ctx.Err()).Err() }
+	answer := func(_ context.Context, reply any) error { reply.(*fxv1.ConvertResponse).Amount = 42; return nil }
+	out := &fxv1.ConvertResponse{}
+	start := time.Now()
+	err := call(context.Background(), fxv1.FxService_Convert_FullMethodName, &fxv1.ConvertRequest{}, out, nil, invoker(&calls, hang, answer))
+	if err != nil || out.Amount != 42 || calls.Load() != 2 {
+		t.Fatalf("err %v, amount %v, %d calls", err, out.Amount, calls.Load())
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("hedge took %v", d)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	call, clk := setup(t, "/fx.v1.FxService/Convert fallback=cache:1m; /risk.v1.RiskService/Evaluate fallback=deny", BreakerConfig{})
+	convert := func(amount float64, answer func(context.Context, any) error) (float64, error) {
+		var calls atomic.Int32
+		out := &fxv1.ConvertResponse{}
+		err := call(context.Background(), fxv1.FxService_Convert_FullMethodName,
+			&fxv1.ConvertRequest{FromCurrency: "USD", ToCurrency: "IDR", Amount: amount}, out, nil, invoker(&calls, answer))
+		return out.Amount, err
+	}
+
+	if _, err := convert(2, func(_ context.Context, reply any) error { reply.(*fxv1.ConvertResponse).Amount = 32000; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := convert(3, fail(codes.Unavailable)); err != nil || got != 48000 {
+		t.Fatalf("cached rate: %v, %v", got, err)
+	}
+	if _, err := convert(3, fail(codes.FailedPrecondition)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("business error replaced by fallback: %v", err)
+	}
+	clk.add(2 * time.Minute)
+	if _, err := convert(3, fail(codes.Unavailable)); status.Code(err) != codes.Unavailable {
+		t.Fatalf("stale cache served: %v", err)
+	}
+
+	var calls atomic.Int32
+	out := &rv1.EvaluateResponse{}
+	err := call(context.Background(), rv1.RiskService_Evaluate_FullMethodName, &rv1.ScoreRequest{}, out, nil, invoker(&calls, fail(codes.Unavailable)))
+	if err != nil || out.Allow || out.Reason != "risk_unavailable" {
+		t.Fatalf("risk deny fallback: %v, %+v", err, out)
+	}
+}
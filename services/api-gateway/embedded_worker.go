@@ -0,0 +1,38 @@
+// services/api-gateway/embedded_worker.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+)
+
+// startEmbeddedWorker: settlement worker di dalam gateway, dipakai bersama
+// QUEUE_DRIVER=memory (dev lokal tanpa Kafka). Tetap butuh Postgres.
+func startEmbeddedWorker(bus queue.Bus, dsn string) error {
+	db, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		return fmt.Errorf("connect db: %w", err)
+	}
+
+	store := worker.NewStore(db)
+	if err := store.EnsureSchema(context.Background()); err != nil {
+		return err
+	}
+	pool := worker.NewPool(worker.Config{Concurrency: 4, CommitInterval: time.Second},
+		worker.SettleHandler(store, bus))
+	go func() {
+		defer db.Close()
+		if err := pool.Run(context.Background(), bus.Subscribe("payments-worker")); err != nil {
+			slog.Error("embedded worker stopped", "error", err)
+		}
+	}()
+	slog.Info("embedded payments worker started", "queue_driver", "memory")
+	return nil
+}
@@ -8,12 +8,19 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
 	pb "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
 	"google.golang.org/grpc"
 )
 
-func updateRates() {
-	conn, err := grpc.Dial("fx-grpc:9102", grpc.WithInsecure())
+func updateRates(cfg config.Gateway) {
+	creds, _, err := mtls.DialOption(cfg.TLS.MTLS())
+	if err != nil {
+		log.Fatalf("mtls: %v", err)
+	}
+	conn, err := grpc.Dial(cfg.Upstreams.Fx, creds, tracing.DialOption())
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
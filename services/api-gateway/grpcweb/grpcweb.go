@@ -0,0 +1,303 @@
+// services/api-gateway/grpcweb/grpcweb.go
+// Package grpcweb: REST, gRPC native (HTTP/2, termasuk h2c tanpa TLS) dan
+// gRPC-Web (HTTP/1.1 dari browser) di satu listener gateway.
+//
+// gRPC dilayani grpc.Server.ServeHTTP, jadi request gRPC melewati middleware
+// HTTP yang sama dengan REST (signature merchant, rate limit). Error dari
+// middleware itu diubah menjadi status gRPC oleh Status.
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+const (
+	contentTypeWeb     = "application/grpc-web"
+	contentTypeWebText = "application/grpc-web-text"
+)
+
+// IsGRPC: request gRPC native (HTTP/2, application/grpc[+proto]).
+func IsGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") && !IsGRPCWeb(r)
+}
+
+// IsGRPCWeb: request gRPC-Web, biner atau base64 (-text).
+func IsGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), contentTypeWeb)
+}
+
+// Mux: gRPC dan gRPC-Web ke grpcHandler, selain itu ke rest. Dibungkus h2c
+// supaya client gRPC bisa bicara HTTP/2 tanpa TLS ke port yang sama.
+func Mux(rest, grpcHandler http.Handler) http.Handler {
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsGRPC(r) || IsGRPCWeb(r) {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		rest.ServeHTTP(w, r)
+	}), &http2.Server{})
+}
+
+// Wrap: request gRPC-Web diterjemahkan ke gRPC untuk srv (biasanya
+// *grpc.Server); request gRPC native diteruskan apa adanya.
+func Wrap(srv http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsGRPCWeb(r) {
+			srv.ServeHTTP(w, r)
+			return
+		}
+		ct := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(ct, contentTypeWebText)
+		subtype := "+proto"
+		if i := strings.IndexByte(ct, '+'); i >= 0 {
+			subtype = ct[i:]
+		}
+
+		req := r.Clone(r.Context())
+		req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2.0"
+		req.Header.Set("Content-Type", "application/grpc"+subtype)
+		req.Header.Set("Te", "trailers")
+		req.Header.Del("Content-Length")
+		req.ContentLength = -1
+		if text {
+			req.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+		}
+		out := contentTypeWeb
+		if text {
+			out = contentTypeWebText
+		}
+		ww := &webWriter{w: w, h: http.Header{}, contentType: out + subtype, text: text}
+		srv.ServeHTTP(ww, req)
+		ww.finish()
+	})
+}
+
+// webWriter: response gRPC (trailer HTTP/2) → gRPC-Web (trailer sebagai
+// frame terakhir di body, flag 0x80).
+type webWriter struct {
+	w           http.ResponseWriter
+	h           http.Header // header yang dilihat grpc.Server
+	contentType string
+	text        bool
+	wroteHeader bool
+	pending     bytes.Buffer // -text: di-encode per Flush
+}
+
+func (ww *webWriter) Header() http.Header { return ww.h }
+
+func (ww *webWriter) WriteHeader(code int) {
+	if ww.wroteHeader {
+		return
+	}
+	ww.wroteHeader = true
+	dst := ww.w.Header()
+	for k, v := range ww.h {
+		if k == "Trailer" || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		dst[k] = v
+	}
+	if code == http.StatusOK {
+		dst.Set("Content-Type", ww.contentType)
+	}
+	dst.Del("Content-Length")
+	ww.w.WriteHeader(code)
+}
+
+func (ww *webWriter) Write(b []byte) (int, error) {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.text {
+		return ww.pending.Write(b)
+	}
+	return ww.w.Write(b)
+}
+
+// Flush: grpc.Server flush setiap message. Untuk -text setiap flush menjadi
+// satu chunk base64 berpadding (spesifikasi gRPC-Web mengizinkannya).
+func (ww *webWriter) Flush() {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.pending.Len() > 0 {
+		_, _ = io.WriteString(ww.w, base64.StdEncoding.EncodeToString(ww.pending.Bytes()))
+		ww.pending.Reset()
+	}
+	_ = http.NewResponseController(ww.w).Flush()
+}
+
+// finish menulis trailer (grpc-status, grpc-message, metadata trailer).
+func (ww *webWriter) finish() {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK) // trailers-only: status ada di header
+		return
+	}
+	var block bytes.Buffer
+	for k, vv := range ww.h {
+		name, ok := strings.CutPrefix(k, http.TrailerPrefix)
+		if !ok && !declared(ww.h, k) {
+			continue
+		}
+		for _, v := range vv {
+			fmt.Fprintf(&block, "%s: %s\r\n", strings.ToLower(name), v)
+		}
+	}
+	frame := make([]byte, 5, 5+block.Len())
+	frame[0] = 0x80
+	binary.BigEndian.PutUint32(frame[1:], uint32(block.Len()))
+	_, _ = ww.Write(append(frame, block.Bytes()...))
+	ww.Flush()
+}
+
+func declared(h http.Header, key string) bool {
+	for _, v := range h.Values("Trailer") {
+		for _, k := range strings.Split(v, ",") {
+			if http.CanonicalHeaderKey(strings.TrimSpace(k)) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Status: response error HTTP dari middleware di depan grpc.Server (401
+// signature, 429 rate limit, ...) diubah jadi response gRPC trailers-only
+// dengan kode yang setara, supaya client gRPC/gRPC-Web melihat status
+// Unauthenticated/ResourceExhausted alih-alih error transport.
+func Status(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, contentType: r.Header.Get("Content-Type")}
+		next.ServeHTTP(sw, r)
+		sw.finish()
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	contentType string
+	code        int // status HTTP error yang ditahan; 0 = diteruskan
+	passed      bool
+	body        bytes.Buffer
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.passed || sw.code != 0 {
+		return
+	}
+	if code < http.StatusBadRequest {
+		sw.passed = true
+		sw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	sw.code = code
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if !sw.passed && sw.code == 0 {
+		sw.WriteHeader(http.StatusOK)
+	}
+	if sw.code != 0 {
+		if sw.body.Len() < 4096 {
+			sw.body.Write(b)
+		}
+		return len(b), nil
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *statusWriter) Flush() {
+	if sw.code == 0 {
+		_ = http.NewResponseController(sw.ResponseWriter).Flush()
+	}
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter { return sw.ResponseWriter }
+
+func (sw *statusWriter) finish() {
+	if sw.code == 0 {
+		return
+	}
+	// body error gateway: problem+json dengan kode katalog (pkg/errors)
+	code, msg := Code(sw.code), http.StatusText(sw.code)
+	var e perrors.Problem
+	var details string
+	switch {
+	case json.Unmarshal(sw.body.Bytes(), &e) != nil:
+		if s := strings.TrimSpace(sw.body.String()); s != "" {
+			msg = s
+		}
+	case perrors.Known(e.Code):
+		st := perrors.E{Code: e.Code, Message: e.Detail, Metadata: e.Metadata}.GRPCStatus()
+		code, msg = st.Code(), e.Detail
+		if msg == "" {
+			msg = e.Code
+		}
+		if b, err := proto.Marshal(st.Proto()); err == nil {
+			details = base64.RawStdEncoding.EncodeToString(b)
+		}
+	}
+	h := sw.ResponseWriter.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", sw.contentType)
+	h.Set("Grpc-Status", strconv.Itoa(int(code)))
+	h.Set("Grpc-Message", encodeMessage(msg))
+	if details != "" {
+		h.Set("Grpc-Status-Details-Bin", details)
+	}
+	sw.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+// Code: status HTTP → kode gRPC (kebalikan transcode.HTTPStatus).
+func Code(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Unknown
+}
+
+// encodeMessage: percent-encoding grpc-message (spesifikasi gRPC over HTTP/2).
+func encodeMessage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,188 @@
+// services/api-gateway/grpcweb/grpcweb_test.go
+package grpcweb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/grpcweb"
+)
+
+// newServer: REST "/rest" dan grpc.health.v1 di listener yang sama; header
+// X-Deny mensimulasikan middleware auth yang menolak request.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gs := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("payments", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(gs, hs)
+
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Deny") != "" {
+				perrors.Write(w, r, perrors.New(perrors.Unauthenticated, "missing_signature"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	rest := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "rest "+r.URL.Path)
+	})
+	srv := httptest.NewServer(grpcweb.Mux(rest, grpcweb.Status(deny(grpcweb.Wrap(gs)))))
+	t.Cleanup(srv.Close)
+	t.Cleanup(gs.Stop)
+	return srv
+}
+
+func TestNativeGRPC(t *testing.T) {
+	srv := newServer(t)
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "payments"})
+	if err != nil || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("check: %v %v", res, err)
+	}
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "nope"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown service: %v", err)
+	}
+
+	// error dari middleware HTTP → status gRPC
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-deny", "1")
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "payments"})
+	if st := status.Convert(err); st.Code() != codes.Unauthenticated || st.Message() != "missing_signature" {
+		t.Fatalf("denied: %v", err)
+	}
+
+	resp, err := http.Get(srv.URL + "/rest/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "rest /rest/x" {
+		t.Fatalf("rest: %q", b)
+	}
+}
+
+func TestGRPCWeb(t *testing.T) {
+	srv := newServer(t)
+	for _, text := range []bool{false, true} {
+		ct := "application/grpc-web+proto"
+		if text {
+			ct = "application/grpc-web-text"
+		}
+		t.Run(ct, func(t *testing.T) {
+			msg, _ := proto.Marshal(&healthpb.HealthCheckRequest{Service: "payments"})
+			body := append([]byte{0, 0, 0, 0, 0}, msg...)
+			binary.BigEndian.PutUint32(body[1:5], uint32(len(msg)))
+			if text {
+				body = []byte(base64.StdEncoding.EncodeToString(body))
+			}
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/grpc.health.v1.Health/Check", bytes.NewReader(body))
+			req.Header.Set("Content-Type", ct)
+			req.Header.Set("X-Grpc-Web", "1")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			raw, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || resp.ProtoMajor != 1 || !strings.HasPrefix(resp.Header.Get("Content-Type"), ct) {
+				t.Fatalf("status %d proto %s content-type %q", resp.StatusCode, resp.Proto, resp.Header.Get("Content-Type"))
+			}
+			if text {
+				raw = decodeChunks(t, raw)
+			}
+			frames := parseFrames(t, raw)
+			if len(frames) != 2 || frames[0].flag != 0 || frames[1].flag != 0x80 {
+				t.Fatalf("frames %+v", frames)
+			}
+			var res healthpb.HealthCheckResponse
+			if err := proto.Unmarshal(frames[0].data, &res); err != nil || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				t.Fatalf("message: %v %v", &res, err)
+			}
+			if !strings.Contains(string(frames[1].data), "grpc-status: 0\r\n") {
+				t.Fatalf("trailers %q", frames[1].data)
+			}
+		})
+	}
+
+	// ditolak middleware: trailers-only, status di header
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/grpc.health.v1.Health/Check", bytes.NewReader([]byte{0, 0, 0, 0, 0}))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("X-Deny", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Grpc-Status") != "16" || resp.Header.Get("Grpc-Message") != "missing_signature" {
+		t.Fatalf("denied: %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+type frame struct {
+	flag byte
+	data []byte
+}
+
+func parseFrames(t *testing.T, b []byte) []frame {
+	t.Helper()
+	var out []frame
+	for len(b) > 0 {
+		if len(b) < 5 {
+			t.Fatalf("short frame %q", b)
+		}
+		n := binary.BigEndian.Uint32(b[1:5])
+		out = append(out, frame{flag: b[0], data: b[5 : 5+n]})
+		b = b[5+n:]
+	}
+	return out
+}
+
+// decodeChunks: -text boleh berisi beberapa chunk base64 berpadding.
+func decodeChunks(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var out []byte
+	for len(b) > 0 {
+		// chunk berakhir setelah padding atau di akhir body
+		end := len(b)
+		if i := bytes.IndexByte(b, '='); i >= 0 {
+			end = i
+			for end < len(b) && b[end] == '=' {
+				end++
+			}
+		}
+		dec, err := base64.StdEncoding.DecodeString(string(b[:end]))
+		if err != nil {
+			t.Fatalf("base64 %q: %v", b[:end], err)
+		}
+		out = append(out, dec...)
+		b = b[end:]
+	}
+	return out
+}
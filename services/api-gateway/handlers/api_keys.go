@@ -0,0 +1,127 @@
+// services/api-gateway/handlers/api_keys.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+)
+
+// APIKeyHandlers: create/list/rotate/revoke API key merchant, di bawah
+// /api/admin/merchants/{merchant_id}/keys dan hanya dengan admin token.
+type APIKeyHandlers struct {
+	Keys        auth.KeyStore
+	Merchants   merchant.Store // opsional: key hanya untuk merchant terdaftar
+	AdminToken  string
+	RotateGrace time.Duration // masa berlaku key lama setelah rotate, default 24h
+}
+
+func (h APIKeyHandlers) Register(r *mux.Router) {
+	s := r.PathPrefix("/api/admin/merchants/{merchant_id}/keys").Subrouter()
+	s.Use(func(next http.Handler) http.Handler { return auth.AdminOnly(h.AdminToken, next) })
+	s.HandleFunc("", h.create).Methods(http.MethodPost)
+	s.HandleFunc("", h.list).Methods(http.MethodGet)
+	s.HandleFunc("/{key_id}/rotate", h.rotate).Methods(http.MethodPost)
+	s.HandleFunc("/{key_id}", h.revoke).Methods(http.MethodDelete)
+}
+
+func (h APIKeyHandlers) create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	id := mux.Vars(r)["merchant_id"]
+	if h.Merchants != nil {
+		if _, err := h.Merchants.Get(ctx, id); errors.Is(err, merchant.ErrNotFound) {
+			perrors.Write(w, r, perrors.New(perrors.NotFound, "merchant %s not found", id).With("merchant_id", id))
+			return
+		} else if err != nil {
+			perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+			return
+		}
+	}
+	k, err := auth.NewKey(id)
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Internal, "generate API key", err))
+		return
+	}
+	if err := h.Keys.Create(ctx, k); err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "key store unavailable", err))
+		return
+	}
+	// secret hanya ditampilkan di sini
+	writeJSON(w, http.StatusCreated, k)
+}
+
+func (h APIKeyHandlers) list(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	keys, err := h.Keys.List(ctx, mux.Vars(r)["merchant_id"])
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "key store unavailable", err))
+		return
+	}
+	for i := range keys {
+		keys[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (h APIKeyHandlers) rotate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	grace := h.RotateGrace
+	if grace <= 0 {
+		grace = 24 * time.Hour
+	}
+	v := mux.Vars(r)
+	k, err := auth.Rotate(ctx, h.Keys, v["merchant_id"], v["key_id"], grace)
+	switch {
+	case errors.Is(err, auth.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "API key %s not found", v["key_id"]).With("key_id", v["key_id"]))
+	case errors.Is(err, auth.ErrRevoked):
+		perrors.Write(w, r, perrors.New(perrors.Conflict, "API key %s is revoked", v["key_id"]).With("key_id", v["key_id"]))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "key store unavailable", err))
+	default:
+		writeJSON(w, http.StatusCreated, k)
+	}
+}
+
+func (h APIKeyHandlers) revoke(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	v := mux.Vars(r)
+	err := h.Keys.Revoke(ctx, v["merchant_id"], v["key_id"], time.Now())
+	switch {
+	case errors.Is(err, auth.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "API key %s not found", v["key_id"]).With("key_id", v["key_id"]))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "key store unavailable", err))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// merchantScope: /merchants/{merchant_id} hanya untuk merchant itu sendiri;
+// request anonim (GATEWAY_AUTH=optional) ditolak.
+func merchantScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := auth.MerchantID(r.Context())
+		if !ok {
+			perrors.Write(w, r, perrors.New(perrors.Unauthenticated, "merchant signature required").
+				With("reason", "authentication_required"))
+			return
+		}
+		if id != mux.Vars(r)["merchant_id"] {
+			perrors.Write(w, r, perrors.New(perrors.MerchantMismatch, "merchant_id does not match the API key"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
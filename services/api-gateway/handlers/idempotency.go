@@ -0,0 +1,133 @@
+// services/api-gateway/handlers/idempotency.go
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/idempotency"
+)
+
+// replayHeaders: header response yang ikut disimpan untuk replay.
+var replayHeaders = []string{"Content-Type", "Location", "Preference-Applied", "Retry-After"}
+
+// Idempotent membungkus PaymentsHandler: request dengan idempotency_key yang
+// sama dan payload yang sama mendapat response pertama tanpa menjalankan
+// FX/wallet/risk/publish lagi; payload berbeda → 409. Duplikat yang datang
+// saat request pertama masih jalan menunggu paling lama maxWait.
+func Idempotent(s idempotency.Store, maxWait time.Duration, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "unreadable request body"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var in PaymentIn
+		if json.Unmarshal(body, &in) != nil || in.IdempotencyKey == "" {
+			next.ServeHTTP(w, r) // validasi di handler
+			return
+		}
+		fp := fingerprint(r, in)
+		// key berlaku per merchant: dua merchant boleh memakai key yang sama
+		key := in.IdempotencyKey
+		if merchant, ok := auth.MerchantID(r.Context()); ok {
+			key = merchant + ":" + key
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), maxWait)
+		stored, token, err := s.Begin(ctx, key, fp)
+		cancel()
+		switch {
+		case errors.Is(err, idempotency.ErrConflict):
+			perrors.Write(w, r, perrors.New(perrors.IdempotencyKeyConflict,
+				"idempotency_key %s was used with a different request", in.IdempotencyKey))
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			w.Header().Set("Retry-After", "1")
+			perrors.Write(w, r, perrors.New(perrors.RequestInProgress,
+				"a request with idempotency_key %s is still running", in.IdempotencyKey))
+			return
+		case err != nil:
+			slog.ErrorContext(r.Context(), "idempotency: begin failed", "key", key, "error", err)
+			perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "idempotency store unavailable", err))
+			return
+		case stored != nil:
+			for k, vs := range stored.Header {
+				w.Header()[k] = vs
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// selesaikan walau client sudah putus
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 3*time.Second)
+			defer cancel()
+			if !completed || !cacheable(rec.status) {
+				if err := s.Release(ctx, key, token); err != nil {
+					slog.WarnContext(ctx, "idempotency: release failed", "key", key, "error", err)
+				}
+				return
+			}
+			resp := idempotency.Response{Status: rec.status, Header: http.Header{}, Body: rec.body.Bytes()}
+			for _, k := range replayHeaders {
+				if v := w.Header().Values(k); len(v) > 0 {
+					resp.Header[k] = v
+				}
+			}
+			if err := s.Complete(ctx, key, token, resp); err != nil {
+				slog.ErrorContext(ctx, "idempotency: complete failed", "key", key, "error", err)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		completed = true
+	}
+}
+
+// cacheable: 5xx berarti payment belum dipublish (FX/wallet/risk/store
+// gagal), jadi retry boleh jalan lagi. Payment yang sudah dipublish selalu
+// dijawab 2xx (202 + Location bila result belum datang).
+func cacheable(status int) bool {
+	return status < 500
+}
+
+// fingerprint: hash dari payload yang sudah di-decode, jadi urutan field
+// atau whitespace tidak membuat payload dianggap berbeda.
+func fingerprint(r *http.Request, in PaymentIn) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	_ = json.NewEncoder(h).Encode(in)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,147 @@
+// services/api-gateway/handlers/merchants.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+)
+
+// MerchantHandlers: registry merchant. Onboarding dan assign akun lewat
+// /api/admin/merchants (ADMIN_TOKEN); merchant sendiri hanya bisa membaca
+// profilnya di /api/merchants/{merchant_id}.
+type MerchantHandlers struct {
+	Store      merchant.Store
+	AdminToken string
+}
+
+type merchantOut struct {
+	merchant.Merchant
+	Accounts []string `json:"accounts"`
+}
+
+func (h MerchantHandlers) Register(r *mux.Router) {
+	admin := r.PathPrefix("/api/admin/merchants").Subrouter()
+	admin.Use(func(next http.Handler) http.Handler { return auth.AdminOnly(h.AdminToken, next) })
+	admin.HandleFunc("", h.create).Methods(http.MethodPost)
+	admin.HandleFunc("", h.list).Methods(http.MethodGet)
+	admin.HandleFunc("/{merchant_id}", h.get).Methods(http.MethodGet)
+	admin.HandleFunc("/{merchant_id}/accounts", h.assignAccount).Methods(http.MethodPost)
+
+	self := r.PathPrefix("/api/merchants/{merchant_id}").Subrouter()
+	self.Use(merchantScope)
+	self.HandleFunc("", h.get).Methods(http.MethodGet)
+}
+
+func (h MerchantHandlers) create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	var in merchant.Merchant
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "malformed JSON body"))
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "%s", err.Error()))
+		return
+	}
+	switch err := h.Store.Create(ctx, &in); {
+	case errors.Is(err, merchant.ErrExists):
+		perrors.Write(w, r, perrors.New(perrors.Conflict, "merchant %s already exists", in.ID).With("merchant_id", in.ID))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+	default:
+		writeJSON(w, http.StatusCreated, merchantOut{Merchant: in, Accounts: []string{}})
+	}
+}
+
+func (h MerchantHandlers) list(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	ms, err := h.Store.List(ctx)
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+		return
+	}
+	if ms == nil {
+		ms = []merchant.Merchant{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"merchants": ms})
+}
+
+func (h MerchantHandlers) get(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	id := mux.Vars(r)["merchant_id"]
+	mc, err := h.Store.Get(ctx, id)
+	if errors.Is(err, merchant.ErrNotFound) {
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "merchant %s not found", id).With("merchant_id", id))
+		return
+	}
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+		return
+	}
+	accounts, err := h.Store.Accounts(ctx, id)
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+		return
+	}
+	if accounts == nil {
+		accounts = []string{}
+	}
+	writeJSON(w, http.StatusOK, merchantOut{Merchant: *mc, Accounts: accounts})
+}
+
+func (h MerchantHandlers) assignAccount(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	var in struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.AccountID == "" {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "account_id is required"))
+		return
+	}
+	id := mux.Vars(r)["merchant_id"]
+	switch err := h.Store.AssignAccount(ctx, id, in.AccountID); {
+	case errors.Is(err, merchant.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "merchant %s not found", id).With("merchant_id", id))
+	case errors.Is(err, merchant.ErrAccountNotFound):
+		perrors.Write(w, r, perrors.New(perrors.AccountNotFound, "account %s not found", in.AccountID).
+			With("account_id", in.AccountID))
+	case errors.Is(err, merchant.ErrAccountTaken):
+		perrors.Write(w, r, perrors.New(perrors.Conflict, "account %s belongs to another merchant", in.AccountID).
+			With("account_id", in.AccountID))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ownsAccount: akun boleh dipakai tenant di ctx bila pemiliknya sama
+// (akun bersama hanya untuk request anonim). Tanpa registry semua akun
+// dianggap bersama.
+func ownsAccount(ctx context.Context, owners merchant.Store, accountID string) (bool, error) {
+	if owners == nil {
+		return true, nil
+	}
+	owner, err := owners.AccountOwner(ctx, accountID)
+	if errors.Is(err, merchant.ErrAccountNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return owner == tenant.ID(ctx), nil
+}
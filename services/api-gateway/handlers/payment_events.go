@@ -0,0 +1,113 @@
+// services/api-gateway/handlers/payment_events.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+	"github.com/example/payment-gateway-poc/services/api-gateway/stream"
+)
+
+// PaymentEventsHandler: GET /api/payments/{id}/events. Snapshot status dulu,
+// lalu update sampai status final; pengganti polling setelah 202.
+func PaymentEventsHandler(s store.PaymentStore, hub *stream.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		p, err := getPayment(r.Context(), s, id)
+		if errors.Is(err, store.ErrNotFound) {
+			perrors.Write(w, r, perrors.New(perrors.PaymentNotFound, "payment not found"))
+			return
+		}
+		if err != nil {
+			perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "payment store unavailable", err))
+			return
+		}
+
+		// subscribe sebelum snapshot kedua: result yang datang di antaranya
+		// tidak terlewat
+		sub := hub.Subscribe(stream.ForIdempotencyKey(p.IdempotencyKey))
+		defer hub.Unsubscribe(sub)
+		if p.Status == store.StatusPending {
+			if latest, err := getPayment(r.Context(), s, id); err == nil {
+				p = latest
+			}
+		}
+
+		c, transport, err := hub.Open(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		defer stream.Track(transport)()
+
+		snap := snapshot(p)
+		if err := c.Send("payment", "", snap); err != nil || snap.Final() {
+			return
+		}
+		stream.Pump(c, sub, true, func(u *stream.Update) {
+			u.PaymentID = id
+			u.IdempotencyKey = tenant.ClientKey(p.MerchantID, u.IdempotencyKey)
+		})
+	}
+}
+
+// AccountEventsHandler: GET /api/accounts/{id}/events, semua payment keluar
+// dan masuk akun ini sampai client menutup koneksi. Hanya untuk akun milik
+// tenant pemanggil.
+func AccountEventsHandler(hub *stream.Hub, owners merchant.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		ok, err := ownsAccount(r.Context(), owners, id)
+		if err != nil {
+			perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+			return
+		}
+		if !ok {
+			perrors.Write(w, r, perrors.New(perrors.AccountNotFound, "account %s not found", id).
+				With("account_id", id))
+			return
+		}
+		sub := hub.Subscribe(stream.ForAccount(id))
+		defer hub.Unsubscribe(sub)
+
+		c, transport, err := hub.Open(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		defer stream.Track(transport)()
+
+		// akun milik tenant pemanggil: key di stream milik tenant yang sama
+		caller := tenant.ID(r.Context())
+		stream.Pump(c, sub, false, func(u *stream.Update) {
+			u.IdempotencyKey = tenant.ClientKey(caller, u.IdempotencyKey)
+		})
+	}
+}
+
+func getPayment(ctx context.Context, s store.PaymentStore, id string) (*store.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return s.Get(ctx, tenant.ID(ctx), id)
+}
+
+func snapshot(p *store.Payment) stream.Update {
+	return stream.Update{
+		PaymentID:      p.ID,
+		IdempotencyKey: tenant.ClientKey(p.MerchantID, p.IdempotencyKey),
+		SenderID:       p.SenderID,
+		ReceiverID:     p.ReceiverID,
+		Status:         p.Status,
+		Reason:         p.Reason,
+		Ref:            p.Ref,
+		At:             p.UpdatedAt,
+	}
+}
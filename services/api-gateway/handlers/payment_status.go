@@ -0,0 +1,39 @@
+// services/api-gateway/handlers/payment_status.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+// PaymentStatusHandler: GET /api/payments/{id} untuk polling setelah 202.
+func PaymentStatusHandler(s store.PaymentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+
+		// payment tenant lain → 404, keberadaannya tidak dibocorkan
+		p, err := s.Get(ctx, tenant.ID(r.Context()), mux.Vars(r)["id"])
+		if errors.Is(err, store.ErrNotFound) {
+			perrors.Write(w, r, perrors.New(perrors.PaymentNotFound, "payment not found"))
+			return
+		}
+		if err != nil {
+			perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "payment store unavailable", err))
+			return
+		}
+		if p.Status == store.StatusPending {
+			w.Header().Set("Retry-After", "1")
+		}
+		p.IdempotencyKey = tenant.ClientKey(p.MerchantID, p.IdempotencyKey)
+		writeJSON(w, http.StatusOK, p)
+	}
+}
@@ -4,15 +4,28 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
 	fxv1   "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
 	riskv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
 	wv1    "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
 
-	"github.com/example/payment-gateway-poc/services/api-gateway/queue"
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+	"github.com/example/payment-gateway-poc/pkg/events"
 	m "github.com/example/payment-gateway-poc/pkg/metrics"
 )
 
@@ -20,7 +33,18 @@ type Deps struct {
 	Fx     fxv1.FxServiceClient
 	Wallet wv1.WalletServiceClient
 	Risk   riskv1.RiskServiceClient
-	Bus    *queue.Bus
+	Bus    queue.Bus
+	Store  store.PaymentStore
+	// Merchants: registry tenant; nil = semua akun dianggap akun bersama
+	// dan tanpa fee.
+	Merchants merchant.Store
+
+	// SyncWait: berapa lama request sync menunggu result sebelum dijawab
+	// 202 PENDING. Default 5 detik.
+	SyncWait time.Duration
+	// AsyncWait: berapa lama result ditunggu di background untuk request
+	// async (atau sync yang timeout). Default 2 menit.
+	AsyncWait time.Duration
 }
 
 func PaymentsHandler(d Deps) http.HandlerFunc {
@@ -30,22 +54,74 @@ func PaymentsHandler(d Deps) http.HandlerFunc {
 
 		var in PaymentIn
 		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-			writeJSON(w, http.StatusBadRequest, PaymentOut{Status: "FAILED", Reason: "bad_json"})
+			perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "malformed JSON body"))
 			return
 		}
 		if in.SenderID == "" || in.ReceiverID == "" || in.Amount <= 0 || in.IdempotencyKey == "" || in.TxDateISO == "" {
-			writeJSON(w, http.StatusBadRequest, PaymentOut{Status: "FAILED", Reason: "invalid_input"})
+			perrors.Write(w, r, perrors.New(perrors.InvalidArgument,
+				"sender_id, receiver_id, amount, tx_date and idempotency_key are required"))
+			return
+		}
+		if id, ok := auth.MerchantID(r.Context()); ok {
+			if in.MerchantID != "" && in.MerchantID != id {
+				perrors.Write(w, r, perrors.New(perrors.MerchantMismatch, "merchant_id does not match the API key"))
+				return
+			}
+			in.MerchantID = id
+		} else if d.Merchants != nil && in.MerchantID != "" {
+			// dengan registry, merchant_id hanya dari API key
+			perrors.Write(w, r, perrors.New(perrors.Unauthenticated, "merchant_id requires a merchant API key"))
 			return
 		}
 		cur := strings.ToUpper(strings.TrimSpace(in.Currency))
 		if cur != "IDR" && cur != "USD" && cur != "SGD" {
-			writeJSON(w, http.StatusBadRequest, PaymentOut{Status: "FAILED", Reason: "unsupported_currency"})
+			perrors.Write(w, r, perrors.New(perrors.UnsupportedCurrency, "currency must be IDR, USD or SGD").
+				With("currency", cur))
 			return
 		}
 
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
 
+		// 0) Tenant: merchant terdaftar, kedua akun miliknya; akun merchant
+		// lain diperlakukan seperti tidak ada
+		var fee merchant.FeePlan
+		settleCur := "IDR"
+		if d.Merchants != nil && in.MerchantID != "" {
+			mc, err := d.Merchants.Get(ctx, in.MerchantID)
+			if errors.Is(err, merchant.ErrNotFound) {
+				perrors.Write(w, r, perrors.New(perrors.UnknownMerchant, "merchant %s is not registered", in.MerchantID))
+				return
+			}
+			if err != nil {
+				perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+				return
+			}
+			fee, settleCur = mc.FeePlan, mc.SettlementCurrency
+		}
+		for _, acc := range []string{in.SenderID, in.ReceiverID} {
+			ok, err := ownsAccount(ctx, d.Merchants, acc)
+			if err != nil {
+				perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err))
+				return
+			}
+			if !ok {
+				m.IncRequest("api-gateway", "FAILED", "TENANT_ACCOUNT")
+				perrors.Write(w, r, perrors.New(perrors.AccountNotFound, "account %s not found", acc).
+					With("account_id", acc))
+				return
+			}
+		}
+		// idempotency key milik client di-namespace per tenant sampai ke
+		// worker (payments.idempotency_key unik global)
+		idemKey := tenant.Key(in.MerchantID, in.IdempotencyKey)
+		// id dibuat sebelum memanggil upstream: semua log payment ini, di
+		// gateway maupun FX/wallet/risk/worker, membawa payment_id yang sama
+		id := uuid.NewString()
+		ctx = logging.With(ctx, logging.FieldIdempotencyKey, idemKey, logging.FieldPaymentID, id)
+		slog.InfoContext(ctx, "payment received", "merchant_id", in.MerchantID,
+			"currency", cur, "amount", in.Amount)
+
 		// 1) FX convert → amount_idr
 		amountIDR := in.Amount
 		if cur != "IDR" {
@@ -55,24 +131,52 @@ func PaymentsHandler(d Deps) http.HandlerFunc {
 				Amount:       in.Amount,
 			})
 			if err != nil {
+				slog.ErrorContext(ctx, "payment: fx convert failed", "error", err)
 				m.IncRequest("api-gateway", "FAILED", "FX")
-				writeJSON(w, http.StatusBadGateway, PaymentOut{Status: "FAILED", Reason: "fx_unavailable"})
+				perrors.Write(w, r, upstream(err, "fx", perrors.FXRateStale, perrors.FXRateUnavailable))
 				return
 			}
 			amountIDR = fxRes.GetAmount()
 			m.IncRequest("api-gateway", "SUCCESS", "FX")
 		}
 
+		// 1b) Fee plan dipotong dari kredit receiver; nilai bersih dalam
+		// mata uang settlement merchant ikut di event untuk dicatat worker
+		feeIDR := fee.Fee(int64(amountIDR))
+		if feeIDR >= int64(amountIDR) {
+			perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "amount does not cover the merchant fee").
+				With("fee_idr", strconv.FormatInt(feeIDR, 10)))
+			return
+		}
+		settleAmount := float64(int64(amountIDR) - feeIDR)
+		if settleCur != "IDR" {
+			fxRes, err := d.Fx.Convert(ctx, &fxv1.ConvertRequest{
+				FromCurrency: "IDR",
+				ToCurrency:   settleCur,
+				Amount:       settleAmount,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "payment: fx convert settlement failed", "error", err)
+				m.IncRequest("api-gateway", "FAILED", "FX")
+				perrors.Write(w, r, upstream(err, "fx", perrors.FXRateStale, perrors.FXRateUnavailable))
+				return
+			}
+			settleAmount = fxRes.GetAmount()
+		}
+
 		// 2) Wallet check saldo pengirim
 		acc, err := d.Wallet.GetAccount(ctx, &wv1.GetAccountRequest{AccountId: in.SenderID})
 		if err != nil {
+			slog.ErrorContext(ctx, "payment: wallet get account failed", "error", err)
 			m.IncRequest("api-gateway", "FAILED", "WALLET_GET")
-			writeJSON(w, http.StatusBadGateway, PaymentOut{Status: "FAILED", Reason: "wallet_unavailable"})
+			perrors.Write(w, r, upstream(err, "wallet", perrors.AccountNotFound, perrors.AccountBlocked))
 			return
 		}
 		if acc.GetBalanceIdr() < int64(amountIDR) {
+			slog.InfoContext(ctx, "payment rejected", "code", perrors.InsufficientFunds)
 			m.IncRequest("api-gateway", "FAILED", "WALLET_INSUFFICIENT")
-			writeJSON(w, http.StatusOK, PaymentOut{Status: "FAILED", Reason: "insufficient_funds"})
+			perrors.Write(w, r, perrors.New(perrors.InsufficientFunds, "balance of %s is below the payment amount", in.SenderID).
+				With("account_id", in.SenderID))
 			return
 		}
 		m.IncRequest("api-gateway", "SUCCESS", "WALLET_CHECK")
@@ -86,47 +190,184 @@ func PaymentsHandler(d Deps) http.HandlerFunc {
 			TxDateIso:     in.TxDateISO,
 		})
 		if err != nil {
+			slog.ErrorContext(ctx, "payment: risk evaluate failed", "error", err)
 			m.IncRequest("api-gateway", "FAILED", "RISK_CALL")
-			writeJSON(w, http.StatusBadGateway, PaymentOut{Status: "FAILED", Reason: "risk_unavailable"})
+			perrors.Write(w, r, upstream(err, "risk"))
 			return
 		}
 		if !riskRes.GetAllow() {
+			slog.InfoContext(ctx, "payment rejected", "code", perrors.RiskDenied, "reason", riskRes.GetReason())
 			m.IncRequest("api-gateway", "FAILED", "RISK_DENY")
-			writeJSON(w, http.StatusOK, PaymentOut{Status: "FAILED", Reason: riskRes.GetReason()})
+			perrors.Write(w, r, perrors.New(perrors.RiskDenied, "payment denied by risk check").
+				With("reason", riskRes.GetReason()))
 			return
 		}
 		m.IncRequest("api-gateway", "SUCCESS", "RISK_ALLOW")
 
-		// 4) Publish ke Kafka & tunggu result
-		payload, _ := json.Marshal(map[string]any{
-			"idempotency_key": in.IdempotencyKey,
-			"sender_id":       in.SenderID,
-			"receiver_id":     in.ReceiverID,
-			"amount_idr":      int64(amountIDR),
-			"currency_input":  cur,
-			"tx_date":         in.TxDateISO,
-		})
+		// 4) Simpan request (PENDING), publish ke Kafka
+		rec := &store.Payment{
+			ID:             id,
+			IdempotencyKey: idemKey,
+			SenderID:       in.SenderID,
+			ReceiverID:     in.ReceiverID,
+			Currency:       cur,
+			Amount:         in.Amount,
+			AmountIDR:      int64(amountIDR),
+			TxDateISO:      in.TxDateISO,
+			MerchantID:     in.MerchantID,
+			FeeIDR:         feeIDR,
+			Status:         store.StatusPending,
+		}
+		if err := d.Store.Create(ctx, rec); err != nil {
+			slog.ErrorContext(ctx, "payment: store create failed", "error", err)
+			m.IncRequest("api-gateway", "FAILED", "STORE_CREATE")
+			perrors.Write(w, r, perrors.Wrap(perrors.Internal, "store payment", err))
+			return
+		}
+
+		payload, err := events.EncodeRequested(&eventsv1.PaymentRequested{
+			IdempotencyKey:     idemKey,
+			SenderId:           in.SenderID,
+			ReceiverId:         in.ReceiverID,
+			AmountIdr:          int64(amountIDR),
+			CurrencyInput:      cur,
+			TxDate:             in.TxDateISO,
+			MerchantId:         in.MerchantID,
+			FeeIdr:             feeIDR,
+			SettlementCurrency: settleCur,
+			SettlementAmount:   settleAmount,
+		}, "api-gateway")
+		if err != nil {
+			m.IncRequest("api-gateway", "FAILED", "KAFKA_PUBLISH")
+			perrors.Write(w, r, perrors.Wrap(perrors.Internal, "encode payment event", err))
+			return
+		}
 
-		if err := d.Bus.Publish(ctx, []byte(in.IdempotencyKey), payload); err != nil {
+		// key = sender_id: worker menjaga urutan per akun pengirim
+		if err := d.Bus.Publish(ctx, []byte(in.SenderID), payload); err != nil {
+			slog.ErrorContext(ctx, "payment: publish failed", "error", err)
 			m.IncRequest("api-gateway", "FAILED", "KAFKA_PUBLISH")
-			writeJSON(w, http.StatusBadGateway, PaymentOut{Status: "FAILED", Reason: "queue_publish_error"})
+			_ = d.Store.SetResult(context.Background(), rec.ID, "FAILED", "queue_publish_error", "")
+			perrors.Write(w, r, perrors.E{Code: perrors.Unavailable, Message: "payment queue unavailable", Err: err}.
+				With("payment_id", rec.ID))
 			return
 		}
 		m.IncRequest("api-gateway", "SUCCESS", "KAFKA_PUBLISH")
+		slog.InfoContext(ctx, "payment published", "amount_idr", rec.AmountIDR)
 
-		result, ok, err := d.Bus.WaitResult(r.Context(), []byte(in.IdempotencyKey), 5*time.Second)
-		if err != nil || !ok {
-			m.IncRequest("api-gateway", "FAILED", "KAFKA_WAIT")
-			writeJSON(w, http.StatusGatewayTimeout, PaymentOut{Status: "FAILED", Reason: "queue_timeout"})
+		location := "/api/payments/" + rec.ID
+
+		// 5a) Async (Prefer: respond-async): langsung 202, client polling Location
+		if preferAsync(r) {
+			go d.completeLater(context.WithoutCancel(ctx), rec.ID, idemKey)
+			m.IncRequest("api-gateway", "SUCCESS", "ASYNC_ACCEPTED")
+			w.Header().Set("Location", location)
+			w.Header().Set("Preference-Applied", "respond-async")
+			writeJSON(w, http.StatusAccepted, PaymentOut{Status: store.StatusPending, PaymentID: rec.ID})
 			return
 		}
-		m.IncRequest("api-gateway", "SUCCESS", "KAFKA_WAIT")
 
-		var out PaymentOut
-		if err := json.Unmarshal(result, &out); err != nil || out.Status == "" {
-			writeJSON(w, http.StatusOK, PaymentOut{Status: "FAILED", Reason: "bad_worker_result"})
+		// 5b) Sync: tunggu result
+		wait := d.SyncWait
+		if wait <= 0 {
+			wait = 5 * time.Second
+		}
+		result, ok, err := d.Bus.WaitResult(r.Context(), []byte(idemKey), wait)
+		if err != nil || !ok {
+			m.IncRequest("api-gateway", "PENDING", "KAFKA_WAIT")
+			slog.WarnContext(ctx, "payment: result timeout, waiting in background")
+			// payment sudah dipublish dan bisa settle belakangan: bukan
+			// FAILED, client polling Location seperti jalur async
+			go d.completeLater(context.WithoutCancel(ctx), rec.ID, idemKey)
+			w.Header().Set("Location", location)
+			writeJSON(w, http.StatusAccepted, PaymentOut{Status: store.StatusPending, PaymentID: rec.ID})
 			return
 		}
+		m.IncRequest("api-gateway", "SUCCESS", "KAFKA_WAIT")
+
+		out := d.recordResult(context.WithoutCancel(ctx), rec.ID, result)
 		writeJSON(w, http.StatusOK, out)
 	}
 }
+
+// upstream: error panggilan FX/wallet/risk. Kode domain yang boleh
+// diteruskan ke client (pass) tetap; selain itu UNAVAILABLE tanpa pesan
+// upstream.
+func upstream(err error, service string, pass ...string) error {
+	e := perrors.From(err)
+	for _, code := range pass {
+		if e.Code == code {
+			return e
+		}
+	}
+	return perrors.Wrap(perrors.Unavailable, service+" unavailable", err)
+}
+
+// preferAsync: RFC 7240 "Prefer: respond-async"
+func preferAsync(r *http.Request) bool {
+	for _, v := range r.Header.Values("Prefer") {
+		for _, p := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(p), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// completeLater: tunggu result di background lalu simpan ke store. ctx
+// tanpa cancel, hanya membawa field log request asalnya.
+func (d Deps) completeLater(ctx context.Context, id, idempotencyKey string) {
+	wait := d.AsyncWait
+	if wait <= 0 {
+		wait = 2 * time.Minute
+	}
+	result, ok, err := d.Bus.WaitResult(ctx, []byte(idempotencyKey), wait)
+	if err != nil || !ok {
+		slog.WarnContext(ctx, "payment: no result", "wait", wait.String(), "error", err)
+		m.IncRequest("api-gateway", "FAILED", "ASYNC_WAIT")
+		return
+	}
+	m.IncRequest("api-gateway", "SUCCESS", "ASYNC_WAIT")
+	d.recordResult(ctx, id, result)
+}
+
+func (d Deps) recordResult(ctx context.Context, id string, result []byte) PaymentOut {
+	out := PaymentOut{Status: "FAILED", Reason: "bad_worker_result", Code: perrors.Internal}
+	if ev, err := events.DecodeResult(result); err == nil {
+		out = resultOut(ev)
+	}
+	if err := d.Store.SetResult(ctx, id, out.Status, out.Reason, out.Ref); err != nil {
+		slog.ErrorContext(ctx, "payment: store result failed", "error", err)
+		m.IncRequest("api-gateway", "FAILED", "STORE_UPDATE")
+	}
+	slog.InfoContext(ctx, "payment completed", "status", out.Status, "reason", out.Reason, "ref", out.Ref)
+	out.PaymentID = id
+	return out
+}
+
+func resultOut(ev events.Result) PaymentOut {
+	switch ev := ev.(type) {
+	case *eventsv1.PaymentSettled:
+		return PaymentOut{Status: ev.GetStatus(), Ref: ev.GetPaymentId()}
+	case *eventsv1.PaymentFailed:
+		return PaymentOut{Status: "FAILED", Reason: ev.GetReason(), Code: resultCode(ev.GetReason()), Ref: ev.GetPaymentId()}
+	}
+	return PaymentOut{Status: "FAILED", Reason: "bad_worker_result", Code: perrors.Internal}
+}
+
+// resultCodes: reason dari worker (PaymentFailed.reason) → kode katalog,
+// sama dengan kode problem+json untuk penolakan sebelum publish.
+var resultCodes = map[string]string{
+	"insufficient_funds":        perrors.InsufficientFunds,
+	"receiver_not_found":        perrors.AccountNotFound,
+	"duplicate_idempotency_key": perrors.DuplicatePayment,
+	"invalid_input":             perrors.InvalidArgument,
+}
+
+func resultCode(reason string) string {
+	if code, ok := resultCodes[reason]; ok {
+		return code
+	}
+	return perrors.Internal
+}
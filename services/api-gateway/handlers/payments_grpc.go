@@ -0,0 +1,86 @@
+// services/api-gateway/handlers/payments_grpc.go
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "github.com/example/payment-gateway-poc/proto/gen/common/v1"
+	paymentsv1 "github.com/example/payment-gateway-poc/proto/gen/payments/v1"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	m "github.com/example/payment-gateway-poc/pkg/metrics"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+)
+
+// PaymentsFacade: PaymentsService publik di port gateway (gRPC dan
+// gRPC-Web) untuk UI dan aplikasi mobile. Signature merchant dan rate limit
+// sudah diterapkan di depannya (middleware HTTP yang sama dengan REST); di
+// sini validasi, scope tenant, lalu diteruskan ke payments-grpc.
+type PaymentsFacade struct {
+	paymentsv1.UnimplementedPaymentsServiceServer
+
+	Upstream paymentsv1.PaymentsServiceClient
+	// Merchants: registry tenant; nil = semua akun dianggap akun bersama.
+	Merchants merchant.Store
+	Timeout   time.Duration // default 5 detik
+}
+
+func (f *PaymentsFacade) CreatePayment(ctx context.Context, in *paymentsv1.CreatePaymentRequest) (*paymentsv1.CreatePaymentResponse, error) {
+	switch {
+	case in.GetAmountMinor() <= 0:
+		return nil, perrors.New(perrors.InvalidArgument, "amount_minor must be greater than 0")
+	case in.GetCurrency() == commonv1.Currency_CURRENCY_UNSPECIFIED:
+		return nil, perrors.New(perrors.InvalidArgument, "currency is required")
+	case in.GetSourceAccount() == "" || in.GetDestinationAccount() == "":
+		return nil, perrors.New(perrors.InvalidArgument, "source_account and destination_account are required")
+	case in.GetClientTxId() == "":
+		return nil, perrors.New(perrors.InvalidArgument, "client_tx_id is required")
+	}
+
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// akun merchant lain diperlakukan seperti tidak ada (sama dengan REST)
+	for _, acc := range []string{in.GetSourceAccount(), in.GetDestinationAccount()} {
+		ok, err := ownsAccount(ctx, f.Merchants, acc)
+		if err != nil {
+			return nil, perrors.Wrap(perrors.Unavailable, "merchant registry unavailable", err)
+		}
+		if !ok {
+			m.IncRequest("api-gateway", "FAILED", "TENANT_ACCOUNT")
+			return nil, perrors.New(perrors.AccountNotFound, "account %s not found", acc).With("account_id", acc)
+		}
+	}
+
+	// client_tx_id = idempotency key client, di-namespace per tenant
+	req := proto.Clone(in).(*paymentsv1.CreatePaymentRequest)
+	req.ClientTxId = tenant.Key(tenant.ID(ctx), in.GetClientTxId())
+	ctx = logging.With(ctx, logging.FieldIdempotencyKey, req.ClientTxId)
+	res, err := f.Upstream.CreatePayment(ctx, req)
+	if err != nil {
+		m.IncRequest("api-gateway", "FAILED", "GRPC_CREATE_PAYMENT")
+		return nil, err
+	}
+	m.IncRequest("api-gateway", "SUCCESS", "GRPC_CREATE_PAYMENT")
+	slog.InfoContext(logging.With(ctx, logging.FieldPaymentID, res.GetPaymentId()),
+		"payment created", "status", res.GetStatus())
+	return res, nil
+}
+
+// LogAndSettle hanya untuk worker settlement internal, tidak dibuka di
+// facade publik.
+func (f *PaymentsFacade) LogAndSettle(context.Context, *paymentsv1.LogAndSettleRequest) (*paymentsv1.LogAndSettleResponse, error) {
+	return nil, status.Error(codes.PermissionDenied, "LogAndSettle is internal to the payment worker")
+}
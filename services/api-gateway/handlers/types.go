@@ -8,10 +8,13 @@ type PaymentIn struct {
     Amount         float64 `json:"amount"`
     TxDateISO      string  `json:"tx_date"`
     IdempotencyKey string  `json:"idempotency_key"`
+    MerchantID     string  `json:"merchant_id,omitempty"` // opsional: tujuan webhook
 }
 
 type PaymentOut struct {
-    Status string `json:"status"`
-    Reason string `json:"reason,omitempty"`
-    Ref    string `json:"ref,omitempty"` // reservation_id / reference
+    Status    string `json:"status"`
+    Reason    string `json:"reason,omitempty"`
+    Code      string `json:"code,omitempty"` // kode katalog pkg/errors untuk Reason
+    Ref       string `json:"ref,omitempty"` // reservation_id / reference
+    PaymentID string `json:"payment_id,omitempty"` // untuk GET /api/payments/{id}
 }
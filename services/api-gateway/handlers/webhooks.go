@@ -0,0 +1,159 @@
+// services/api-gateway/handlers/webhooks.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/payment-gateway-poc/internal/webhook"
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+type WebhookEndpointIn struct {
+	URL string `json:"url"`
+}
+
+type deliveryOut struct {
+	*webhook.Delivery
+	Attempts []webhook.Attempt `json:"attempt_log,omitempty"`
+}
+
+// WebhookHandlers: registrasi endpoint, delivery log, dan redeliver manual,
+// semua di bawah /api/merchants/{merchant_id}/webhooks.
+type WebhookHandlers struct {
+	Store webhook.Store
+	Retry webhook.Retry // window untuk redeliver manual
+	// AllowPrivate: terima URL ke alamat loopback/privat (dev, test).
+	AllowPrivate bool
+}
+
+func (h WebhookHandlers) Register(r *mux.Router) {
+	s := r.PathPrefix("/api/merchants/{merchant_id}/webhooks").Subrouter()
+	s.Use(merchantScope)
+	s.HandleFunc("", h.create).Methods(http.MethodPost)
+	s.HandleFunc("", h.list).Methods(http.MethodGet)
+	s.HandleFunc("/deliveries", h.deliveries).Methods(http.MethodGet)
+	s.HandleFunc("/deliveries/{id}", h.delivery).Methods(http.MethodGet)
+	s.HandleFunc("/deliveries/{id}/redeliver", h.redeliver).Methods(http.MethodPost)
+	s.HandleFunc("/{id}", h.disable).Methods(http.MethodDelete)
+}
+
+func (h WebhookHandlers) create(w http.ResponseWriter, r *http.Request) {
+	var in WebhookEndpointIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "malformed JSON body"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	u, err := webhook.CheckURL(ctx, in.URL, h.AllowPrivate)
+	if errors.Is(err, webhook.ErrForbiddenTarget) {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "url must not target a private or loopback address").
+			With("reason", "forbidden_url"))
+		return
+	}
+	if err != nil {
+		perrors.Write(w, r, perrors.New(perrors.InvalidArgument, "url must be an http(s) URL with a resolvable host").
+			With("reason", "invalid_url"))
+		return
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Internal, "generate webhook secret", err))
+		return
+	}
+	ep := &webhook.Endpoint{
+		MerchantID: mux.Vars(r)["merchant_id"],
+		URL:        u.String(),
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := h.Store.CreateEndpoint(ctx, ep); err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+		return
+	}
+	// secret hanya ditampilkan di sini
+	writeJSON(w, http.StatusCreated, ep)
+}
+
+func (h WebhookHandlers) list(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	eps, err := h.Store.ListEndpoints(ctx, mux.Vars(r)["merchant_id"])
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+		return
+	}
+	for i := range eps {
+		eps[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"endpoints": eps})
+}
+
+func (h WebhookHandlers) disable(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	v := mux.Vars(r)
+	err := h.Store.DisableEndpoint(ctx, v["merchant_id"], v["id"])
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "webhook endpoint %s not found", v["id"]))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h WebhookHandlers) deliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	ds, err := h.Store.ListDeliveries(ctx, mux.Vars(r)["merchant_id"], 100)
+	if err != nil {
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deliveries": ds})
+}
+
+func (h WebhookHandlers) delivery(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	v := mux.Vars(r)
+	d, attempts, err := h.Store.GetDelivery(ctx, v["merchant_id"], v["id"])
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "webhook delivery %s not found", v["id"]))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+	default:
+		writeJSON(w, http.StatusOK, deliveryOut{Delivery: d, Attempts: attempts})
+	}
+}
+
+// redeliver: jadwalkan ulang sekarang dengan retry window baru; yang
+// mengirim tetap webhook-worker.
+func (h WebhookHandlers) redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	v := mux.Vars(r)
+	window := h.Retry.Window
+	if window <= 0 {
+		window = 72 * time.Hour
+	}
+	now := time.Now().UTC()
+	d, err := h.Store.Redeliver(ctx, v["merchant_id"], v["id"], now, now.Add(window))
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		perrors.Write(w, r, perrors.New(perrors.NotFound, "webhook delivery %s not found", v["id"]))
+	case err != nil:
+		perrors.Write(w, r, perrors.Wrap(perrors.Unavailable, "webhook store unavailable", err))
+	default:
+		writeJSON(w, http.StatusAccepted, d)
+	}
+}
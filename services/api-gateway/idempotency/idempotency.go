@@ -0,0 +1,77 @@
+// services/api-gateway/idempotency/idempotency.go
+// Package idempotency menyimpan response per idempotency key di gateway,
+// supaya retry client tidak menjalankan FX, wallet, risk dan publish lagi.
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrConflict: key sama, payload berbeda.
+	ErrConflict = errors.New("idempotency: key reused with a different payload")
+	// ErrInProgress: request pertama belum selesai dalam batas tunggu.
+	ErrInProgress = errors.New("idempotency: request still in progress")
+	// ErrNotOwner: klaim sudah diambil alih request lain (LockTimeout
+	// terlewati); Complete/Release pemilik lama diabaikan.
+	ErrNotOwner = errors.New("idempotency: claim was taken over by another request")
+)
+
+// Response: yang dikembalikan apa adanya saat replay.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store: satu record per key, berlaku sampai TTL.
+type Store interface {
+	// Begin mengklaim key. Hasil:
+	//   (nil, token, nil) → pemanggil pemilik key; wajib Complete atau
+	//                       Release dengan token ini
+	//   (resp, "", nil)   → sudah selesai sebelumnya; kirim resp
+	//   ErrConflict       → fingerprint berbeda
+	// Bila request lain sedang memegang key, Begin menunggu sampai selesai
+	// atau ctx habis (ErrInProgress). Klaim yang diambil alih setelah
+	// LockTimeout mendapat token baru.
+	Begin(ctx context.Context, key, fingerprint string) (resp *Response, token string, err error)
+	// Complete menyimpan response; ErrNotOwner bila token bukan pemilik
+	// klaim saat ini.
+	Complete(ctx context.Context, key, token string, resp Response) error
+	// Release melepas klaim tanpa menyimpan response (mis. upstream gagal),
+	// jadi retry berikutnya jalan normal. ErrNotOwner seperti Complete.
+	Release(ctx context.Context, key, token string) error
+}
+
+// Config untuk semua Store.
+type Config struct {
+	TTL time.Duration // default 24h
+	// LockTimeout: klaim yang lebih tua dari ini dianggap ditinggal
+	// (gateway crash) dan boleh diambil alih. Default 30s.
+	LockTimeout time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.TTL <= 0 {
+		c.TTL = 24 * time.Hour
+	}
+	if c.LockTimeout <= 0 {
+		c.LockTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// pollInterval: jeda cek ulang saat menunggu request yang sedang berjalan.
+const pollInterval = 25 * time.Millisecond
+
+// newToken: token pemilik per klaim.
+func newToken() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,115 @@
+// services/api-gateway/idempotency/memory.go
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memRecord struct {
+	owner       string // token klaim yang berlaku
+	fingerprint string
+	resp        *Response // nil selama in-flight
+	lockedAt    time.Time
+	expiresAt   time.Time
+	done        chan struct{} // ditutup saat Complete/Release
+}
+
+// Memory: Store in-process (satu replica gateway).
+type Memory struct {
+	cfg       Config
+	mu        sync.Mutex
+	data      map[string]*memRecord
+	lastSweep time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory(cfg Config) *Memory {
+	return &Memory{cfg: cfg.withDefaults(), data: map[string]*memRecord{}}
+}
+
+func (s *Memory) Begin(ctx context.Context, key, fingerprint string) (*Response, string, error) {
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		s.sweep(now)
+		rec, ok := s.data[key]
+		if ok && rec.resp != nil && now.After(rec.expiresAt) {
+			ok = false
+		}
+		if ok && rec.resp == nil && now.Sub(rec.lockedAt) > s.cfg.LockTimeout {
+			close(rec.done) // pemilik lama hilang; ambil alih
+			ok = false
+		}
+		if !ok {
+			token := newToken()
+			s.data[key] = &memRecord{
+				owner:       token,
+				fingerprint: fingerprint,
+				lockedAt:    now,
+				expiresAt:   now.Add(s.cfg.TTL),
+				done:        make(chan struct{}),
+			}
+			s.mu.Unlock()
+			return nil, token, nil
+		}
+		if rec.fingerprint != fingerprint {
+			s.mu.Unlock()
+			return nil, "", ErrConflict
+		}
+		if rec.resp != nil {
+			resp := *rec.resp
+			s.mu.Unlock()
+			return &resp, "", nil
+		}
+		done := rec.done
+		s.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, "", ErrInProgress
+		}
+	}
+}
+
+func (s *Memory) Complete(ctx context.Context, key, token string, resp Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.data[key]
+	if !ok || rec.resp != nil || rec.owner != token {
+		return ErrNotOwner
+	}
+	rec.resp = &resp
+	rec.expiresAt = time.Now().Add(s.cfg.TTL)
+	close(rec.done)
+	return nil
+}
+
+func (s *Memory) Release(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.data[key]
+	if !ok || rec.resp != nil || rec.owner != token {
+		return ErrNotOwner
+	}
+	delete(s.data, key)
+	close(rec.done)
+	return nil
+}
+
+// sweep: hapus record kedaluwarsa, paling sering sekali per menit
+// (dipanggil dengan mu terkunci).
+func (s *Memory) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for k, rec := range s.data {
+		if rec.resp != nil && now.After(rec.expiresAt) {
+			delete(s.data, k)
+		}
+	}
+}
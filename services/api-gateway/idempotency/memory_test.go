@@ -0,0 +1,41 @@
+// services/api-gateway/idempotency/memory_test.go
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Pemilik lama yang selesai setelah klaimnya diambil alih tidak boleh
+// menimpa atau melepas klaim pemilik baru.
+func TestMemoryTakeoverIssuesNewOwner(t *testing.T) {
+	s := NewMemory(Config{LockTimeout: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	_, first, err := s.Begin(ctx, "k", "fp")
+	if err != nil || first == "" {
+		t.Fatalf("first Begin: token %q err %v", first, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, second, err := s.Begin(ctx, "k", "fp")
+	if err != nil || second == "" || second == first {
+		t.Fatalf("takeover: token %q (first %q) err %v", second, first, err)
+	}
+
+	if err := s.Complete(ctx, "k", first, Response{Status: 500}); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("stale Complete: %v", err)
+	}
+	if err := s.Release(ctx, "k", first); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("stale Release: %v", err)
+	}
+	if err := s.Complete(ctx, "k", second, Response{Status: 202}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, token, err := s.Begin(ctx, "k", "fp")
+	if err != nil || token != "" || resp == nil || resp.Status != 202 {
+		t.Fatalf("replay: %+v token %q err %v", resp, token, err)
+	}
+}
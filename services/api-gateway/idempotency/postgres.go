@@ -0,0 +1,133 @@
+// services/api-gateway/idempotency/postgres.go
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres: Store yang dibagi semua replica gateway. Duplikat yang masuk
+// ke replica lain menunggu lewat polling baris yang sama.
+type Postgres struct {
+	cfg  Config
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*Postgres)(nil)
+
+func NewPostgres(ctx context.Context, pool *pgxpool.Pool, cfg Config) (*Postgres, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &Postgres{cfg: cfg.withDefaults(), pool: pool}, nil
+}
+
+func (s *Postgres) Begin(ctx context.Context, key, fingerprint string) (*Response, string, error) {
+	for {
+		// klaim baru, atau ambil alih record kedaluwarsa / klaim yang ditinggal;
+		// owner baru membuat Complete/Release pemilik lama tidak berlaku
+		token := newToken()
+		cmd, err := s.pool.Exec(ctx, `
+			INSERT INTO idempotency_keys (key, owner, fingerprint, locked_at, expires_at)
+			VALUES ($1, $5, $2, now(), now() + $3 * interval '1 millisecond')
+			ON CONFLICT (key) DO UPDATE
+			SET owner = EXCLUDED.owner, fingerprint = EXCLUDED.fingerprint, status_code = NULL, header = NULL, body = NULL,
+			    locked_at = now(), expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at < now()
+			   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_at < now() - $4 * interval '1 millisecond')
+		`, key, fingerprint, s.cfg.TTL.Milliseconds(), s.cfg.LockTimeout.Milliseconds(), token)
+		if err != nil {
+			return nil, "", fmt.Errorf("claim key: %w", err)
+		}
+		if cmd.RowsAffected() == 1 {
+			return nil, token, nil
+		}
+
+		var (
+			fp     string
+			status *int
+			header []byte
+			body   []byte
+		)
+		err = s.pool.QueryRow(ctx, `
+			SELECT fingerprint, status_code, header, body FROM idempotency_keys WHERE key = $1
+		`, key).Scan(&fp, &status, &header, &body)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // di-Release di antara dua query
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("read key: %w", err)
+		}
+		if fp != fingerprint {
+			return nil, "", ErrConflict
+		}
+		if status != nil {
+			resp := Response{Status: *status, Body: body}
+			if err := json.Unmarshal(header, &resp.Header); err != nil {
+				return nil, "", fmt.Errorf("decode header: %w", err)
+			}
+			return &resp, "", nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, "", ErrInProgress
+		}
+	}
+}
+
+func (s *Postgres) Complete(ctx context.Context, key, token string, resp Response) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, header = $4, body = $5, expires_at = now() + $6 * interval '1 millisecond'
+		WHERE key = $1 AND owner = $2 AND status_code IS NULL
+	`, key, token, resp.Status, header, resp.Body, s.cfg.TTL.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("complete key: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func (s *Postgres) Release(ctx context.Context, key, token string) error {
+	cmd, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND owner = $2 AND status_code IS NULL`, key, token)
+	if err != nil {
+		return fmt.Errorf("release key: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  key         TEXT PRIMARY KEY,
+  owner       TEXT        NOT NULL DEFAULT '',
+  fingerprint TEXT        NOT NULL,
+  status_code INT,
+  header      JSONB,
+  body        BYTEA,
+  locked_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at  TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_idx ON idempotency_keys (expires_at);
+-- token pemilik klaim (tabel lama belum punya kolom ini)
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+`)
+	return err
+}
@@ -2,69 +2,251 @@
 package main
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/example/payment-gateway-poc/internal/webhook"
+	"github.com/example/payment-gateway-poc/services/api-gateway/admin"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
 	"github.com/example/payment-gateway-poc/services/api-gateway/client"
+	"github.com/example/payment-gateway-poc/services/api-gateway/grpcweb"
 	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
-	"github.com/example/payment-gateway-poc/services/api-gateway/queue"
+	"github.com/example/payment-gateway-poc/services/api-gateway/idempotency"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/openapi"
+	"github.com/example/payment-gateway-poc/services/api-gateway/ratelimit"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+	"github.com/example/payment-gateway-poc/services/api-gateway/stream"
+	"github.com/example/payment-gateway-poc/services/api-gateway/transcode"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+	"github.com/example/payment-gateway-poc/pkg/server"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
 	m "github.com/example/payment-gateway-poc/pkg/metrics"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	paymentsv1 "github.com/example/payment-gateway-poc/proto/gen/payments/v1"
+	riskv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
+	walletv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
 )
 
 const serviceName = "api-gateway"
 
 func main() {
-	grpcClients, err := clients.NewGRPC()
+	cfg := config.DefaultGateway()
+	config.MustLoad(serviceName, &cfg)
+
+	// listener gRPC internal (server.grpc_addr): FxAdminService, di belakang
+	// mTLS (identitas ops) dan token ber-role + audit log
+	srv, err := server.New(server.Options{
+		Name:   serviceName,
+		Config: cfg.Server,
+		TLS:    cfg.TLS,
+		Unary:  []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+	})
+	if err != nil {
+		logging.Fatal("init server", "error", err)
+	}
+	if srv.GRPC != nil {
+		fxv1.RegisterFxAdminServiceServer(srv.GRPC, admin.NewAdminServer(cfg.Upstreams.Fx))
+	}
+
+	grpcClients, err := clients.NewGRPC(cfg.Upstreams, cfg.TLS, cfg.Clients)
 	if err != nil {
-		log.Fatalf("init grpc clients: %v", err)
+		logging.Fatal("init grpc clients", "error", err)
 	}
 	defer grpcClients.Close()
 
+	st, err := newStores(cfg)
+	if err != nil {
+		logging.Fatal("init stores", "error", err)
+	}
+	authMode, err := auth.ParseMode(cfg.Auth.Mode)
+	if err != nil {
+		logging.Fatal("auth mode", "error", err)
+	}
+	verifier := &auth.Verifier{
+		Keys:      st.keys,
+		Nonces:    st.nonces,
+		Mode:      authMode,
+		Tolerance: cfg.Auth.ClockSkew,
+		Public:    publicRoute,
+	}
+
+	limiter, err := newLimiter(cfg.RateLimit, st.limits)
+	if err != nil {
+		logging.Fatal("rate limits", "error", err)
+	}
+	spec, err := openapi.Load()
+	if err != nil {
+		logging.Fatal("load openapi spec", "error", err)
+	}
+
 	r := mux.NewRouter()
+	// span per request (traceparent dari client bila ada) sebelum yang lain
+	r.Use(tracing.Middleware(serviceName))
+	// correlation id (X-Correlation-ID) + access log, dengan trace_id span di atas
+	r.Use(logging.Middleware)
+	// auth dulu supaya metricsMiddleware dan limiter bisa melihat merchant-nya
+	r.Use(verifier.Middleware)
 	r.Use(metricsMiddleware)
+	if limiter != nil {
+		r.Use(limiter.Middleware)
+	}
+	// kontrak openapi.json: request invalid → 400 per field; pelanggaran
+	// response hanya dicatat (OPENAPI_VALIDATE_RESPONSES=false mematikan)
+	r.Use((&openapi.Validator{
+		Doc:       spec,
+		Responses: cfg.OpenAPIValidateResponses,
+	}).Middleware)
 
 	// metrics & health
 	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
-	r.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"ok":      true,
-			"service": serviceName,
-			"ts":      time.Now().UTC(),
-		})
-	}).Methods(http.MethodGet)
+	r.Handle("/openapi.json", spec).Methods(http.MethodGet)
+	// /healthz = /readyz: 503 bila DB, Kafka, atau upstream gRPC tidak sehat
+	r.HandleFunc("/healthz", srv.Readyz).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", srv.Readyz).Methods(http.MethodGet)
+	r.HandleFunc("/livez", srv.Livez).Methods(http.MethodGet)
+	if st.db != nil {
+		srv.Check("postgres", st.db.Ping)
+	}
+	for _, name := range []string{
+		fxv1.FxService_ServiceDesc.ServiceName,
+		walletv1.WalletService_ServiceDesc.ServiceName,
+		riskv1.RiskService_ServiceDesc.ServiceName,
+		paymentsv1.PaymentsService_ServiceDesc.ServiceName,
+	} {
+		srv.Check(name, health.GRPC(grpcClients.Conns[name], name))
+	}
 
 	// API
-	bus := queue.New(
-		strings.Split(getenv("KAFKA_BROKERS", "kafka:9092"), ","),
-		getenv("KAFKA_REQ_TOPIC", "payments.request"),
-		getenv("KAFKA_RES_TOPIC", "payments.result"),
-	)
+	var bus queue.Bus
+	switch cfg.QueueDriver {
+	case "memory":
+		// tanpa Kafka: worker jalan di proses yang sama
+		mem := queue.NewMemory(cfg.Kafka.RequestTopic)
+		if err := startEmbeddedWorker(mem, cfg.Database.URL); err != nil {
+			logging.Fatal("embedded worker", "error", err)
+		}
+		bus = mem
+	default:
+		kb := queue.NewKafka(cfg.Kafka.Brokers, cfg.Kafka.RequestTopic, cfg.Kafka.ResultTopic)
+		kb.ResultGroup = cfg.ResultGroup
+		kb.StartResultConsumer()
+		bus = kb
+		srv.Check("kafka", health.Kafka(cfg.Kafka.Brokers, cfg.Kafka.RequestTopic, cfg.Kafka.ResultTopic))
+	}
+	defer bus.Close()
 	r.HandleFunc("/api/random-accounts", handlers.RandomAccountsHandler(grpcClients.Wallet)).Methods(http.MethodGet)
-	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
-		Fx:     grpcClients.Fx,
-		Wallet: grpcClients.Wallet,
-		Risk:   grpcClients.Risk,
-		Bus:    bus,
-	})).Methods(http.MethodPost)
+	payments := st.payments
+	// retry dengan idempotency_key yang sama → response pertama
+	r.Handle("/api/payments", handlers.Idempotent(st.idempotency, 10*time.Second, handlers.PaymentsHandler(handlers.Deps{
+		Fx:        grpcClients.Fx,
+		Wallet:    grpcClients.Wallet,
+		Risk:      grpcClients.Risk,
+		Bus:       bus,
+		Store:     payments,
+		Merchants: st.merchants,
+	}))).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", handlers.PaymentStatusHandler(payments)).Methods(http.MethodGet)
+
+	// status real-time (SSE, fallback WebSocket) dari payments.result
+	hub := stream.NewHub()
+	hub.AllowedOrigins = cfg.CORSAllowedOrigins
+	defer bus.WatchResults(hub.PublishResult)()
+	r.HandleFunc("/api/payments/{id}/events", handlers.PaymentEventsHandler(payments, hub)).Methods(http.MethodGet)
+	r.HandleFunc("/api/accounts/{id}/events", handlers.AccountEventsHandler(hub, st.merchants)).Methods(http.MethodGet)
+	handlers.WebhookHandlers{Store: st.webhooks, AllowPrivate: cfg.WebhookAllowPrivate}.Register(r)
+	adminToken := cfg.Auth.AdminToken
+	handlers.MerchantHandlers{Store: st.merchants, AdminToken: adminToken}.Register(r)
+	handlers.APIKeyHandlers{
+		Keys:        st.keys,
+		Merchants:   st.merchants,
+		AdminToken:  adminToken,
+		RotateGrace: cfg.Auth.KeyRotationGrace,
+	}.Register(r)
+	// level log gateway saat runtime (service lain: /loglevel di metrics_addr)
+	r.Handle("/api/admin/log-level", auth.AdminOnly(adminToken, logging.LevelHandler())).
+		Methods(http.MethodGet, http.MethodPut)
+
+	// REST /v1 hasil transcoding anotasi google.api.http di proto/gen
+	routes, err := transcode.Routes(grpcClients.Conns)
+	if err != nil {
+		logging.Fatal("transcode routes", "error", err)
+	}
+	transcode.Mount(r, routes, transcode.Options{
+		Timeout: cfg.V1RPCTimeout,
+		Wrap: func(rt *transcode.Route, h http.Handler) http.Handler {
+			if adminRPCs[rt.RPC] {
+				return auth.AdminOnly(adminToken, h)
+			}
+			return auth.MerchantOrAdmin(adminToken, h)
+		},
+	})
+	if err := spec.Extend(transcode.OpenAPI(routes, v1Security)); err != nil {
+		logging.Fatal("extend openapi spec", "error", err)
+	}
 
 	// static
 	staticDir := "./static"
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
-	addr := getenv("HTTP_ADDR", ":8080")
-	handler := cors.AllowAll().Handler(r)
+	// gRPC native (h2c) dan gRPC-Web di port yang sama: PaymentsService
+	// publik, lewat signature dan rate limit yang sama dengan REST; batas
+	// account diputuskan di interceptor limiter setelah request di-decode
+	unary := []grpc.UnaryServerInterceptor{logging.UnaryServerInterceptor()}
+	if limiter != nil {
+		unary = append(unary, limiter.UnaryServerInterceptor())
+	}
+	gs := grpc.NewServer(tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor()))
+	paymentsv1.RegisterPaymentsServiceServer(gs, &handlers.PaymentsFacade{
+		Upstream:  grpcClients.Payments,
+		Merchants: st.merchants,
+	})
+	healthpb.RegisterHealthServer(gs, srv.Health)
+	var grpcHandler http.Handler = grpcweb.Wrap(gs)
+	if limiter != nil {
+		grpcHandler = limiter.Middleware(grpcHandler)
+	}
+	grpcHandler = grpcweb.Status(verifier.Middleware(grpcHandler))
+	// koneksi h2c di-hijack: drain lewat srv.Drained, bukan GracefulStop
+	grpcHandler = srv.Drained(grpcHandler)
+	srv.OnStop(func(context.Context) error { gs.Stop(); return nil })
+
+	addr := cfg.HTTPAddr
+	handler := grpcweb.Mux(r, grpcHandler)
+	if origins := cfg.CORSAllowedOrigins; len(origins) > 0 {
+		handler = cors.New(cors.Options{
+			AllowedOrigins: origins,
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete},
+			AllowedHeaders: []string{"Content-Type", "Prefer", "Authorization",
+				auth.HeaderKeyID, auth.HeaderTimestamp, auth.HeaderNonce, auth.HeaderSignature,
+				"X-Grpc-Web", "X-User-Agent", "Grpc-Timeout", logging.HeaderCorrelationID},
+			ExposedHeaders: []string{"Location", "Retry-After", "Idempotent-Replayed", "Preference-Applied", logging.HeaderCorrelationID,
+				"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"},
+		}).Handler(handler)
+	}
 
-	log.Printf("%s listening at %s", serviceName, addr)
-	log.Fatal(http.ListenAndServe(addr, handler))
+	srv.HTTP(addr, handler)
+	slog.Info("listening (HTTP, gRPC, gRPC-Web)", "addr", addr)
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
+	}
 }
 
 /*************** Metrics middleware ***************/
@@ -78,6 +260,9 @@ func (s *statusRecorder) WriteHeader(code int) {
 	s.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap: supaya http.ResponseController bisa Flush (SSE) dan Hijack (WebSocket).
+func (s *statusRecorder) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
 func metricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/metrics" {
@@ -94,12 +279,124 @@ func metricsMiddleware(next http.Handler) http.Handler {
 		}
 		m.IncRequest(serviceName, statusLabel, r.Method)
 		m.ObserveDuration(serviceName, statusLabel, time.Since(start).Seconds())
+		if merchant, ok := auth.MerchantID(r.Context()); ok {
+			m.IncMerchantRequest(serviceName, merchant, statusLabel)
+		}
 	})
 }
 
-func getenv(k, d string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+// publicRoute: UI statis, health (termasuk grpc.health.v1), metrics, dan data demo tidak perlu
+// signature; /api/admin/ memakai ADMIN_TOKEN. /v1/ dengan Authorization
+// (ADMIN_TOKEN, dicek per route) juga dilewatkan, selain itu wajib signature.
+func publicRoute(r *http.Request) bool {
+	p := r.URL.Path
+	if grpcweb.IsGRPC(r) || grpcweb.IsGRPCWeb(r) {
+		// PaymentsService gRPC: signature seperti /api/; health check publik
+		return strings.HasPrefix(p, "/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+	}
+	if strings.HasPrefix(p, "/v1/") {
+		return r.Header.Get("Authorization") != ""
+	}
+	return !strings.HasPrefix(p, "/api/") ||
+		p == "/api/random-accounts" ||
+		strings.HasPrefix(p, "/api/admin/")
+}
+
+// adminRPCs: RPC /v1 yang mengubah state, hanya untuk ADMIN_TOKEN. RPC lain
+// (saldo, konversi, evaluasi risk) juga boleh dipanggil merchant.
+var adminRPCs = map[string]bool{
+	walletv1.WalletService_Reserve_FullMethodName:           true,
+	walletv1.WalletService_Capture_FullMethodName:           true,
+	fxv1.FxService_UpdateRates_FullMethodName:               true,
+	paymentsv1.PaymentsService_CreatePayment_FullMethodName: true,
+	paymentsv1.PaymentsService_LogAndSettle_FullMethodName:  true,
+}
+
+func v1Security(rt *transcode.Route) []string {
+	if adminRPCs[rt.RPC] {
+		return []string{"adminToken"}
+	}
+	return []string{"merchantSignature", "adminToken"}
+}
+
+// newLimiter: RATE_LIMITS (lihat ratelimit.ParseRules), "off" untuk
+// mematikan; RATE_LIMIT_TRUST_XFF=true bila gateway di belakang LB, dengan
+// RATE_LIMIT_TRUSTED_HOPS = jumlah proxy di depannya.
+func newLimiter(cfg config.RateLimit, s ratelimit.Store) (*ratelimit.Limiter, error) {
+	spec := cfg.Rules
+	if spec == "" {
+		spec = ratelimit.DefaultRules
+	}
+	if spec == "off" {
+		return nil, nil
+	}
+	rules, err := ratelimit.ParseRules(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &ratelimit.Limiter{
+		Rules:          rules,
+		Store:          s,
+		TrustForwarded: cfg.TrustForwarded,
+		TrustedHops:    cfg.TrustedHops,
+	}, nil
+}
+
+type stores struct {
+	payments    store.PaymentStore
+	webhooks    webhook.Store
+	idempotency idempotency.Store
+	keys        auth.KeyStore
+	nonces      auth.NonceCache
+	limits      ratelimit.Store
+	merchants   merchant.Store
+	db          *pgxpool.Pool // nil untuk store memory
+}
+
+// newStores: PAYMENT_STORE=postgres agar status bisa di-poll dari replica
+// mana pun, endpoint webhook terlihat oleh webhook-worker, dan idempotency
+// key, API key, nonce, rate limit, serta registry merchant berlaku lintas
+// replica; default memory (satu instance, webhook tidak terkirim, payment
+// 202 yang result-nya datang saat gateway restart tetap PENDING). Registry
+// merchant memory tetap menulis kepemilikan akun ke wallet_accounts di
+// database.url, karena wallet-grpc dan worker men-scope saldo dari sana.
+func newStores(cfg config.Gateway) (stores, error) {
+	ctx := context.Background()
+	idemCfg := idempotency.Config{TTL: cfg.IdempotencyTTL}
+	if cfg.Store != "postgres" {
+		// pool lazy: tanpa Postgres gateway tetap jalan, hanya assign akun
+		// merchant yang gagal
+		ledger, err := pgxpool.New(ctx, cfg.Database.URL)
+		if err != nil {
+			return stores{}, fmt.Errorf("connect wallet ledger: %w", err)
+		}
+		return stores{store.NewMemory(cfg.PaymentTTL), webhook.NewMemory(), idempotency.NewMemory(idemCfg),
+			auth.NewMemoryKeys(), auth.NewMemoryNonces(), ratelimit.NewMemory(),
+			merchant.NewMemory(merchant.NewWalletLedger(ledger)), nil}, nil
+	}
+	pool, err := pgxpool.New(ctx, cfg.Database.URL)
+	if err != nil {
+		return stores{}, fmt.Errorf("connect db: %w", err)
+	}
+	st := stores{db: pool}
+	if st.payments, err = store.NewPostgres(ctx, pool); err != nil {
+		return stores{}, err
+	}
+	if st.webhooks, err = webhook.NewPostgres(ctx, pool); err != nil {
+		return stores{}, err
+	}
+	if st.idempotency, err = idempotency.NewPostgres(ctx, pool, idemCfg); err != nil {
+		return stores{}, err
+	}
+	if st.keys, err = auth.NewPostgresKeys(ctx, pool); err != nil {
+		return stores{}, err
+	}
+	st.nonces = auth.NewPostgresNonces(pool)
+	if st.limits, err = ratelimit.NewPostgres(ctx, pool); err != nil {
+		return stores{}, err
+	}
+	if st.merchants, err = merchant.NewPostgres(ctx, pool); err != nil {
+		return stores{}, err
 	}
-	return d
+	return st, nil
 }
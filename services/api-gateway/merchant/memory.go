@@ -0,0 +1,97 @@
+// services/api-gateway/merchant/memory.go
+package merchant
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory: registry in-process. Akun yang belum pernah di-assign dianggap
+// akun bersama. Assign juga ditulis ke ledger (wallet_accounts), yang
+// sekaligus memastikan akunnya ada.
+type Memory struct {
+	ledger Ledger
+
+	mu        sync.RWMutex
+	merchants map[string]Merchant
+	owners    map[string]string // account_id → merchant_id
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory(ledger Ledger) *Memory {
+	return &Memory{ledger: ledger, merchants: map[string]Merchant{}, owners: map[string]string{}}
+}
+
+func (s *Memory) Create(_ context.Context, m *Merchant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.merchants[m.ID]; ok {
+		return ErrExists
+	}
+	m.CreatedAt = time.Now().UTC()
+	s.merchants[m.ID] = *m
+	return nil
+}
+
+func (s *Memory) Get(_ context.Context, id string) (*Merchant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.merchants[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &m, nil
+}
+
+func (s *Memory) List(_ context.Context) ([]Merchant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Merchant, 0, len(s.merchants))
+	for _, m := range s.merchants {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (s *Memory) AssignAccount(ctx context.Context, merchantID, accountID string) error {
+	s.mu.RLock()
+	_, ok := s.merchants[merchantID]
+	owner := s.owners[accountID]
+	s.mu.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+	if owner != "" && owner != merchantID {
+		return ErrAccountTaken
+	}
+	if err := s.ledger.AssignAccount(ctx, merchantID, accountID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owners[accountID] = merchantID
+	return nil
+}
+
+func (s *Memory) AccountOwner(_ context.Context, accountID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.owners[accountID], nil
+}
+
+func (s *Memory) Accounts(_ context.Context, merchantID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []string
+	for acc, owner := range s.owners {
+		if owner == merchantID {
+			out = append(out, acc)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
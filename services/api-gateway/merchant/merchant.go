@@ -0,0 +1,93 @@
+// services/api-gateway/merchant/merchant.go
+// Package merchant: registry merchant (tenant). Merchant memiliki akun
+// wallet, mata uang settlement, dan fee plan; id merchant dipakai sebagai
+// tenant id di seluruh gateway, wallet, dan payment record.
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound        = errors.New("merchant: not found")
+	ErrExists          = errors.New("merchant: already exists")
+	ErrAccountNotFound = errors.New("merchant: account not found")
+	ErrAccountTaken    = errors.New("merchant: account owned by another merchant")
+	ErrInvalid         = errors.New("merchant: invalid")
+)
+
+// FeePlan: fee per payment, dihitung dari amount IDR dan dicatat di payment
+// record.
+type FeePlan struct {
+	Name       string `json:"name"`
+	PercentBps int64  `json:"percent_bps"` // 100 bps = 1%
+	FixedIDR   int64  `json:"fixed_idr"`
+}
+
+func (f FeePlan) Fee(amountIDR int64) int64 {
+	return amountIDR*f.PercentBps/10_000 + f.FixedIDR
+}
+
+type Merchant struct {
+	ID                 string    `json:"merchant_id"`
+	Name               string    `json:"name"`
+	SettlementCurrency string    `json:"settlement_currency"`
+	FeePlan            FeePlan   `json:"fee_plan"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{2,64}$`)
+
+// Normalize mengisi default (id acak, IDR) dan memvalidasi field.
+func (m *Merchant) Normalize() error {
+	if m.ID == "" {
+		m.ID = "m_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:16]
+	}
+	if !idPattern.MatchString(m.ID) {
+		return fmt.Errorf("%w: merchant_id must match %s", ErrInvalid, idPattern)
+	}
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("%w: name required", ErrInvalid)
+	}
+	m.SettlementCurrency = strings.ToUpper(strings.TrimSpace(m.SettlementCurrency))
+	switch m.SettlementCurrency {
+	case "":
+		m.SettlementCurrency = "IDR"
+	case "IDR", "USD", "SGD":
+	default:
+		return fmt.Errorf("%w: unsupported settlement_currency %q", ErrInvalid, m.SettlementCurrency)
+	}
+	if m.FeePlan.PercentBps < 0 || m.FeePlan.PercentBps > 10_000 || m.FeePlan.FixedIDR < 0 {
+		return fmt.Errorf("%w: fee_plan out of range", ErrInvalid)
+	}
+	return nil
+}
+
+type Store interface {
+	Create(ctx context.Context, m *Merchant) error
+	Get(ctx context.Context, id string) (*Merchant, error)
+	List(ctx context.Context) ([]Merchant, error)
+	// AssignAccount menjadikan akun milik merchant; akun milik merchant lain
+	// → ErrAccountTaken.
+	AssignAccount(ctx context.Context, merchantID, accountID string) error
+	// AccountOwner: merchant pemilik akun, "" untuk akun bersama (demo).
+	AccountOwner(ctx context.Context, accountID string) (string, error)
+	Accounts(ctx context.Context, merchantID string) ([]string, error)
+}
+
+// Ledger: kepemilikan akun di wallet_accounts.merchant_id, kolom yang
+// dibaca wallet-grpc dan worker saat settle. Registry apa pun harus menulis
+// ke sini; kalau tidak, payment merchant ditolak worker sebagai akun tidak
+// ditemukan.
+type Ledger interface {
+	// AssignAccount: ErrAccountNotFound bila akun tidak ada di wallet,
+	// ErrAccountTaken bila milik merchant lain.
+	AssignAccount(ctx context.Context, merchantID, accountID string) error
+}
@@ -0,0 +1,156 @@
+// services/api-gateway/merchant/postgres.go
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres: registry bersama. Kepemilikan akun disimpan di
+// wallet_accounts.merchant_id, kolom yang sama yang dipakai wallet-grpc dan
+// worker untuk membatasi query per tenant.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*Postgres)(nil)
+
+func NewPostgres(ctx context.Context, pool *pgxpool.Pool) (*Postgres, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+func (s *Postgres) Create(ctx context.Context, m *Merchant) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO merchants (merchant_id, name, settlement_currency, fee_plan_name, fee_percent_bps, fee_fixed_idr)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, m.ID, m.Name, m.SettlementCurrency, m.FeePlan.Name, m.FeePlan.PercentBps, m.FeePlan.FixedIDR).Scan(&m.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrExists
+	}
+	if err != nil {
+		return fmt.Errorf("insert merchant: %w", err)
+	}
+	return nil
+}
+
+const selectMerchant = `
+	SELECT merchant_id, name, settlement_currency, fee_plan_name, fee_percent_bps, fee_fixed_idr, created_at
+	FROM merchants`
+
+func scanMerchant(row pgx.Row) (*Merchant, error) {
+	var m Merchant
+	err := row.Scan(&m.ID, &m.Name, &m.SettlementCurrency, &m.FeePlan.Name, &m.FeePlan.PercentBps, &m.FeePlan.FixedIDR, &m.CreatedAt)
+	return &m, err
+}
+
+func (s *Postgres) Get(ctx context.Context, id string) (*Merchant, error) {
+	m, err := scanMerchant(s.pool.QueryRow(ctx, selectMerchant+` WHERE merchant_id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query merchant: %w", err)
+	}
+	return m, nil
+}
+
+func (s *Postgres) List(ctx context.Context) ([]Merchant, error) {
+	rows, err := s.pool.Query(ctx, selectMerchant+` ORDER BY merchant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query merchants: %w", err)
+	}
+	defer rows.Close()
+	var out []Merchant
+	for rows.Next() {
+		m, err := scanMerchant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan merchant: %w", err)
+		}
+		out = append(out, *m)
+	}
+	return out, rows.Err()
+}
+
+func (s *Postgres) AssignAccount(ctx context.Context, merchantID, accountID string) error {
+	if _, err := s.Get(ctx, merchantID); err != nil {
+		return err
+	}
+	return NewWalletLedger(s.pool).AssignAccount(ctx, merchantID, accountID)
+}
+
+func (s *Postgres) AccountOwner(ctx context.Context, accountID string) (string, error) {
+	return accountOwner(ctx, s.pool, accountID)
+}
+
+// WalletLedger: Ledger di tabel wallet_accounts; dipakai juga oleh registry
+// memory supaya kepemilikan akun sampai ke wallet-grpc dan worker.
+type WalletLedger struct {
+	pool *pgxpool.Pool
+}
+
+var _ Ledger = (*WalletLedger)(nil)
+
+func NewWalletLedger(pool *pgxpool.Pool) *WalletLedger { return &WalletLedger{pool: pool} }
+
+func (l *WalletLedger) AssignAccount(ctx context.Context, merchantID, accountID string) error {
+	cmd, err := l.pool.Exec(ctx, `
+		UPDATE wallet_accounts SET merchant_id = $1, updated_at = now()
+		WHERE account_id = $2 AND merchant_id IN ('', $1)
+	`, merchantID, accountID)
+	if err != nil {
+		return fmt.Errorf("assign account: %w", err)
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil
+	}
+	if _, err := accountOwner(ctx, l.pool, accountID); err != nil {
+		return err
+	}
+	return ErrAccountTaken
+}
+
+func accountOwner(ctx context.Context, pool *pgxpool.Pool, accountID string) (string, error) {
+	var owner string
+	err := pool.QueryRow(ctx, `SELECT merchant_id FROM wallet_accounts WHERE account_id = $1`, accountID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrAccountNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("query account owner: %w", err)
+	}
+	return owner, nil
+}
+
+func (s *Postgres) Accounts(ctx context.Context, merchantID string) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `SELECT account_id FROM wallet_accounts WHERE merchant_id = $1 ORDER BY account_id`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("query accounts: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS merchants (
+  merchant_id         TEXT PRIMARY KEY,
+  name                TEXT        NOT NULL,
+  settlement_currency TEXT        NOT NULL DEFAULT 'IDR',
+  fee_plan_name       TEXT        NOT NULL DEFAULT '',
+  fee_percent_bps     BIGINT      NOT NULL DEFAULT 0,
+  fee_fixed_idr       BIGINT      NOT NULL DEFAULT 0,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE IF EXISTS wallet_accounts ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+`)
+	return err
+}
@@ -0,0 +1,269 @@
+// services/api-gateway/openapi/middleware.go
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+const maxBody = 1 << 20
+
+var (
+	rejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "openapi",
+		Name:      "requests_rejected_total",
+		Help:      "Request yang ditolak karena tidak sesuai openapi.json.",
+	}, []string{"operation"})
+	responseViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "openapi",
+		Name:      "response_violations_total",
+		Help:      "Response gateway yang tidak sesuai openapi.json.",
+	}, []string{"operation", "status"})
+)
+
+// Validator: middleware mux. Request ke operasi yang terdokumentasi
+// divalidasi (parameter path/query dan body JSON, field tak dikenal ditolak)
+// → 400 dengan daftar field. Route yang tidak ada di dokumen (UI statis)
+// dilewatkan apa adanya.
+type Validator struct {
+	Doc *Document
+	// Responses: validasi juga response JSON. Response tetap dikirim;
+	// pelanggaran dicatat lewat OnResponseError (default: log) dan metric.
+	Responses       bool
+	OnResponseError func(r *http.Request, status int, errs []FieldError)
+}
+
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		op := v.Doc.Operation(r.Method, tpl)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		status, errs := v.checkRequest(op, r)
+		if len(errs) > 0 {
+			rejected.WithLabelValues(op.OperationID).Inc()
+			perrors.Write(w, r, InvalidRequest(status, errs))
+			return
+		}
+		if !v.Responses {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if errs := checkResponse(op, rec); len(errs) > 0 {
+			responseViolations.WithLabelValues(op.OperationID, strconv.Itoa(rec.status)).Inc()
+			if v.OnResponseError != nil {
+				v.OnResponseError(r, rec.status, errs)
+			} else {
+				slog.WarnContext(r.Context(), "openapi: response violates spec",
+					"method", r.Method, "route", tpl, "status", rec.status, "errors", errs)
+			}
+		}
+	})
+}
+
+func (v *Validator) checkRequest(op *Operation, r *http.Request) (int, []FieldError) {
+	var errs []FieldError
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var (
+			raw     string
+			present bool
+		)
+		switch p.In {
+		case "path":
+			raw, present = vars[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		default:
+			continue // header: informatif saja
+		}
+		if !present {
+			if p.Required {
+				errs = append(errs, FieldError{Field: p.Name, Reason: "is required"})
+			}
+			continue
+		}
+		if p.Schema != nil {
+			for _, e := range p.Schema.Validate(paramValue(p.Schema, raw)) {
+				errs = append(errs, FieldError{Field: p.Name, Reason: e.Reason})
+			}
+		}
+	}
+
+	rb := op.RequestBody
+	if rb == nil {
+		return http.StatusBadRequest, errs
+	}
+	mt, ok := rb.Content["application/json"]
+	if !ok || mt.Schema == nil {
+		return http.StatusBadRequest, errs
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil {
+		return http.StatusBadRequest, append(errs, FieldError{Field: "body", Reason: "unreadable"})
+	}
+	if len(body) > maxBody {
+		return http.StatusRequestEntityTooLarge, append(errs, FieldError{Field: "body", Reason: "must be at most 1 MiB"})
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if rb.Required {
+			errs = append(errs, FieldError{Field: "body", Reason: "is required"})
+		}
+		return http.StatusBadRequest, errs
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != "application/json" {
+			return http.StatusUnsupportedMediaType, append(errs, FieldError{Field: "Content-Type", Reason: "must be application/json"})
+		}
+	}
+	doc, err := decode(body)
+	if err != nil {
+		return http.StatusBadRequest, append(errs, FieldError{Field: "body", Reason: "malformed JSON: " + err.Error()})
+	}
+	return http.StatusBadRequest, append(errs, mt.Schema.Validate(doc)...)
+}
+
+func checkResponse(op *Operation, rec *recorder) []FieldError {
+	resp, ok := op.response(rec.status)
+	if !ok {
+		return []FieldError{{Field: "status", Reason: "undocumented status " + strconv.Itoa(rec.status)}}
+	}
+	if !rec.json || rec.truncated {
+		return nil
+	}
+	mt, ok := resp.Content[rec.mediaType]
+	if !ok {
+		if len(resp.Content) == 0 {
+			return nil
+		}
+		return []FieldError{{Field: "Content-Type", Reason: "undocumented media type " + rec.mediaType}}
+	}
+	if mt.Schema == nil {
+		return nil
+	}
+	doc, err := decode(rec.body.Bytes())
+	if err != nil {
+		return []FieldError{{Field: "body", Reason: "malformed JSON: " + err.Error()}}
+	}
+	return mt.Schema.Validate(doc)
+}
+
+// decode: satu nilai JSON, angka sebagai json.Number; sisa data ditolak.
+func decode(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// paramValue: string parameter → tipe schema-nya supaya bisa divalidasi
+// dengan Schema yang sama.
+func paramValue(s *Schema, raw string) any {
+	if s.ref != nil {
+		s = s.ref
+	}
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// recorder menyalin body JSON (maks 1 MiB) untuk divalidasi setelah handler
+// selesai; SSE/WebSocket tetap jalan lewat Unwrap.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	json        bool
+	mediaType   string // application/json atau application/problem+json
+	truncated   bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = code
+		r.mediaType, _, _ = mime.ParseMediaType(r.Header().Get("Content-Type"))
+		r.json = r.mediaType == "application/json" || strings.HasSuffix(r.mediaType, "+json")
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.json && !r.truncated {
+		if r.body.Len()+len(b) > maxBody {
+			r.truncated = true
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// InvalidRequest: hasil validasi request (status dari checkRequest, juga
+// dipakai transcode) sebagai problem+json dengan daftar field.
+func InvalidRequest(status int, errs []FieldError) error {
+	code := perrors.InvalidArgument
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		code = perrors.PayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		code = perrors.UnsupportedMedia
+	}
+	msg := make([]string, len(errs))
+	for i, e := range errs {
+		msg[i] = e.String()
+	}
+	return perrors.New(code, "invalid request: %s", strings.Join(msg, "; ")).WithFields(errs)
+}
@@ -0,0 +1,235 @@
+// services/api-gateway/openapi/openapi.go
+// Package openapi: dokumen OpenAPI 3 untuk semua route REST gateway
+// (openapi.json, di-embed), disajikan di /openapi.json dan dipakai untuk
+// memvalidasi request dan response. Dokumen ini sumber kebenaran kontrak:
+// route baru di main.go harus ditambahkan ke sana (lihat openapi_test.go).
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+type Document struct {
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema    `json:"schemas"`
+		Parameters map[string]*Parameter `json:"parameters"`
+		Responses  map[string]*Response  `json:"responses"`
+	} `json:"components"`
+
+	raw []byte
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref,omitempty"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path | query | header
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref     string                `json:"$ref,omitempty"`
+	Content map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Load: dokumen yang di-embed di binary.
+func Load() (*Document, error) { return Parse(spec) }
+
+// Parse memuat dokumen dan me-resolve semua $ref; ref yang tidak ada atau
+// pattern yang tidak valid → error saat start, bukan saat request.
+func Parse(b []byte) (*Document, error) {
+	d := &Document{raw: b}
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("openapi: parse: %w", err)
+	}
+	resolveSchemas := func(root *Schema) error {
+		return root.walk(func(s *Schema) error {
+			if s.Ref != "" {
+				target, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+				if !ok || !strings.HasPrefix(s.Ref, "#/components/schemas/") {
+					return fmt.Errorf("openapi: unresolved $ref %s", s.Ref)
+				}
+				s.ref = target
+			}
+			if s.Pattern != "" {
+				re, err := regexp.Compile(s.Pattern)
+				if err != nil {
+					return fmt.Errorf("openapi: pattern %q: %w", s.Pattern, err)
+				}
+				s.pattern = re
+			}
+			return nil
+		})
+	}
+	for _, s := range d.Components.Schemas {
+		if err := resolveSchemas(s); err != nil {
+			return nil, err
+		}
+	}
+	for path, item := range d.Paths {
+		for method, op := range item.operations() {
+			for i, p := range op.Parameters {
+				if p.Ref != "" {
+					target, ok := d.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+					if !ok {
+						return nil, fmt.Errorf("openapi: %s %s: unresolved $ref %s", method, path, p.Ref)
+					}
+					op.Parameters[i] = target
+				}
+			}
+			for code, resp := range op.Responses {
+				if resp.Ref != "" {
+					target, ok := d.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+					if !ok {
+						return nil, fmt.Errorf("openapi: %s %s: unresolved $ref %s", method, path, resp.Ref)
+					}
+					op.Responses[code] = target
+				}
+			}
+		}
+	}
+	var media []*MediaType
+	for _, r := range d.Components.Responses {
+		for _, mt := range r.Content {
+			media = append(media, mt)
+		}
+	}
+	for _, item := range d.Paths {
+		for _, op := range item.operations() {
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					media = append(media, mt)
+				}
+			}
+			for _, r := range op.Responses {
+				for _, mt := range r.Content {
+					media = append(media, mt)
+				}
+			}
+		}
+	}
+	for _, p := range d.Components.Parameters {
+		if err := resolveSchemas(p.Schema); err != nil {
+			return nil, err
+		}
+	}
+	for _, mt := range media {
+		if err := resolveSchemas(mt.Schema); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (p *PathItem) operations() map[string]*Operation {
+	ops := map[string]*Operation{}
+	for method, op := range map[string]*Operation{
+		http.MethodGet: p.Get, http.MethodPost: p.Post, http.MethodPut: p.Put,
+		http.MethodPatch: p.Patch, http.MethodDelete: p.Delete,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// Operation: operasi untuk template path gorilla/mux (sintaks {param} sama
+// dengan OpenAPI) dan method; nil bila tidak terdokumentasi.
+func (d *Document) Operation(method, pathTemplate string) *Operation {
+	item, ok := d.Paths[pathTemplate]
+	if !ok {
+		return nil
+	}
+	return item.operations()[method]
+}
+
+// Extend menambahkan path dan schema (mis. hasil transcode.OpenAPI) ke
+// dokumen yang disajikan di /openapi.json. Hanya dokumentasi: operasi
+// tambahan tidak divalidasi Validator, pemiliknya memvalidasi sendiri.
+func (d *Document) Extend(paths, schemas map[string]any) error {
+	var doc map[string]any
+	if err := json.Unmarshal(d.raw, &doc); err != nil {
+		return fmt.Errorf("openapi: extend: %w", err)
+	}
+	merge := func(parent map[string]any, key string, add map[string]any) error {
+		dst, _ := parent[key].(map[string]any)
+		if dst == nil {
+			dst = map[string]any{}
+			parent[key] = dst
+		}
+		for k, v := range add {
+			if _, dup := dst[k]; dup {
+				return fmt.Errorf("openapi: extend: %s %q already defined", key, k)
+			}
+			dst[k] = v
+		}
+		return nil
+	}
+	components, _ := doc["components"].(map[string]any)
+	if components == nil {
+		components = map[string]any{}
+		doc["components"] = components
+	}
+	if err := merge(doc, "paths", paths); err != nil {
+		return err
+	}
+	if err := merge(components, "schemas", schemas); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("openapi: extend: %w", err)
+	}
+	d.raw = raw
+	return nil
+}
+
+// ServeHTTP: GET /openapi.json
+func (d *Document) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(d.raw)
+}
+
+// response: schema JSON untuk status tertentu, fallback ke "default".
+func (op *Operation) response(status int) (*Response, bool) {
+	if r, ok := op.Responses[fmt.Sprint(status)]; ok {
+		return r, true
+	}
+	r, ok := op.Responses["default"]
+	return r, ok
+}
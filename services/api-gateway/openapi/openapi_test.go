@@ -0,0 +1,156 @@
+// services/api-gateway/openapi/openapi_test.go
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/openapi"
+)
+
+func load(t *testing.T) *openapi.Document {
+	t.Helper()
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// Semua route yang didaftarkan handler harus ada di openapi.json.
+func TestEveryRouteDocumented(t *testing.T) {
+	doc := load(t)
+	r := mux.NewRouter()
+	handlers.WebhookHandlers{}.Register(r)
+	handlers.APIKeyHandlers{}.Register(r)
+	handlers.MerchantHandlers{}.Register(r)
+	// route yang didaftarkan langsung di main.go
+	for _, rt := range [][2]string{
+		{http.MethodGet, "/healthz"}, {http.MethodGet, "/readyz"}, {http.MethodGet, "/livez"}, {http.MethodGet, "/metrics"}, {http.MethodGet, "/openapi.json"},
+		{http.MethodGet, "/api/random-accounts"}, {http.MethodPost, "/api/payments"},
+		{http.MethodGet, "/api/payments/{id}"}, {http.MethodGet, "/api/payments/{id}/events"},
+		{http.MethodGet, "/api/accounts/{id}/events"},
+		{http.MethodGet, "/api/admin/log-level"}, {http.MethodPut, "/api/admin/log-level"},
+	} {
+		r.HandleFunc(rt[1], http.NotFound).Methods(rt[0])
+	}
+	n := 0
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil // PathPrefix subrouter
+		}
+		for _, m := range methods {
+			n++
+			if doc.Operation(m, tpl) == nil {
+				t.Errorf("%s %s missing from openapi.json", m, tpl)
+			}
+		}
+		return nil
+	})
+	if err != nil || n < 20 {
+		t.Fatalf("walk: %v (%d routes)", err, n)
+	}
+}
+
+func newServer(t *testing.T, v *openapi.Validator, h http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	r := mux.NewRouter()
+	r.Use(v.Middleware)
+	r.HandleFunc("/api/payments", h).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", h).Methods(http.MethodGet)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRequestValidation(t *testing.T) {
+	v := &openapi.Validator{Doc: load(t)}
+	srv := newServer(t, v, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	cases := []struct {
+		name, body string
+		status     int
+		fields     []string
+	}{
+		{"valid", `{"sender_id":"A","receiver_id":"B","currency":"USD","amount":10.5,"tx_date":"2025-01-02T03:04:05Z","idempotency_key":"k"}`,
+			http.StatusAccepted, nil},
+		{"unknown field", `{"sender_id":"A","receiver_id":"B","currency":"USD","amount":1,"tx_date":"2025-01-02T03:04:05+07:00","idempotency_key":"k","amout":2}`,
+			http.StatusBadRequest, []string{"amout: unknown field"}},
+		{"bad tx_date and amount", `{"sender_id":"A","receiver_id":"B","currency":"usd","amount":0,"tx_date":"02/01/2025","idempotency_key":"k"}`,
+			http.StatusBadRequest, []string{"amount: must be greater than 0", "currency: must be one of", "tx_date: must be an ISO-8601"}},
+		{"missing", `{"sender_id":"","currency":"IDR"}`,
+			http.StatusBadRequest, []string{"amount: is required", "idempotency_key: is required", "receiver_id: is required", "sender_id: must not be empty", "tx_date: is required"}},
+		{"wrong type", `{"sender_id":1,"receiver_id":"B","currency":"IDR","amount":"10","tx_date":"2025-01-02T03:04:05Z","idempotency_key":"k"}`,
+			http.StatusBadRequest, []string{"amount: must be a number", "sender_id: must be a string"}},
+		{"malformed", `{"sender_id":`, http.StatusBadRequest, []string{"body: malformed JSON"}},
+		{"trailing", `{} {}`, http.StatusBadRequest, []string{"body: malformed JSON: trailing data"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+"/api/payments", "application/json", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.status {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tc.status)
+			}
+			if tc.fields == nil {
+				return
+			}
+			var out perrors.Problem
+			if err := json.NewDecoder(resp.Body).Decode(&out); err != nil || out.Code != perrors.InvalidArgument ||
+				resp.Header.Get("Content-Type") != perrors.ContentType {
+				t.Fatalf("body: %v %+v", err, out)
+			}
+			if len(out.Fields) != len(tc.fields) {
+				t.Fatalf("fields %v, want %v", out.Fields, tc.fields)
+			}
+			for i, want := range tc.fields {
+				if !strings.HasPrefix(out.Fields[i].String(), want) {
+					t.Errorf("field %d = %q, want prefix %q", i, out.Fields[i], want)
+				}
+			}
+		})
+	}
+
+	resp, _ := http.Get(srv.URL + "/api/payments/not-a-uuid")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("path param: %d", resp.StatusCode)
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	var got []openapi.FieldError
+	v := &openapi.Validator{Doc: load(t), Responses: true,
+		OnResponseError: func(_ *http.Request, _ int, errs []openapi.FieldError) { got = errs }}
+	srv := newServer(t, v, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"SUCCESS","extra":true}`))
+	})
+	body := `{"sender_id":"A","receiver_id":"B","currency":"IDR","amount":1,"tx_date":"2025-01-02T03:04:05Z","idempotency_key":"k"}`
+	resp, err := http.Post(srv.URL+"/api/payments", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(got) != 1 || got[0].Field != "extra" {
+		t.Fatalf("status %d, violations %v", resp.StatusCode, got)
+	}
+}
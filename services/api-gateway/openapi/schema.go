@@ -0,0 +1,258 @@
+// services/api-gateway/openapi/schema.go
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+)
+
+// Schema: subset JSON Schema OpenAPI 3.0 yang dipakai openapi.json. Keyword
+// di luar subset ini diabaikan, jadi tambahkan dukungannya di sini sebelum
+// memakainya di dokumen.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+
+	ref     *Schema // hasil resolve $ref
+	pattern *regexp.Regexp
+}
+
+// FieldError: satu pelanggaran; Field berupa path JSON (fee_plan.percent_bps,
+// fields[0].reason) atau nama parameter. Sama dengan "fields" di
+// problem+json.
+type FieldError = perrors.FieldError
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate memeriksa nilai hasil decode JSON (dengan UseNumber) terhadap s.
+func (s *Schema) Validate(v any) []FieldError {
+	var errs []FieldError
+	s.validate(v, "", &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func (s *Schema) validate(v any, path string, errs *[]FieldError) {
+	if s.ref != nil {
+		s.ref.validate(v, path, errs)
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Field: fieldName(path), Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(v, path, errs)
+	}
+	alternatives := append(append([]*Schema(nil), s.AnyOf...), s.OneOf...)
+	if len(alternatives) > 0 {
+		var best []FieldError
+		for i, sub := range alternatives {
+			var e []FieldError
+			sub.validate(v, path, &e)
+			if len(e) == 0 {
+				best = nil
+				break
+			}
+			if i == 0 || len(e) < len(best) {
+				best = e
+			}
+		}
+		*errs = append(*errs, best...)
+	}
+
+	if v == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		fail("must be one of %v", s.Enum)
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, FieldError{Field: join(path, name), Reason: "is required"})
+			}
+		}
+		for name, val := range obj {
+			if p, ok := s.Properties[name]; ok {
+				p.validate(val, join(path, name), errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, FieldError{Field: join(path, name), Reason: "unknown field"})
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(item, path+"["+strconv.Itoa(i)+"]", errs)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			fail("must match %s", s.Pattern)
+		}
+		if reason := checkFormat(s.Format, str); reason != "" {
+			fail("%s", reason)
+		}
+	case "number", "integer":
+		num, ok := v.(json.Number)
+		if !ok {
+			fail("must be a %s", s.Type)
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			fail("must be a %s", s.Type)
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				fail("must be an integer")
+				return
+			}
+		}
+		if s.Minimum != nil && (f < *s.Minimum || s.ExclusiveMinimum && f == *s.Minimum) {
+			if s.ExclusiveMinimum {
+				fail("must be greater than %v", *s.Minimum)
+			} else {
+				fail("must be at least %v", *s.Minimum)
+			}
+		}
+		if s.Maximum != nil && (f > *s.Maximum || s.ExclusiveMaximum && f == *s.Maximum) {
+			if s.ExclusiveMaximum {
+				fail("must be less than %v", *s.Maximum)
+			} else {
+				fail("must be at most %v", *s.Maximum)
+			}
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// checkFormat: "" bila valid.
+func checkFormat(format, s string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an ISO-8601 date-time (e.g. 2025-01-02T03:04:05Z)"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return "must be an ISO-8601 date (e.g. 2025-01-02)"
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be an absolute URI"
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(s) {
+			return "must be a UUID"
+		}
+	}
+	return ""
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+// walk memanggil fn untuk s dan semua sub-schema (tanpa mengikuti $ref).
+func (s *Schema) walk(fn func(*Schema) error) error {
+	if s == nil {
+		return nil
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	for _, p := range s.Properties {
+		if err := p.walk(fn); err != nil {
+			return err
+		}
+	}
+	for _, group := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf, {s.Items}} {
+		for _, sub := range group {
+			if err := sub.walk(fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
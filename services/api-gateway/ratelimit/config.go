@@ -0,0 +1,130 @@
+// services/api-gateway/ratelimit/config.go
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dimension: kunci bucket.
+type Dimension string
+
+const (
+	ByMerchant Dimension = "merchant" // merchant hasil autentikasi
+	ByAccount  Dimension = "account"  // sender_id di body
+	ByIP       Dimension = "ip"
+)
+
+// DimLimit: satu batas untuk satu dimensi. Daily > 0 berarti quota harian,
+// selain itu token bucket.
+type DimLimit struct {
+	Dim   Dimension
+	Limit Limit
+	Daily int64
+}
+
+// Rule: batas untuk satu route ("POST /api/payments", template mux) atau
+// "*" untuk semua route yang tidak punya rule sendiri.
+type Rule struct {
+	Method string // "*" = semua
+	Path   string // template route mux atau "*"
+	Limits []DimLimit
+}
+
+func (r Rule) name() string {
+	if r.Method == "*" && r.Path == "*" {
+		return "*"
+	}
+	return r.Method + " " + r.Path
+}
+
+func (r Rule) matches(method, path string) bool {
+	return (r.Method == "*" || r.Method == method) && (r.Path == "*" || r.Path == path)
+}
+
+// DefaultRules: hanya pembuatan payment yang dibatasi, lewat REST, /v1,
+// maupun PaymentsService gRPC di port gateway (path = nama method gRPC).
+const DefaultRules = "POST /api/payments merchant=50/s:100 account=5/s:10 ip=20/s:40;" +
+	"POST /v1/payments merchant=50/s:100 account=5/s:10 ip=20/s:40;" +
+	"POST /payments.v1.PaymentsService/CreatePayment merchant=50/s:100 account=5/s:10 ip=20/s:40"
+
+// ParseRules: rule dipisah ";", mis.
+//
+//	POST /api/payments merchant=20/s:40 merchant=100000/d account=2/s ip=10/s;
+//	* ip=50/s:100
+//
+// Format batas: dim=N/unit[:burst]; unit s, m, h untuk token bucket (burst
+// default N), d untuk quota harian.
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, raw := range strings.Split(spec, ";") {
+		f := strings.Fields(raw)
+		if len(f) == 0 {
+			continue
+		}
+		r := Rule{Method: "*", Path: "*"}
+		switch {
+		case f[0] == "*":
+			f = f[1:]
+		case len(f) >= 2 && strings.HasPrefix(f[1], "/"):
+			r.Method, r.Path = strings.ToUpper(f[0]), f[1]
+			f = f[2:]
+		default:
+			return nil, fmt.Errorf("ratelimit: rule %q: want \"METHOD /path\" or \"*\"", strings.TrimSpace(raw))
+		}
+		for _, l := range f {
+			dl, err := parseLimit(l)
+			if err != nil {
+				return nil, fmt.Errorf("ratelimit: rule %q: %w", r.name(), err)
+			}
+			r.Limits = append(r.Limits, dl)
+		}
+		if len(r.Limits) == 0 {
+			return nil, fmt.Errorf("ratelimit: rule %q has no limits", r.name())
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseLimit(s string) (DimLimit, error) {
+	dim, val, ok := strings.Cut(s, "=")
+	if !ok {
+		return DimLimit{}, fmt.Errorf("limit %q: want dim=N/unit", s)
+	}
+	d := Dimension(dim)
+	if d != ByMerchant && d != ByAccount && d != ByIP {
+		return DimLimit{}, fmt.Errorf("limit %q: unknown dimension %q", s, dim)
+	}
+	val, burstStr, hasBurst := strings.Cut(val, ":")
+	nStr, unit, ok := strings.Cut(val, "/")
+	if !ok {
+		return DimLimit{}, fmt.Errorf("limit %q: missing /unit", s)
+	}
+	n, err := strconv.ParseFloat(nStr, 64)
+	if err != nil || n <= 0 {
+		return DimLimit{}, fmt.Errorf("limit %q: bad count", s)
+	}
+	if unit == "d" {
+		if hasBurst {
+			return DimLimit{}, fmt.Errorf("limit %q: daily quota has no burst", s)
+		}
+		return DimLimit{Dim: d, Daily: int64(n)}, nil
+	}
+	per := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}[unit]
+	if per == 0 {
+		return DimLimit{}, fmt.Errorf("limit %q: unit must be s, m, h or d", s)
+	}
+	burst := int(n)
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+			return DimLimit{}, fmt.Errorf("limit %q: bad burst", s)
+		}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return DimLimit{Dim: d, Limit: Limit{Rate: n / per.Seconds(), Burst: burst}}, nil
+}
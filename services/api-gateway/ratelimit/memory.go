@@ -0,0 +1,92 @@
+// services/api-gateway/ratelimit/memory.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type daily struct {
+	day   time.Time // awal hari UTC
+	count int64
+}
+
+// Memory: Store in-process, default untuk satu replica.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	quotas    map[string]*daily
+	lastSweep time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}, quotas: map[string]*daily{}}
+}
+
+func (s *Memory) Take(_ context.Context, key string, l Limit, now time.Time) (Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.last), l)
+	b.last = now
+	if b.tokens < 1 {
+		return Decision{RetryAfter: wait(b.tokens, l)}, nil
+	}
+	b.tokens--
+	return Decision{Allowed: true}, nil
+}
+
+func (s *Memory) Refund(_ context.Context, key string, l Limit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = min(b.tokens+1, float64(l.Burst))
+	}
+	return nil
+}
+
+func (s *Memory) AddDaily(_ context.Context, key string, n int64, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	day := nextDay(now).AddDate(0, 0, -1)
+	q, ok := s.quotas[key]
+	if !ok || !q.day.Equal(day) {
+		q = &daily{day: day}
+		s.quotas[key] = q
+	}
+	q.count += n
+	return q.count, nil
+}
+
+// sweep: buang bucket yang idle > 1 jam (pasti sudah penuh lagi) dan quota
+// hari sebelumnya, paling sering sekali per menit.
+func (s *Memory) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.last) > time.Hour {
+			delete(s.buckets, k)
+		}
+	}
+	today := nextDay(now).AddDate(0, 0, -1)
+	for k, q := range s.quotas {
+		if q.day.Before(today) {
+			delete(s.quotas, k)
+		}
+	}
+}
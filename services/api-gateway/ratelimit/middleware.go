@@ -0,0 +1,307 @@
+// services/api-gateway/ratelimit/middleware.go
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+)
+
+var (
+	rejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "gateway",
+			Name:      "ratelimit_rejected_total",
+			Help:      "Requests rejected by rate limit or daily quota",
+		},
+		[]string{"rule", "dimension", "kind"},
+	)
+	storeErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "payment",
+		Subsystem: "gateway",
+		Name:      "ratelimit_store_errors_total",
+		Help:      "Rate limit store failures (requests let through)",
+	})
+)
+
+// Limiter: middleware mux; dipasang setelah auth supaya merchant diketahui.
+//
+// Dimensi account butuh sender dari request yang sudah di-decode: REST
+// (sender_id) dan /v1 (source_account) dibaca dari body JSON, sedangkan body
+// gRPC/gRPC-Web baru bisa dibaca di server gRPC. Untuk request gRPC yang
+// rule-nya punya batas account, keputusan seluruh rule ditunda ke
+// UnaryServerInterceptor, jadi interceptor itu wajib dipasang di server gRPC
+// yang dilayani lewat Middleware.
+type Limiter struct {
+	Rules []Rule
+	Store Store
+	// TrustForwarded: ambil IP client dari X-Forwarded-For (gateway di
+	// belakang load balancer).
+	TrustForwarded bool
+	// TrustedHops: jumlah proxy tepercaya di depan gateway (default 1). IP
+	// client = entri ke-TrustedHops dari kanan X-Forwarded-For; entri di
+	// kirinya dikirim client sendiri dan tidak dipercaya.
+	TrustedHops int
+	Now         func() time.Time
+}
+
+func (l *Limiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+func (l *Limiter) rule(method, path string) (Rule, bool) {
+	for _, rule := range l.Rules {
+		if rule.matches(method, path) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil {
+				path = tmpl
+			}
+		}
+		rule, ok := l.rule(r.Method, path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		keys := map[Dimension]string{}
+		for _, dl := range rule.Limits {
+			if _, done := keys[dl.Dim]; !done {
+				keys[dl.Dim] = l.keyFor(dl.Dim, r)
+			}
+		}
+		now := l.now()
+		if _, ok := keys[ByAccount]; ok && isGRPC(r) {
+			// sender ada di frame protobuf: diputuskan di interceptor
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), pendingKey{}, &pending{rule: rule, keys: keys, now: now})))
+			return
+		}
+		if d := l.allow(r.Context(), rule, keys, now); d != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(retrySeconds(d.retry)))
+			perrors.Write(w, r, d.err())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pending: rule request gRPC yang keputusannya ditunda ke interceptor.
+type pending struct {
+	rule Rule
+	keys map[Dimension]string
+	now  time.Time
+}
+
+type pendingKey struct{}
+
+// UnaryServerInterceptor: menyelesaikan keputusan yang ditunda Middleware
+// untuk request gRPC, dengan akun = GetSourceAccount() dari request.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, ok := ctx.Value(pendingKey{}).(*pending)
+		if !ok {
+			return handler(ctx, req)
+		}
+		if in, ok := req.(interface{ GetSourceAccount() string }); ok {
+			p.keys[ByAccount] = in.GetSourceAccount()
+		}
+		if d := l.allow(ctx, p.rule, p.keys, p.now); d != nil {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retrySeconds(d.retry))))
+			return nil, d.err()
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isGRPC(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// denial: alasan penolakan allow.
+type denial struct {
+	reason string
+	scope  Dimension
+	retry  time.Duration
+}
+
+// allow: token bucket semua dimensi dulu, quota hanya dihitung untuk request
+// yang lolos. Bila satu dimensi menolak, token dan quota yang sudah diambil
+// request ini dikembalikan supaya penolakan di satu dimensi (mis. IP) tidak
+// menghabiskan bucket dimensi lain (merchant, account).
+func (l *Limiter) allow(ctx context.Context, rule Rule, keys map[Dimension]string, now time.Time) *denial {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var taken, counted []DimLimit
+	undo := func() {
+		for _, dl := range taken {
+			if err := l.Store.Refund(ctx, bucketKey(rule, dl.Dim, keys[dl.Dim]), dl.Limit); err != nil {
+				storeFailed(ctx, err)
+			}
+		}
+		for _, dl := range counted {
+			if _, err := l.Store.AddDaily(ctx, "q:"+bucketKey(rule, dl.Dim, keys[dl.Dim]), -1, now); err != nil {
+				storeFailed(ctx, err)
+			}
+		}
+	}
+
+	var d denial
+	for _, dl := range rule.Limits {
+		if dl.Daily > 0 || keys[dl.Dim] == "" {
+			continue
+		}
+		dec, err := l.Store.Take(ctx, bucketKey(rule, dl.Dim, keys[dl.Dim]), dl.Limit, now)
+		if err != nil {
+			storeFailed(ctx, err)
+			continue // fail open: limiter tidak boleh menjatuhkan gateway
+		}
+		if dec.Allowed {
+			taken = append(taken, dl)
+		} else if dec.RetryAfter >= d.retry {
+			d.retry, d.scope = dec.RetryAfter, dl.Dim
+		}
+	}
+	if d.scope != "" {
+		undo()
+		rejected.WithLabelValues(rule.name(), string(d.scope), "rate").Inc()
+		d.reason = "rate_limited"
+		return &d
+	}
+
+	for _, dl := range rule.Limits {
+		if dl.Daily <= 0 || keys[dl.Dim] == "" {
+			continue
+		}
+		n, err := l.Store.AddDaily(ctx, "q:"+bucketKey(rule, dl.Dim, keys[dl.Dim]), 1, now)
+		if err != nil {
+			storeFailed(ctx, err)
+			continue
+		}
+		counted = append(counted, dl)
+		if n > dl.Daily {
+			undo()
+			rejected.WithLabelValues(rule.name(), string(dl.Dim), "quota").Inc()
+			return &denial{reason: "daily_quota_exceeded", scope: dl.Dim, retry: nextDay(now).Sub(now)}
+		}
+	}
+	return nil
+}
+
+func storeFailed(ctx context.Context, err error) {
+	storeErrors.Inc()
+	slog.ErrorContext(ctx, "ratelimit: store failed", "error", err)
+}
+
+func bucketKey(rule Rule, dim Dimension, v string) string {
+	return rule.name() + "|" + string(dim) + "|" + v
+}
+
+// keyFor: nilai dimensi untuk request ini; "" → dimensi dilewati (mis.
+// request anonim tidak punya merchant).
+func (l *Limiter) keyFor(d Dimension, r *http.Request) string {
+	switch d {
+	case ByMerchant:
+		id, _ := auth.MerchantID(r.Context())
+		return id
+	case ByAccount:
+		if isGRPC(r) {
+			return ""
+		}
+		return senderID(r)
+	case ByIP:
+		return l.clientIP(r)
+	}
+	return ""
+}
+
+// senderID membaca akun pengirim dari body JSON (sender_id REST, atau
+// source_account /v1 dalam nama proto maupun JSON) lalu mengembalikan body
+// untuk handler berikutnya.
+func senderID(r *http.Request) string {
+	if r.Body == nil || r.Method == http.MethodGet {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var in struct {
+		SenderID      string `json:"sender_id"`
+		SourceAccount string `json:"source_account"`
+		SourceAcc     string `json:"sourceAccount"`
+	}
+	_ = json.Unmarshal(body, &in)
+	switch {
+	case in.SenderID != "":
+		return in.SenderID
+	case in.SourceAccount != "":
+		return in.SourceAccount
+	}
+	return in.SourceAcc
+}
+
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.TrustForwarded {
+		if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+			hops := strings.Split(strings.Join(xff, ","), ",")
+			n := l.TrustedHops
+			if n < 1 {
+				n = 1
+			}
+			// rantai lebih pendek dari jumlah proxy: semua entri dari proxy
+			return strings.TrimSpace(hops[max(0, len(hops)-n)])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func retrySeconds(retry time.Duration) int {
+	return max(1, int(math.Ceil(retry.Seconds())))
+}
+
+// err: penolakan sebagai RATE_LIMITED; reason (rate_limited,
+// daily_quota_exceeded), scope, dan retry_after di metadata.
+func (d *denial) err() perrors.E {
+	secs := strconv.Itoa(retrySeconds(d.retry))
+	msg := "rate limit exceeded"
+	if d.reason == "daily_quota_exceeded" {
+		msg = "daily quota exceeded"
+	}
+	return perrors.New(perrors.RateLimited, "%s for %s, retry after %ss", msg, d.scope, secs).
+		With("reason", d.reason).With("scope", string(d.scope)).With("retry_after", secs)
+}
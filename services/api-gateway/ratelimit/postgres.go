@@ -0,0 +1,107 @@
+// services/api-gateway/ratelimit/postgres.go
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres: Store bersama semua replica gateway. Waktu diambil dari jam
+// database (bukan now dari replica) supaya refill konsisten walau jam
+// replica sedikit berbeda.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+var _ Store = (*Postgres)(nil)
+
+func NewPostgres(ctx context.Context, pool *pgxpool.Pool) (*Postgres, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+func (s *Postgres) Take(ctx context.Context, key string, l Limit, _ time.Time) (Decision, error) {
+	// refill + ambil token dalam satu statement; baris tidak ter-update bila
+	// token < 1
+	var left float64
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO rate_buckets (key, tokens, updated_at)
+		VALUES ($1, $3::float8 - 1, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = LEAST($3::float8, rate_buckets.tokens
+				+ EXTRACT(EPOCH FROM clock_timestamp() - rate_buckets.updated_at) * $2::float8) - 1,
+			updated_at = clock_timestamp()
+		WHERE LEAST($3::float8, rate_buckets.tokens
+			+ EXTRACT(EPOCH FROM clock_timestamp() - rate_buckets.updated_at) * $2::float8) >= 1
+		RETURNING tokens
+	`, key, l.Rate, l.Burst).Scan(&left)
+	if err == nil {
+		return Decision{Allowed: true}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Decision{}, fmt.Errorf("take token: %w", err)
+	}
+	var tokens float64
+	err = s.pool.QueryRow(ctx, `
+		SELECT LEAST($2::float8, tokens + EXTRACT(EPOCH FROM clock_timestamp() - updated_at) * $3::float8)
+		FROM rate_buckets WHERE key = $1
+	`, key, l.Burst, l.Rate).Scan(&tokens)
+	if err != nil {
+		return Decision{}, fmt.Errorf("read bucket: %w", err)
+	}
+	return Decision{RetryAfter: wait(tokens, l)}, nil
+}
+
+func (s *Postgres) Refund(ctx context.Context, key string, l Limit) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE rate_buckets SET tokens = LEAST($2::float8, tokens + 1) WHERE key = $1
+	`, key, l.Burst)
+	if err != nil {
+		return fmt.Errorf("refund token: %w", err)
+	}
+	return nil
+}
+
+func (s *Postgres) AddDaily(ctx context.Context, key string, n int64, now time.Time) (int64, error) {
+	var total int64
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO rate_quotas (key, day, count) VALUES ($1, $2, $3)
+		ON CONFLICT (key, day) DO UPDATE SET count = rate_quotas.count + $3
+		RETURNING count
+	`, key, now.UTC().Format("2006-01-02"), n).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("add quota: %w", err)
+	}
+	// bersih-bersih sesekali: bucket idle dan quota hari lalu
+	if rand.IntN(500) == 0 {
+		_, _ = s.pool.Exec(ctx, `DELETE FROM rate_buckets WHERE updated_at < now() - interval '1 hour'`)
+		_, _ = s.pool.Exec(ctx, `DELETE FROM rate_quotas WHERE day < current_date - 1`)
+	}
+	return total, nil
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_buckets (
+  key        TEXT PRIMARY KEY,
+  tokens     DOUBLE PRECISION NOT NULL,
+  updated_at TIMESTAMPTZ      NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rate_quotas (
+  key   TEXT   NOT NULL,
+  day   DATE   NOT NULL,
+  count BIGINT NOT NULL,
+  PRIMARY KEY (key, day)
+);
+`)
+	return err
+}
@@ -0,0 +1,57 @@
+// services/api-gateway/ratelimit/ratelimit.go
+// Package ratelimit: token bucket per merchant, akun pengirim, dan IP client,
+// dikonfigurasi per route, plus quota request harian. State disimpan di
+// Store: Memory untuk satu replica, Postgres agar limit berlaku bersama
+// lintas replica gateway.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit: token bucket, Rate token per detik dengan kapasitas Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision: hasil Take.
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration // kapan satu token tersedia lagi (bila ditolak)
+}
+
+type Store interface {
+	// Take mengambil satu token dari bucket key.
+	Take(ctx context.Context, key string, l Limit, now time.Time) (Decision, error)
+	// Refund mengembalikan satu token yang diambil Take (dibatasi Burst),
+	// dipakai bila dimensi lain menolak request yang sama.
+	Refund(ctx context.Context, key string, l Limit) error
+	// AddDaily menambah pemakaian key sebanyak n (negatif = batal) pada hari
+	// (UTC) dari now dan mengembalikan total setelah ditambah.
+	AddDaily(ctx context.Context, key string, n int64, now time.Time) (int64, error)
+}
+
+// refill: isi bucket setelah elapsed, dibatasi Burst.
+func refill(tokens float64, elapsed time.Duration, l Limit) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * l.Rate
+	}
+	return math.Min(tokens, float64(l.Burst))
+}
+
+// wait: lama sampai tokens mencapai 1.
+func wait(tokens float64, l Limit) time.Duration {
+	if tokens >= 1 || l.Rate <= 0 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+}
+
+// nextDay: awal hari UTC berikutnya, untuk Retry-After quota harian.
+func nextDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,262 @@
+// services/api-gateway/ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("POST /api/payments merchant=20/s:40 merchant=1000/d account=60/m; * ip=50/s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].Path != "/api/payments" || rules[1].name() != "*" {
+		t.Fatalf("rules: %+v", rules)
+	}
+	got := rules[0].Limits
+	if got[0].Limit != (Limit{Rate: 20, Burst: 40}) || got[1].Daily != 1000 || got[2].Limit != (Limit{Rate: 1, Burst: 60}) {
+		t.Fatalf("limits: %+v", got)
+	}
+	for _, bad := range []string{"POST", "* user=1/s", "* ip=1/w", "* ip=1/d:5", "* ip=x/s"} {
+		if _, err := ParseRules(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+	l := Limit{Rate: 1, Burst: 2}
+	now := time.Unix(1000, 0)
+	for i := 0; i < 2; i++ {
+		if d, _ := s.Take(ctx, "k", l, now); !d.Allowed {
+			t.Fatalf("take %d denied", i)
+		}
+	}
+	d, _ := s.Take(ctx, "k", l, now)
+	if d.Allowed || d.RetryAfter != time.Second {
+		t.Fatalf("third take: %+v", d)
+	}
+	if d, _ := s.Take(ctx, "k", l, now.Add(time.Second)); !d.Allowed {
+		t.Fatal("refill did not happen")
+	}
+}
+
+func newTestServer(t *testing.T, spec string, now func() time.Time) http.Handler {
+	t.Helper()
+	rules, err := ParseRules(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lim := &Limiter{Rules: rules, Store: NewMemory(), Now: now}
+	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if m := r.Header.Get("X-Test-Merchant"); m != "" {
+				r = r.WithContext(auth.WithMerchant(r.Context(), m))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Use(lim.Middleware)
+	r.HandleFunc("/api/payments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}).Methods(http.MethodPost)
+	return r
+}
+
+func post(h http.Handler, merchant, sender, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/payments", bytes.NewBufferString(`{"sender_id":"`+sender+`"}`))
+	req.RemoteAddr = ip + ":1234"
+	if merchant != "" {
+		req.Header.Set("X-Test-Merchant", merchant)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareKeysAndRetryAfter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := newTestServer(t, "POST /api/payments account=1/m:1 ip=100/s", func() time.Time { return now })
+
+	if rec := post(h, "", "acc-1", "10.0.0.1"); rec.Code != http.StatusAccepted {
+		t.Fatalf("first: %d", rec.Code)
+	}
+	rec := post(h, "", "acc-1", "10.0.0.2")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("same account: %d Retry-After=%q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var p perrors.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || p.Code != perrors.RateLimited ||
+		p.Metadata["scope"] != "account" || rec.Header().Get("Content-Type") != perrors.ContentType {
+		t.Fatalf("body: %v %s", err, rec.Body)
+	}
+	// akun lain punya bucket sendiri
+	if rec := post(h, "", "acc-2", "10.0.0.1"); rec.Code != http.StatusAccepted {
+		t.Fatalf("other account: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareDailyQuota(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	h := newTestServer(t, "POST /api/payments merchant=2/d", func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		if rec := post(h, "m1", "a", "10.0.0.1"); rec.Code != http.StatusAccepted {
+			t.Fatalf("request %d: %d", i, rec.Code)
+		}
+	}
+	rec := post(h, "m1", "a", "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("over quota: %d Retry-After=%q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	// tanpa merchant: dimensi dilewati
+	if rec := post(h, "", "a", "10.0.0.1"); rec.Code != http.StatusAccepted {
+		t.Fatalf("anonymous: %d", rec.Code)
+	}
+	now = now.Add(2 * time.Hour) // hari berikutnya
+	if rec := post(h, "m1", "a", "10.0.0.1"); rec.Code != http.StatusAccepted {
+		t.Fatalf("next day: %d", rec.Code)
+	}
+}
+
+// penolakan di satu dimensi tidak boleh memakan token/quota dimensi lain
+func TestRejectionRefundsOtherDimensions(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := newTestServer(t, "POST /api/payments account=1/m:1 ip=1/m:1", func() time.Time { return now })
+	if rec := post(h, "", "acc-1", "10.0.0.1"); rec.Code != http.StatusAccepted {
+		t.Fatalf("first: %d", rec.Code)
+	}
+	if rec := post(h, "", "acc-1", "10.0.0.2"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("same account: %d", rec.Code)
+	}
+	// token IP 10.0.0.2 dikembalikan saat account menolak
+	if rec := post(h, "", "acc-2", "10.0.0.2"); rec.Code != http.StatusAccepted {
+		t.Fatalf("ip bucket burned by rejected request: %d", rec.Code)
+	}
+
+	h = newTestServer(t, "POST /api/payments account=1/d merchant=2/d", func() time.Time { return now })
+	for i, tc := range []struct {
+		merchant, sender string
+		want             int
+	}{
+		{"m1", "a1", http.StatusAccepted},
+		{"m1", "a1", http.StatusTooManyRequests}, // quota account
+		{"m1", "a2", http.StatusAccepted},
+		{"m1", "a3", http.StatusTooManyRequests}, // quota merchant; a3 dibatalkan
+		{"m2", "a3", http.StatusAccepted},
+	} {
+		if rec := post(h, tc.merchant, tc.sender, "10.0.0.1"); rec.Code != tc.want {
+			t.Fatalf("request %d (%s/%s): %d, want %d", i, tc.merchant, tc.sender, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestClientIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	for _, tc := range []struct {
+		hops int
+		xff  []string
+		want string
+	}{
+		{0, []string{"203.0.113.9"}, "203.0.113.9"},
+		{0, []string{"1.1.1.1, 2.2.2.2, 203.0.113.9"}, "203.0.113.9"},
+		{1, []string{"1.1.1.1", "203.0.113.9"}, "203.0.113.9"},
+		{2, []string{"1.1.1.1, 203.0.113.9, 10.0.0.5"}, "203.0.113.9"},
+		{3, []string{"203.0.113.9, 10.0.0.5"}, "203.0.113.9"},
+	} {
+		l := &Limiter{TrustForwarded: true, TrustedHops: tc.hops}
+		req := httptest.NewRequest(http.MethodPost, "/api/payments", nil)
+		for _, v := range tc.xff {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		if got := l.clientIP(req); got != tc.want {
+			t.Errorf("hops=%d xff=%q: %q, want %q", tc.hops, tc.xff, got, tc.want)
+		}
+	}
+
+	// prefix XFF yang diganti-ganti client tidak memberi bucket baru
+	rules, err := ParseRules("POST /api/payments ip=1/m:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lim := &Limiter{Rules: rules, Store: NewMemory(), TrustForwarded: true}
+	h := lim.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	for i, spoof := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/payments", nil)
+		req.Header.Set("X-Forwarded-For", spoof+", 203.0.113.9")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if want := []int{http.StatusAccepted, http.StatusTooManyRequests}[i]; rec.Code != want {
+			t.Fatalf("spoofed %s: %d, want %d", spoof, rec.Code, want)
+		}
+	}
+}
+
+type sourceReq string
+
+func (s sourceReq) GetSourceAccount() string { return string(s) }
+
+// body gRPC tidak bisa dibaca middleware: account diputuskan interceptor;
+// /v1 membawa source_account di body JSON.
+func TestAccountDimensionOnGRPCAndV1(t *testing.T) {
+	rules, err := ParseRules("POST /payments.v1.PaymentsService/CreatePayment account=1/m:1 ip=100/s; POST /api/payments account=1/m:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lim := &Limiter{Rules: rules, Store: NewMemory()}
+	var calls int
+	h := lim.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acc := r.Header.Get("X-Test-Account")
+		_, err := lim.UnaryServerInterceptor()(r.Context(), sourceReq(acc), &grpc.UnaryServerInfo{},
+			func(context.Context, any) (any, error) { calls++; return nil, nil })
+		if err != nil {
+			w.WriteHeader(perrors.HTTPStatus(perrors.CodeOf(err)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(acc string) int {
+		req := httptest.NewRequest(http.MethodPost, "/payments.v1.PaymentsService/CreatePayment", nil)
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("X-Test-Account", acc)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for i, tc := range []struct {
+		acc  string
+		want int
+	}{{"A-1", http.StatusOK}, {"A-1", http.StatusTooManyRequests}, {"A-2", http.StatusOK}} {
+		if got := call(tc.acc); got != tc.want {
+			t.Fatalf("grpc %d (%s): %d, want %d", i, tc.acc, got, tc.want)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls %d, want 2", calls)
+	}
+
+	for i, body := range []string{`{"source_account":"A-1"}`, `{"sourceAccount":"A-1"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/payments", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		lim.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+		if want := []int{http.StatusOK, http.StatusTooManyRequests}[i]; rec.Code != want {
+			t.Fatalf("json %s: %d, want %d", body, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// services/api-gateway/store/memory.go
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory: PaymentStore in-process (default, hilang saat restart). Payment
+// yang tidak di-update selama ttl dibuang.
+//
+// Keterbatasan: status hanya di-update dari result yang dibaca proses ini.
+// Payment yang sudah dijawab 202 lalu gateway restart sebelum result-nya
+// terbaca tetap PENDING sampai ttl habis (tidak dicocokkan ulang ke result
+// stream atau tabel payments); pakai store postgres bila status harus
+// tetap benar lintas restart.
+type Memory struct {
+	ttl       time.Duration
+	mu        sync.RWMutex
+	data      map[string]*Payment
+	lastSweep time.Time
+}
+
+// NewMemory: ttl <= 0 = 24 jam.
+func NewMemory(ttl time.Duration) *Memory {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &Memory{ttl: ttl, data: map[string]*Payment{}}
+}
+
+func (s *Memory) Create(ctx context.Context, p *Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	s.sweep(now)
+	p.CreatedAt, p.UpdatedAt = now, now
+	cp := *p
+	s.data[p.ID] = &cp
+	return nil
+}
+
+func (s *Memory) Get(ctx context.Context, merchantID, id string) (*Payment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.data[id]
+	if !ok || p.MerchantID != merchantID || time.Since(p.UpdatedAt) > s.ttl {
+		return nil, ErrNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (s *Memory) SetResult(ctx context.Context, id, status, reason, ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.data[id]
+	if !ok {
+		return ErrNotFound
+	}
+	p.Status, p.Reason, p.Ref = status, reason, ref
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// sweep: hapus payment kedaluwarsa, paling sering sekali per menit
+// (dipanggil dengan mu terkunci).
+func (s *Memory) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for id, p := range s.data {
+		if now.Sub(p.UpdatedAt) > s.ttl {
+			delete(s.data, id)
+		}
+	}
+}
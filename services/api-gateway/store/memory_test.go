@@ -0,0 +1,32 @@
+// services/api-gateway/store/memory_test.go
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryEvictsExpired(t *testing.T) {
+	s := NewMemory(20 * time.Millisecond)
+	ctx := context.Background()
+	if err := s.Create(ctx, &Payment{ID: "p-1", Status: StatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "", "p-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, err := s.Get(ctx, "", "p-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expired payment: err %v, want ErrNotFound", err)
+	}
+	s.lastSweep = time.Time{} // sweep berikutnya tidak menunggu semenit
+	if err := s.Create(ctx, &Payment{ID: "p-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.data); n != 1 {
+		t.Fatalf("%d payments kept, want 1", n)
+	}
+}
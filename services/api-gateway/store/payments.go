@@ -0,0 +1,42 @@
+// services/api-gateway/store/payments.go
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("payment not found")
+
+// Status PENDING sampai worker mengirim result.
+const StatusPending = "PENDING"
+
+// Payment: request pembayaran yang diterima gateway + status terakhirnya.
+type Payment struct {
+	ID             string    `json:"payment_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	SenderID       string    `json:"sender_id"`
+	ReceiverID     string    `json:"receiver_id"`
+	Currency       string    `json:"currency"`
+	Amount         float64   `json:"amount"`
+	AmountIDR      int64     `json:"amount_idr"`
+	TxDateISO      string    `json:"tx_date"`
+	MerchantID     string    `json:"merchant_id,omitempty"`
+	FeeIDR         int64     `json:"fee_idr"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	Ref            string    `json:"ref,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PaymentStore menyimpan request supaya client bisa polling
+// GET /api/payments/{id} setelah 202 Accepted.
+type PaymentStore interface {
+	Create(ctx context.Context, p *Payment) error
+	// Get hanya mengembalikan payment milik merchantID ("" = payment tanpa
+	// merchant); payment tenant lain → ErrNotFound.
+	Get(ctx context.Context, merchantID, id string) (*Payment, error)
+	SetResult(ctx context.Context, id, status, reason, ref string) error
+}
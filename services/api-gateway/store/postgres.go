@@ -0,0 +1,113 @@
+// services/api-gateway/store/postgres.go
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres: PaymentStore yang dibagi semua replica gateway. Status final
+// juga dibaca dari tabel payments milik worker, jadi result yang terlewat
+// oleh gateway (restart, timeout) tetap terlihat saat polling.
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(ctx context.Context, pool *pgxpool.Pool) (*Postgres, error) {
+	if err := ensureSchema(ctx, pool); err != nil {
+		return nil, fmt.Errorf("ensure schema: %w", err)
+	}
+	return &Postgres{pool: pool}, nil
+}
+
+func (s *Postgres) Create(ctx context.Context, p *Payment) error {
+	return s.pool.QueryRow(ctx, `
+		INSERT INTO payment_requests
+			(payment_id, idempotency_key, sender_id, receiver_id, currency, amount, amount_idr, tx_date, merchant_id, fee_idr, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at
+	`, p.ID, p.IdempotencyKey, p.SenderID, p.ReceiverID, p.Currency, p.Amount, p.AmountIDR, p.TxDateISO, p.MerchantID, p.FeeIDR, p.Status,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (s *Postgres) Get(ctx context.Context, merchantID, id string) (*Payment, error) {
+	var (
+		p            Payment
+		ledger       *string
+		ledgerRef    *string
+		ledgerReason *string
+	)
+	err := s.pool.QueryRow(ctx, `
+		SELECT r.payment_id::text, r.idempotency_key, r.sender_id, r.receiver_id, r.currency,
+		       r.amount, r.amount_idr, r.tx_date, r.merchant_id, r.fee_idr, r.status, r.reason, r.ref,
+		       r.created_at, r.updated_at, p.status, p.payment_id::text, p.reason
+		FROM payment_requests r
+		LEFT JOIN payments p ON p.idempotency_key = r.idempotency_key
+		WHERE r.payment_id = $1 AND r.merchant_id = $2
+	`, id, merchantID).Scan(&p.ID, &p.IdempotencyKey, &p.SenderID, &p.ReceiverID, &p.Currency,
+		&p.Amount, &p.AmountIDR, &p.TxDateISO, &p.MerchantID, &p.FeeIDR, &p.Status, &p.Reason, &p.Ref,
+		&p.CreatedAt, &p.UpdatedAt, &ledger, &ledgerRef, &ledgerReason)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query payment: %w", err)
+	}
+	if p.Status == StatusPending && ledger != nil {
+		p.Status = *ledger
+		if ledgerRef != nil {
+			p.Ref = *ledgerRef
+		}
+		if ledgerReason != nil {
+			p.Reason = *ledgerReason
+		}
+	}
+	return &p, nil
+}
+
+func (s *Postgres) SetResult(ctx context.Context, id, status, reason, ref string) error {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE payment_requests
+		SET status = $2, reason = $3, ref = $4, updated_at = now()
+		WHERE payment_id = $1
+	`, id, status, reason, ref)
+	if err != nil {
+		return fmt.Errorf("update payment: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS payment_requests (
+  payment_id      UUID PRIMARY KEY,
+  idempotency_key TEXT        NOT NULL,
+  sender_id       VARCHAR     NOT NULL,
+  receiver_id     VARCHAR     NOT NULL,
+  currency        TEXT        NOT NULL,
+  amount          DOUBLE PRECISION NOT NULL,
+  amount_idr      BIGINT      NOT NULL,
+  tx_date         TEXT        NOT NULL,
+  merchant_id     TEXT        NOT NULL DEFAULT '',
+  fee_idr         BIGINT      NOT NULL DEFAULT 0,
+  status          TEXT        NOT NULL DEFAULT 'PENDING',
+  reason          TEXT        NOT NULL DEFAULT '',
+  ref             TEXT        NOT NULL DEFAULT '',
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE payment_requests ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE payment_requests ADD COLUMN IF NOT EXISTS fee_idr BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS payment_requests_idem_idx ON payment_requests (idempotency_key);
+-- alasan FAILED dari worker; kolom juga ditambahkan worker.EnsureSchema
+ALTER TABLE IF EXISTS payments ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+`)
+	return err
+}
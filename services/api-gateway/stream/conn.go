@@ -0,0 +1,122 @@
+// services/api-gateway/stream/conn.go
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conn: satu stream ke client, SSE atau WebSocket.
+type Conn interface {
+	// Send kirim satu event; id dipakai SSE sebagai Last-Event-ID.
+	Send(event, id string, v any) error
+	Heartbeat() error
+	// Done tertutup saat client putus.
+	Done() <-chan struct{}
+	Close() error
+}
+
+// Open: WebSocket bila client meminta upgrade, selain itu SSE.
+func (h *Hub) Open(w http.ResponseWriter, r *http.Request) (Conn, string, error) {
+	if IsWebSocket(r) {
+		c, err := openWebSocket(w, r, h.AllowedOrigins)
+		return c, "websocket", err
+	}
+	c, err := openSSE(w, r)
+	return c, "sse", err
+}
+
+// ===== Server-Sent Events =====
+
+type sseConn struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+	r  *http.Request
+}
+
+func openSSE(w http.ResponseWriter, r *http.Request) (*sseConn, error) {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // nginx: jangan di-buffer
+	w.WriteHeader(http.StatusOK)
+	c := &sseConn{w: w, rc: http.NewResponseController(w), r: r}
+	if err := c.rc.Flush(); err != nil {
+		return nil, fmt.Errorf("sse: %w", err)
+	}
+	return c, nil
+}
+
+func (c *sseConn) Send(event, id string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if id != "" {
+		fmt.Fprintf(&sb, "id: %s\n", id)
+	}
+	fmt.Fprintf(&sb, "event: %s\ndata: %s\n\n", event, b)
+	if _, err := c.w.Write([]byte(sb.String())); err != nil {
+		return err
+	}
+	return c.rc.Flush()
+}
+
+func (c *sseConn) Heartbeat() error {
+	if _, err := c.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	return c.rc.Flush()
+}
+
+func (c *sseConn) Done() <-chan struct{} { return c.r.Context().Done() }
+
+func (c *sseConn) Close() error { return nil }
+
+var errClosed = errors.New("stream: closed")
+
+// HeartbeatInterval: komentar SSE / ping WebSocket supaya proxy tidak
+// menutup koneksi yang diam.
+var HeartbeatInterval = 15 * time.Second
+
+// Pump kirim update dari sub ke c sampai client putus, sub diputus hub, atau
+// (bila untilFinal) status final terkirim. fill boleh nil.
+func Pump(c Conn, sub *Sub, untilFinal bool, fill func(*Update)) {
+	t := time.NewTicker(HeartbeatInterval)
+	defer t.Stop()
+	for {
+		select {
+		case u, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if fill != nil {
+				fill(&u)
+			}
+			if err := c.Send("payment", u.EventID, u); err != nil {
+				return
+			}
+			if untilFinal && u.Final() {
+				return
+			}
+		case <-t.C:
+			if err := c.Heartbeat(); err != nil {
+				return
+			}
+		case <-c.Done():
+			return
+		}
+	}
+}
+
+// Track: naikkan gauge subscriber; panggil fungsi hasilnya saat stream selesai.
+func Track(transport string) func() {
+	subscribers.WithLabelValues(transport).Inc()
+	return func() { subscribers.WithLabelValues(transport).Dec() }
+}
@@ -0,0 +1,155 @@
+// services/api-gateway/stream/hub.go
+// Package stream mendorong perubahan status payment ke browser/client
+// lewat Server-Sent Events, dengan WebSocket sebagai fallback. Sumbernya
+// result yang dilihat gateway di payments.result (queue.Bus.WatchResults).
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/example/payment-gateway-poc/pkg/events"
+	eventsv1 "github.com/example/payment-gateway-poc/proto/gen/events/v1"
+)
+
+// subBuffer: update yang boleh antre per subscriber. Subscriber yang lebih
+// lambat dari ini diputus; client reconnect dan mendapat snapshot lagi.
+const subBuffer = 32
+
+var (
+	subscribers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "payment",
+			Subsystem: "gateway",
+			Name:      "stream_subscribers",
+			Help:      "Open payment status streams by transport",
+		},
+		[]string{"transport"},
+	)
+	dropped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "payment",
+			Subsystem: "gateway",
+			Name:      "stream_dropped_total",
+			Help:      "Streams closed because the client could not keep up",
+		},
+	)
+)
+
+// Update: satu perubahan status.
+type Update struct {
+	EventID        string    `json:"event_id,omitempty"`
+	PaymentID      string    `json:"payment_id,omitempty"` // id dari POST /api/payments, bila diketahui
+	IdempotencyKey string    `json:"idempotency_key"`
+	SenderID       string    `json:"sender_id,omitempty"`
+	ReceiverID     string    `json:"receiver_id,omitempty"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	Ref            string    `json:"ref,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// Final: PENDING satu-satunya status yang masih bisa berubah.
+func (u Update) Final() bool { return u.Status != "PENDING" }
+
+type Filter func(Update) bool
+
+func ForIdempotencyKey(k string) Filter {
+	return func(u Update) bool { return u.IdempotencyKey == k }
+}
+
+// ForAccount: payment keluar maupun masuk.
+func ForAccount(id string) Filter {
+	return func(u Update) bool { return u.SenderID == id || u.ReceiverID == id }
+}
+
+type Sub struct {
+	C      <-chan Update
+	c      chan Update
+	filter Filter
+	closed bool
+}
+
+// Hub membagikan Update ke subscriber yang filternya cocok.
+type Hub struct {
+	// AllowedOrigins: origin lain (selain host gateway sendiri) yang boleh
+	// membuka WebSocket, mis. CORS_ALLOWED_ORIGINS; "*" = semua.
+	AllowedOrigins []string
+
+	mu   sync.Mutex
+	subs map[*Sub]struct{}
+}
+
+func NewHub() *Hub { return &Hub{subs: map[*Sub]struct{}{}} }
+
+func (h *Hub) Subscribe(f Filter) *Sub {
+	c := make(chan Update, subBuffer)
+	s := &Sub{C: c, c: c, filter: f}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Sub) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(s)
+}
+
+func (h *Hub) drop(s *Sub) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(h.subs, s)
+	close(s.c)
+}
+
+func (h *Hub) Publish(u Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		if !s.filter(u) {
+			continue
+		}
+		select {
+		case s.c <- u:
+		default:
+			dropped.Inc()
+			h.drop(s)
+		}
+	}
+}
+
+// PublishResult: pasang ke queue.Bus.WatchResults.
+func (h *Hub) PublishResult(key, payload []byte) {
+	ev, err := events.DecodeResult(payload)
+	if err != nil {
+		return
+	}
+	u := Update{
+		EventID:        ev.GetHeader().GetEventId(),
+		IdempotencyKey: ev.GetIdempotencyKey(),
+		SenderID:       ev.GetSenderId(),
+		ReceiverID:     ev.GetReceiverId(),
+		Ref:            ev.GetPaymentId(),
+		At:             time.Now().UTC(),
+	}
+	if u.IdempotencyKey == "" {
+		u.IdempotencyKey = string(key) // JSON lama tidak membawa key
+	}
+	if ms := ev.GetHeader().GetOccurredAtMs(); ms > 0 {
+		u.At = time.UnixMilli(ms).UTC()
+	}
+	switch ev := ev.(type) {
+	case *eventsv1.PaymentSettled:
+		u.Status = ev.GetStatus()
+	case *eventsv1.PaymentFailed:
+		u.Status, u.Reason = "FAILED", ev.GetReason()
+	}
+	h.Publish(u)
+}
@@ -0,0 +1,277 @@
+// services/api-gateway/stream/websocket.go
+package stream
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket minimal (RFC 6455) untuk client yang tidak bisa SSE: server
+// hanya mengirim text frame; dari client hanya ping dan close yang
+// ditangani, data frame dibaca lalu dibuang. Frame yang melanggar RFC
+// (tidak di-mask, RSV, opcode tak dikenal, control frame terfragmentasi
+// atau > 125 byte) menutup koneksi dengan 1002; frame > maxFrameSize 1009.
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+const (
+	maxFrameSize   = 64 << 10
+	maxControlSize = 125
+
+	closeProtocolError = 1002
+	closeTooBig        = 1009
+)
+
+// wsError: pelanggaran protokol; code dikirim di close frame.
+type wsError struct {
+	code   uint16
+	reason string
+}
+
+func (e *wsError) Error() string { return "websocket: " + e.reason }
+
+func IsWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		headerHasToken(r.Header, "Connection", "upgrade")
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	wmu    sync.Mutex
+	done   chan struct{}
+	closed sync.Once
+}
+
+// checkOrigin: browser selalu mengirim Origin pada handshake WebSocket dan
+// cookie ikut terkirim lintas situs (cross-site WebSocket hijacking), jadi
+// hanya origin host ini sendiri atau yang ada di allowed yang diterima.
+// Tanpa Origin = client non-browser.
+func checkOrigin(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func openWebSocket(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		http.Error(w, "bad websocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: bad handshake")
+	}
+	if !checkOrigin(r, allowedOrigins) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %q not allowed", r.Header.Get("Origin"))
+	}
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &wsConn{conn: conn, br: brw.Reader, done: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+// readLoop: balas ping, tutup saat client kirim close, melanggar
+// protokol, atau koneksi putus.
+func (c *wsConn) readLoop() {
+	defer c.shutdown()
+	for {
+		op, payload, err := c.readFrame()
+		var perr *wsError
+		if errors.As(err, &perr) {
+			_ = c.writeFrame(opClose, closePayload(perr.code, perr.reason))
+			return
+		}
+		if err != nil {
+			return
+		}
+		switch op {
+		case opPing:
+			_ = c.writeFrame(opPong, payload)
+		case opClose:
+			_ = c.writeFrame(opClose, payload)
+			return
+		}
+	}
+}
+
+func closePayload(code uint16, reason string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, code), reason...)
+}
+
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		return 0, nil, err
+	}
+	fin := h[0]&0x80 != 0
+	op := h[0] & 0x0F
+	masked := h[1]&0x80 != 0
+	n := uint64(h[1] & 0x7F)
+
+	if h[0]&0x70 != 0 {
+		return 0, nil, &wsError{closeProtocolError, "reserved bits set"}
+	}
+	switch op {
+	case opContinuation, opText, opBinary:
+	case opClose, opPing, opPong:
+		// control frame: tidak boleh terfragmentasi, payload <= 125
+		if !fin {
+			return 0, nil, &wsError{closeProtocolError, "fragmented control frame"}
+		}
+		if n > maxControlSize {
+			return 0, nil, &wsError{closeProtocolError, "control frame too large"}
+		}
+	default:
+		return 0, nil, &wsError{closeProtocolError, "unknown opcode"}
+	}
+	// frame dari client wajib di-mask (RFC 6455 5.1)
+	if !masked {
+		return 0, nil, &wsError{closeProtocolError, "unmasked client frame"}
+	}
+
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > maxFrameSize {
+		return 0, nil, &wsError{closeTooBig, "frame too large"}
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	select {
+	case <-c.done:
+		return errClosed
+	default:
+	}
+
+	hdr := []byte{0x80 | op} // FIN
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xFFFF:
+		hdr = append(hdr, 126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(append(hdr, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Send: {"event": ..., "id": ..., "data": ...} sebagai satu text frame.
+func (c *wsConn) Send(event, id string, v any) error {
+	b, err := json.Marshal(struct {
+		Event string `json:"event"`
+		ID    string `json:"id,omitempty"`
+		Data  any    `json:"data"`
+	}{event, id, v})
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, b)
+}
+
+func (c *wsConn) Heartbeat() error { return c.writeFrame(opPing, nil) }
+
+func (c *wsConn) Done() <-chan struct{} { return c.done }
+
+func (c *wsConn) Close() error {
+	_ = c.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000 normal closure
+	c.shutdown()
+	return nil
+}
+
+func (c *wsConn) shutdown() {
+	c.closed.Do(func() {
+		c.wmu.Lock()
+		close(c.done)
+		c.wmu.Unlock()
+		c.conn.Close()
+	})
+}
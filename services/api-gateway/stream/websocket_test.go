@@ -0,0 +1,163 @@
+// services/api-gateway/stream/websocket_test.go
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func wsServer(t *testing.T, allowed ...string) *httptest.Server {
+	t.Helper()
+	hub := NewHub()
+	hub.AllowedOrigins = allowed
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _, err := hub.Open(w, r)
+		if err != nil {
+			return
+		}
+		<-c.Done()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// handshake: status HTTP; untuk 101 koneksi siap dipakai.
+func handshake(t *testing.T, srv *httptest.Server, origin string) (int, net.Conn, *bufio.Reader) {
+	t.Helper()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	req := fmt.Sprintf("GET /events HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n", host)
+	if origin != "" {
+		req += "Origin: " + origin + "\r\n"
+	}
+	if _, err := io.WriteString(conn, req+"\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, conn, br
+}
+
+// clientFrame: satu frame dari client; masked=false untuk menguji penolakan.
+func clientFrame(fin bool, op byte, payload []byte, masked bool) []byte {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	f := []byte{b0}
+	var mbit byte
+	if masked {
+		mbit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		f = append(f, mbit|byte(n))
+	case n <= 0xFFFF:
+		f = append(f, mbit|126)
+		f = binary.BigEndian.AppendUint16(f, uint16(n))
+	default:
+		f = append(f, mbit|127)
+		f = binary.BigEndian.AppendUint64(f, uint64(n))
+	}
+	if !masked {
+		return append(f, payload...)
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	f = append(f, mask[:]...)
+	for i, b := range payload {
+		f = append(f, b^mask[i%4])
+	}
+	return f
+}
+
+// serverFrame: baca satu frame (tidak di-mask) dari server.
+func serverFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var h [2]byte
+	if _, err := io.ReadFull(br, h[:]); err != nil {
+		t.Fatal(err)
+	}
+	n := int(h[1] & 0x7F)
+	if n >= 126 {
+		t.Fatalf("unexpected long server frame")
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return h[0] & 0x0F, payload
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	srv := wsServer(t, "https://shop.example")
+	for origin, want := range map[string]int{
+		"":                     http.StatusSwitchingProtocols, // client non-browser
+		srv.URL:                http.StatusSwitchingProtocols, // same origin
+		"https://shop.example": http.StatusSwitchingProtocols,
+		"https://evil.example": http.StatusForbidden,
+		"null":                 http.StatusForbidden,
+	} {
+		if got, _, _ := handshake(t, srv, origin); got != want {
+			t.Errorf("Origin %q: status %d, want %d", origin, got, want)
+		}
+	}
+}
+
+func TestWebSocketPing(t *testing.T) {
+	_, conn, br := handshake(t, wsServer(t), "")
+	if _, err := conn.Write(clientFrame(true, opPing, []byte("hi"), true)); err != nil {
+		t.Fatal(err)
+	}
+	if op, payload := serverFrame(t, br); op != opPong || string(payload) != "hi" {
+		t.Fatalf("got op %#x %q, want pong", op, payload)
+	}
+}
+
+func TestWebSocketRejectsBadFrames(t *testing.T) {
+	// header saja: panjang ditolak sebelum payload dibaca
+	huge := binary.BigEndian.AppendUint64([]byte{0x80 | opBinary, 0x80 | 127}, maxFrameSize+1)
+
+	for name, tc := range map[string]struct {
+		frame []byte
+		code  uint16
+	}{
+		"unmasked":            {clientFrame(true, opPing, nil, false), closeProtocolError},
+		"fragmented control":  {clientFrame(false, opPing, nil, true), closeProtocolError},
+		"oversized control":   {clientFrame(true, opPing, make([]byte, 126), true), closeProtocolError},
+		"reserved bits":       {append([]byte{0x80 | 0x40 | opText}, clientFrame(true, opText, nil, true)[1:]...), closeProtocolError},
+		"unknown opcode":      {clientFrame(true, 0x3, nil, true), closeProtocolError},
+		"frame over max size": {huge, closeTooBig},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, conn, br := handshake(t, wsServer(t), "")
+			if _, err := conn.Write(tc.frame); err != nil {
+				t.Fatal(err)
+			}
+			op, payload := serverFrame(t, br)
+			if op != opClose || len(payload) < 2 || binary.BigEndian.Uint16(payload) != tc.code {
+				t.Fatalf("got op %#x payload %q, want close %d", op, payload, tc.code)
+			}
+			// koneksi ditutup server
+			if _, err := br.ReadByte(); err != io.EOF {
+				t.Fatalf("connection still open: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// services/api-gateway/transcode/docs.go
+package transcode
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OpenAPI: path dan schema OpenAPI 3 untuk routes, siap digabung ke
+// openapi.json lewat openapi.Document.Extend. security (opsional) memberi
+// nama security scheme yang diterima per route (salah satu cukup).
+func OpenAPI(routes []*Route, security func(rt *Route) []string) (paths, schemas map[string]any) {
+	paths = map[string]any{}
+	schemas = map[string]any{}
+	ids := map[string]int{}
+	for _, rt := range routes {
+		item, _ := paths[rt.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[rt.Path] = item
+		}
+		svc := rt.Desc.Parent().(protoreflect.ServiceDescriptor)
+		// additional_bindings: FxService_Convert, FxService_Convert_2, ...
+		id := string(svc.Name()) + "_" + string(rt.Desc.Name())
+		if ids[id]++; ids[id] > 1 {
+			id += "_" + strconv.Itoa(ids[id])
+		}
+		op := map[string]any{
+			"operationId":   id,
+			"tags":          []string{string(svc.FullName())},
+			"summary":       rt.RPC,
+			"x-grpc-method": rt.RPC,
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     jsonContent(messageRef(rt.Desc.Output(), schemas)),
+				},
+				"400":     map[string]any{"$ref": "#/components/responses/BadRequest"},
+				"default": map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		}
+
+		var params []any
+		bound := map[string]bool{}
+		for _, v := range rt.Vars {
+			bound[v] = true
+			params = append(params, map[string]any{
+				"name": v, "in": "path", "required": true,
+				"schema": fieldSchema(lookup(rt.Desc.Input(), v), schemas, false),
+			})
+		}
+		switch rt.Body {
+		case "":
+			fields := rt.Desc.Input().Fields()
+			for i := 0; i < fields.Len(); i++ {
+				fd := fields.Get(i)
+				if bound[string(fd.Name())] || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+					continue
+				}
+				params = append(params, map[string]any{
+					"name": string(fd.Name()), "in": "query",
+					"schema": fieldSchema(fd, schemas, true),
+				})
+			}
+		case "*":
+			op["requestBody"] = map[string]any{"required": true, "content": jsonContent(messageRef(rt.Desc.Input(), schemas))}
+		default:
+			op["requestBody"] = map[string]any{"required": true, "content": jsonContent(messageRef(lookup(rt.Desc.Input(), rt.Body).Message(), schemas))}
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if security != nil {
+			var reqs []any
+			for _, name := range security(rt) {
+				reqs = append(reqs, map[string]any{name: []string{}})
+			}
+			if len(reqs) > 0 {
+				op["security"] = reqs
+			}
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+	return paths, schemas
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// messageRef mendaftarkan md (dan message di dalamnya) ke schemas.
+func messageRef(md protoreflect.MessageDescriptor, schemas map[string]any) map[string]any {
+	name := string(md.FullName())
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := schemas[name]; ok {
+		return ref
+	}
+	props := map[string]any{}
+	schemas[name] = map[string]any{"type": "object", "additionalProperties": false, "properties": props}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		props[string(fd.Name())] = fieldSchema(fd, schemas, true)
+	}
+	return ref
+}
+
+// fieldSchema mengikuti pemetaan JSON protojson: int64 sebagai string,
+// enum sebagai nama.
+func fieldSchema(fd protoreflect.FieldDescriptor, schemas map[string]any, list bool) map[string]any {
+	if fd.IsMap() {
+		return map[string]any{"type": "object", "additionalProperties": fieldSchema(fd.MapValue(), schemas, false)}
+	}
+	if list && fd.IsList() {
+		return map[string]any{"type": "array", "items": fieldSchema(fd, schemas, false)}
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]any, values.Len())
+		for i := range names {
+			names[i] = string(values.Get(i).Name())
+		}
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageRef(fd.Message(), schemas)
+	}
+	return map[string]any{"type": "string"}
+}
@@ -0,0 +1,422 @@
+// services/api-gateway/transcode/transcode.go
+// Package transcode: REST/JSON → gRPC untuk semua service yang diberi
+// anotasi google.api.http di proto/gen/*/v1/*.proto. Route dibaca dari
+// descriptor yang ter-registrasi di binary, jadi menambah RPC cukup dengan
+// anotasi + regenerate; tidak ada handler yang ditulis tangan.
+//
+// Pemetaan request mengikuti google/api/http.proto: body "*" → seluruh
+// message (protojson, field tak dikenal ditolak), variabel path dan query
+// parameter → field dengan nama yang sama. Response selalu protojson dengan
+// nama field proto (snake_case) dan field kosong tetap dikirim.
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/services/api-gateway/openapi"
+)
+
+const maxBody = 1 << 20
+
+var requests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "payment",
+	Subsystem: "transcode",
+	Name:      "requests_total",
+	Help:      "Request REST /v1 yang diteruskan ke gRPC, per RPC dan kode status gRPC.",
+}, []string{"rpc", "code"})
+
+// Route: satu binding HTTP dari anotasi google.api.http.
+type Route struct {
+	Method   string // GET, POST, ...
+	Path     string // template proto, mis. /v1/wallet/accounts/{account_id}
+	RPC      string // /wallet.v1.WalletService/GetBalance
+	Body     string // "", "*" atau nama field
+	Vars     []string
+	Desc     protoreflect.MethodDescriptor
+	conn     grpc.ClientConnInterface
+	template string // template gorilla/mux
+}
+
+var varPattern = regexp.MustCompile(`\{([^{}=]+)\}`)
+
+// Routes: binding untuk setiap service (nama lengkap, mis. wallet.v1.WalletService)
+// ke koneksinya. Package proto service tersebut harus sudah di-import.
+func Routes(conns map[string]grpc.ClientConnInterface) ([]*Route, error) {
+	names := make([]string, 0, len(conns))
+	for name := range conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var routes []*Route
+	for _, name := range names {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("transcode: service %s: %w", name, err)
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("transcode: %s is not a service", name)
+		}
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			opts := md.Options()
+			if opts == nil || !proto.HasExtension(opts, annotations.E_Http) {
+				continue
+			}
+			rule := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+			for _, b := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				rt, err := newRoute(md, b, conns[name])
+				if err != nil {
+					return nil, fmt.Errorf("transcode: %s: %w", md.FullName(), err)
+				}
+				routes = append(routes, rt)
+			}
+		}
+	}
+	return routes, nil
+}
+
+func newRoute(md protoreflect.MethodDescriptor, rule *annotations.HttpRule, conn grpc.ClientConnInterface) (*Route, error) {
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, errors.New("streaming RPCs cannot be transcoded")
+	}
+	rt := &Route{
+		RPC:  fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name()),
+		Body: rule.GetBody(),
+		Desc: md,
+		conn: conn,
+	}
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		rt.Method, rt.Path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Post:
+		rt.Method, rt.Path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Put:
+		rt.Method, rt.Path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Patch:
+		rt.Method, rt.Path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Delete:
+		rt.Method, rt.Path = http.MethodDelete, p.Delete
+	default:
+		return nil, fmt.Errorf("unsupported http pattern %T", p)
+	}
+	if rule.GetResponseBody() != "" {
+		return nil, errors.New("response_body is not supported")
+	}
+	if strings.Contains(rt.Path, "=") {
+		return nil, fmt.Errorf("path %s: only simple {field} variables are supported", rt.Path)
+	}
+	in := md.Input()
+	for _, m := range varPattern.FindAllStringSubmatch(rt.Path, -1) {
+		if fd := lookup(in, m[1]); fd == nil || fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			return nil, fmt.Errorf("path %s: {%s} is not a scalar field of %s", rt.Path, m[1], in.FullName())
+		}
+		rt.Vars = append(rt.Vars, m[1])
+	}
+	if rt.Body != "" && rt.Body != "*" {
+		if fd := lookup(in, rt.Body); fd == nil || strings.Contains(rt.Body, ".") || fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("body %q is not a message field of %s", rt.Body, in.FullName())
+		}
+	}
+	// ":verb" di akhir path tidak boleh ikut tertangkap variabel
+	rt.template = varPattern.ReplaceAllString(rt.Path, "{$1:[^/:]+}")
+	return rt, nil
+}
+
+// Options untuk Mount.
+type Options struct {
+	// Timeout per panggilan gRPC, default 10 detik.
+	Timeout time.Duration
+	// Wrap (opsional) membungkus handler setiap route, mis. untuk
+	// membatasi RPC mutasi ke token admin.
+	Wrap func(rt *Route, h http.Handler) http.Handler
+}
+
+// Mount mendaftarkan semua route ke r.
+func Mount(r *mux.Router, routes []*Route, opt Options) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	for _, rt := range routes {
+		var h http.Handler = &handler{route: rt, timeout: opt.Timeout}
+		if opt.Wrap != nil {
+			h = opt.Wrap(rt, h)
+		}
+		r.Handle(rt.template, h).Methods(rt.Method)
+	}
+}
+
+type handler struct {
+	route   *Route
+	timeout time.Duration
+}
+
+var marshal = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt := h.route
+	in := dynamicpb.NewMessage(rt.Desc.Input())
+	if status, errs := rt.bind(r, in); len(errs) > 0 {
+		requests.WithLabelValues(rt.RPC, "INVALID_REQUEST").Inc()
+		perrors.Write(w, r, openapi.InvalidRequest(status, errs))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	out := dynamicpb.NewMessage(rt.Desc.Output())
+	err := rt.conn.Invoke(ctx, rt.RPC, in, out)
+	st := status.Convert(err)
+	requests.WithLabelValues(rt.RPC, st.Code().String()).Inc()
+	if err != nil {
+		WriteError(w, r, st)
+		return
+	}
+	b, err := marshal.Marshal(out)
+	if err != nil {
+		WriteError(w, r, status.New(codes.Internal, "encode response"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// bind: body → query → variabel path (variabel path menang bila bentrok).
+func (rt *Route) bind(r *http.Request, in *dynamicpb.Message) (int, []openapi.FieldError) {
+	var errs []openapi.FieldError
+	if rt.Body != "" {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+		if err != nil {
+			return http.StatusBadRequest, []openapi.FieldError{{Field: "body", Reason: "unreadable"}}
+		}
+		if len(body) > maxBody {
+			return http.StatusRequestEntityTooLarge, []openapi.FieldError{{Field: "body", Reason: "must be at most 1 MiB"}}
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			if ct := r.Header.Get("Content-Type"); ct != "" {
+				if mt, _, _ := mime.ParseMediaType(ct); mt != "application/json" {
+					return http.StatusUnsupportedMediaType, []openapi.FieldError{{Field: "Content-Type", Reason: "must be application/json"}}
+				}
+			}
+			target := in.ProtoReflect()
+			if rt.Body != "*" {
+				target = target.Mutable(lookup(in.Descriptor(), rt.Body)).Message()
+			}
+			if err := protojson.Unmarshal(body, target.Interface()); err != nil {
+				errs = append(errs, openapi.FieldError{Field: "body", Reason: protoError(err)})
+			}
+		}
+	}
+
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if rt.Body == "*" {
+			errs = append(errs, openapi.FieldError{Field: k, Reason: "unknown query parameter"})
+			continue
+		}
+		if err := setField(in, k, query[k]); err != nil {
+			errs = append(errs, openapi.FieldError{Field: k, Reason: err.Error()})
+		}
+	}
+
+	vars := mux.Vars(r)
+	for _, name := range rt.Vars {
+		if err := setField(in, name, []string{vars[name]}); err != nil {
+			errs = append(errs, openapi.FieldError{Field: name, Reason: err.Error()})
+		}
+	}
+	return http.StatusBadRequest, errs
+}
+
+// lookup: field berdasarkan path bertitik (a.b), nama proto atau JSON.
+func lookup(md protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var fd protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return nil
+			}
+			md = fd.Message()
+		}
+		fields := md.Fields()
+		if fd = fields.ByName(protoreflect.Name(name)); fd == nil {
+			if fd = fields.ByJSONName(name); fd == nil {
+				return nil
+			}
+		}
+	}
+	return fd
+}
+
+var errUnknownField = errors.New("unknown field")
+
+func setField(m protoreflect.ProtoMessage, path string, values []string) error {
+	msg := m.ProtoReflect()
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		fd := lookup(msg.Descriptor(), name)
+		if fd == nil || fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return errUnknownField
+		}
+		msg = msg.Mutable(fd).Message()
+	}
+	fd := lookup(msg.Descriptor(), names[len(names)-1])
+	if fd == nil || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return errUnknownField
+	}
+	if fd.IsList() {
+		list := msg.Mutable(fd).List()
+		for _, s := range values {
+			v, err := parseScalar(fd, s)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+	if len(values) != 1 {
+		return errors.New("must not be repeated")
+	}
+	v, err := parseScalar(fd, values[0])
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return protoreflect.ValueOfBool(b), nil
+		}
+		return protoreflect.Value{}, errors.New("must be a boolean")
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return protoreflect.ValueOfInt32(int32(n)), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return protoreflect.ValueOfInt64(n), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+			return protoreflect.ValueOfUint32(uint32(n)), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return protoreflect.ValueOfUint64(n), nil
+		}
+	case protoreflect.FloatKind:
+		if f, err := strconv.ParseFloat(s, 32); err == nil {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+		return protoreflect.Value{}, errors.New("must be a number")
+	case protoreflect.DoubleKind:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return protoreflect.ValueOfFloat64(f), nil
+		}
+		return protoreflect.Value{}, errors.New("must be a number")
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		if n, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+		}
+		return protoreflect.Value{}, fmt.Errorf("must be a %s value", fd.Enum().Name())
+	case protoreflect.BytesKind:
+		if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+			return protoreflect.ValueOfBytes(b), nil
+		}
+		if b, err := base64.URLEncoding.DecodeString(s); err == nil {
+			return protoreflect.ValueOfBytes(b), nil
+		}
+		return protoreflect.Value{}, errors.New("must be base64")
+	default:
+		return protoreflect.Value{}, errUnknownField
+	}
+	return protoreflect.Value{}, errors.New("must be an integer")
+}
+
+// protoError: pesan protojson tanpa prefix "proto:".
+func protoError(err error) string {
+	msg := err.Error()
+	if rest, ok := strings.CutPrefix(msg, "proto:"); ok {
+		msg = rest
+	}
+	return "malformed JSON: " + strings.TrimSpace(msg)
+}
+
+// HTTPStatus: pemetaan kode gRPC → HTTP, sama dengan grpc-gateway.
+func HTTPStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteError: status gRPC sebagai RFC 7807 problem+json (pkg/errors):
+// kode katalog dari ErrorInfo upstream, selain itu kode generik dari kode
+// gRPC.
+func WriteError(w http.ResponseWriter, r *http.Request, st *status.Status) {
+	perrors.Write(w, r, st.Err())
+}
@@ -0,0 +1,210 @@
+// services/api-gateway/transcode/transcode_test.go
+package transcode_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	commonv1 "github.com/example/payment-gateway-poc/proto/gen/common/v1"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	walletv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/openapi"
+	"github.com/example/payment-gateway-poc/services/api-gateway/transcode"
+)
+
+type fakeWallet struct {
+	walletv1.UnimplementedWalletServiceServer
+}
+
+func (fakeWallet) GetBalance(ctx context.Context, req *walletv1.GetBalanceRequest) (*walletv1.GetBalanceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get(tenant.MetadataKey); len(got) == 0 || got[0] != "m_1" {
+		return nil, status.Errorf(codes.PermissionDenied, "tenant %v", got)
+	}
+	if req.GetAccountId() != "ACC-1" {
+		return nil, perrors.New(perrors.AccountNotFound, "account %s not found", req.GetAccountId()).
+			With("account_id", req.GetAccountId())
+	}
+	return &walletv1.GetBalanceResponse{BalanceMinor: 1500, Currency: commonv1.Currency_IDR}, nil
+}
+
+func (fakeWallet) Reserve(_ context.Context, req *walletv1.ReserveRequest) (*walletv1.ReserveResponse, error) {
+	return &walletv1.ReserveResponse{Ok: true, ReservationId: req.GetAccountId() + "/" + req.GetPaymentId()}, nil
+}
+
+func (fakeWallet) Capture(_ context.Context, req *walletv1.CaptureRequest) (*walletv1.CaptureResponse, error) {
+	return &walletv1.CaptureResponse{Ok: req.GetReservationId() == "R-1"}, nil
+}
+
+type fakeFx struct {
+	fxv1.UnimplementedFxServiceServer
+}
+
+func (fakeFx) Convert(_ context.Context, req *fxv1.ConvertRequest) (*fxv1.ConvertResponse, error) {
+	if req.GetFromCurrency() != "USD" || req.GetToCurrency() != "IDR" {
+		return nil, status.Error(codes.InvalidArgument, "unsupported pair")
+	}
+	return &fxv1.ConvertResponse{Amount: req.GetAmount() * 15000}, nil
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	walletv1.RegisterWalletServiceServer(gs, fakeWallet{})
+	fxv1.RegisterFxServiceServer(gs, fakeFx{})
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tenant.UnaryClientInterceptor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	routes, err := transcode.Routes(map[string]grpc.ClientConnInterface{
+		walletv1.WalletService_ServiceDesc.ServiceName: conn,
+		fxv1.FxService_ServiceDesc.ServiceName:         conn,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := mux.NewRouter()
+	// merchant "terverifikasi" → tenant ikut ke metadata gRPC
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithMerchant(r.Context(), "m_1")))
+		})
+	})
+	transcode.Mount(r, routes, transcode.Options{})
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTranscode(t *testing.T) {
+	srv := newServer(t)
+	cases := []struct {
+		name, method, path, body string
+		status                   int
+		want                     string
+	}{
+		{"path var", http.MethodGet, "/v1/wallet/accounts/ACC-1/balance", "",
+			http.StatusOK, `{"balance_minor":"1500","currency":"IDR"}`},
+		{"query", http.MethodGet, "/v1/fx/convert?from_currency=USD&to_currency=IDR&amount=2", "",
+			http.StatusOK, `{"amount":30000}`},
+		{"additional binding", http.MethodPost, "/v1/fx:convert", `{"from_currency":"USD","toCurrency":"IDR","amount":1}`,
+			http.StatusOK, `{"amount":15000}`},
+		{"body and path var", http.MethodPost, "/v1/wallet/accounts/ACC-9/reservations", `{"payment_id":"P-1","amount_minor":"10","currency":"IDR"}`,
+			http.StatusOK, `{"ok":true,"reservation_id":"ACC-9/P-1","reason":""}`},
+		{"verb suffix", http.MethodPost, "/v1/wallet/reservations/R-1:capture", ``,
+			http.StatusOK, `{"ok":true,"reason":""}`},
+		{"not found", http.MethodGet, "/v1/wallet/accounts/ACC-2/balance", "",
+			http.StatusNotFound, `{"type":"https://payment-gateway-poc.example/errors/account-not-found","title":"Account not found","status":404,
+				"detail":"account ACC-2 not found","instance":"/v1/wallet/accounts/ACC-2/balance","code":"ACCOUNT_NOT_FOUND","metadata":{"account_id":"ACC-2"}}`},
+		{"invalid argument", http.MethodGet, "/v1/fx/convert?from_currency=EUR&to_currency=IDR", "",
+			http.StatusBadRequest, `{"type":"https://payment-gateway-poc.example/errors/invalid-argument","title":"Invalid request","status":400,
+				"detail":"unsupported pair","instance":"/v1/fx/convert","code":"INVALID_ARGUMENT"}`},
+		{"unimplemented", http.MethodGet, "/v1/wallet/random-accounts?count=2", "",
+			http.StatusNotImplemented, ``},
+		{"bad query value", http.MethodGet, "/v1/fx/convert?amount=abc&colour=red", "",
+			http.StatusBadRequest, `{"type":"https://payment-gateway-poc.example/errors/invalid-argument","title":"Invalid request","status":400,
+				"detail":"invalid request: amount: must be a number; colour: unknown field","instance":"/v1/fx/convert","code":"INVALID_ARGUMENT",
+				"fields":[{"field":"amount","reason":"must be a number"},{"field":"colour","reason":"unknown field"}]}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tc.status {
+				t.Fatalf("status %d, want %d: %s", resp.StatusCode, tc.status, b)
+			}
+			if tc.want != "" && !jsonEqual(t, b, tc.want) {
+				t.Fatalf("body %s, want %s", b, tc.want)
+			}
+		})
+	}
+}
+
+func TestTranscodeRejectsUnknownBodyField(t *testing.T) {
+	srv := newServer(t)
+	resp, err := http.Post(srv.URL+"/v1/fx:convert", "application/json", strings.NewReader(`{"from_currency":"USD","amout":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out perrors.Problem
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode != http.StatusBadRequest || len(out.Fields) != 1 || !strings.Contains(out.Fields[0].Reason, `unknown field "amout"`) {
+		t.Fatalf("status %d, body %+v", resp.StatusCode, out)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	routes, err := transcode.Routes(map[string]grpc.ClientConnInterface{
+		walletv1.WalletService_ServiceDesc.ServiceName: nil,
+		fxv1.FxService_ServiceDesc.ServiceName:         nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, schemas := transcode.OpenAPI(routes, nil)
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Extend(paths, schemas); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	doc.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	// dokumen gabungan harus tetap bisa di-parse (semua $ref ter-resolve)
+	if _, err := openapi.Parse(rec.Body.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/v1/wallet/accounts/{account_id}/balance", "/v1/fx/convert", "/v1/fx:convert", "/v1/wallet/reservations/{reservation_id}:capture"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("%s missing", p)
+		}
+	}
+	if err := doc.Extend(paths, nil); err == nil {
+		t.Fatal("duplicate paths accepted")
+	}
+}
+
+func jsonEqual(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatalf("response is not JSON: %s", got)
+	}
+	_ = json.Unmarshal([]byte(want), &b)
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
@@ -42,3 +42,23 @@ CREATE TABLE IF NOT EXISTS payments (
   status           TEXT NOT NULL CHECK (status IN ('SUCCESS','FAILED')),
   created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
 );
+
+-- merchants (tenant); akun & payment tanpa merchant = namespace bersama (demo)
+CREATE TABLE IF NOT EXISTS merchants (
+  merchant_id         TEXT PRIMARY KEY,
+  name                TEXT NOT NULL,
+  settlement_currency TEXT NOT NULL DEFAULT 'IDR',
+  fee_plan_name       TEXT NOT NULL DEFAULT '',
+  fee_percent_bps     BIGINT NOT NULL DEFAULT 0,
+  fee_fixed_idr       BIGINT NOT NULL DEFAULT 0,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE wallet_accounts ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS wallet_accounts_merchant_idx ON wallet_accounts (merchant_id);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT '';
+-- fee dipotong dari kredit receiver; nilai bersih dalam mata uang settlement merchant
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS fee_idr BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS settlement_currency TEXT NOT NULL DEFAULT 'IDR';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS settlement_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- alasan FAILED (insufficient_funds, receiver_not_found, ...), dibaca gateway saat polling
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
@@ -6,17 +6,56 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"net"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/example/payment-gateway-poc/pkg/config"
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/health"
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+	"github.com/example/payment-gateway-poc/pkg/server"
 	pb "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
 	"google.golang.org/grpc"
 )
 
-type server struct {
+type fxServer struct {
 	pb.UnimplementedFxServiceServer
+	seedsDir string
+	// updated: UnixNano UpdateRates terakhir yang berhasil (awal: mtime
+	// fx_rates.json), untuk readiness FX_MAX_RATE_AGE
+	updated atomic.Int64
+	// maxAge: Convert gagal FX_RATE_STALE bila kurs lebih tua; 0 = tidak dicek
+	maxAge time.Duration
+
+	mu    sync.RWMutex
+	rates map[string]float64 // "USD/IDR" → kurs
+}
+
+func newFxServer(seedsDir string, maxAge time.Duration) *fxServer {
+	s := &fxServer{seedsDir: seedsDir, maxAge: maxAge}
+	path := filepath.Join(seedsDir, "fx_rates.json")
+	if fi, err := os.Stat(path); err == nil {
+		s.updated.Store(fi.ModTime().UnixNano())
+	}
+	rates, err := readRates(path)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("fx rates: read seeds file", "path", path, "error", err)
+	}
+	s.rates = rates
+	return s
+}
+
+func (s *fxServer) lastUpdate() time.Time {
+	if n := s.updated.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
 }
 
 // bentuk JSON yang akan disimpan: { "rates": [ ... ] }
@@ -29,10 +68,77 @@ type filePayload struct {
 	Rates []rateJSON `json:"rates"`
 }
 
-func (s *server) UpdateRates(ctx context.Context, req *pb.UpdateRatesRequest) (*pb.UpdateRatesResponse, error) {
-	// log ke console
+// seedRate: entri fx_rates.json, baik dari UpdateRates (base/quote/rate)
+// maupun seed awal ({"pair":"USD/IDR","mid":...}).
+type seedRate struct {
+	rateJSON
+	Pair string  `json:"pair"`
+	Mid  float64 `json:"mid"`
+}
+
+func readRates(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f struct {
+		Rates []seedRate `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	rates := make(map[string]float64, len(f.Rates))
+	for _, r := range f.Rates {
+		pair, rate := r.BaseCurrency+"/"+r.QuoteCurrency, r.Rate
+		if r.Pair != "" {
+			pair, rate = r.Pair, r.Mid
+		}
+		if rate > 0 {
+			rates[strings.ToUpper(pair)] = rate
+		}
+	}
+	return rates, nil
+}
+
+// Convert: amount × kurs from/to (atau 1/kurs to/from). Kurs lebih tua dari
+// FX_MAX_RATE_AGE → FX_RATE_STALE; pasangan tidak ada → FX_RATE_UNAVAILABLE.
+func (s *fxServer) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
+	from := strings.ToUpper(strings.TrimSpace(req.GetFromCurrency()))
+	to := strings.ToUpper(strings.TrimSpace(req.GetToCurrency()))
+	if from == "" || to == "" {
+		return nil, perrors.New(perrors.InvalidArgument, "from_currency and to_currency required")
+	}
+	if req.GetAmount() < 0 {
+		return nil, perrors.New(perrors.InvalidArgument, "amount must be >= 0")
+	}
+	if from == to {
+		return &pb.ConvertResponse{Amount: req.GetAmount()}, nil
+	}
+	pair := from + "/" + to
+	if s.maxAge > 0 {
+		if at := s.lastUpdate(); time.Since(at) > s.maxAge {
+			return nil, perrors.New(perrors.FXRateStale, "rate %s older than %s", pair, s.maxAge).
+				With("pair", pair).With("updated_at", at.UTC().Format(time.RFC3339))
+		}
+	}
+
+	s.mu.RLock()
+	rate, ok := s.rates[pair]
+	if !ok {
+		if inv, found := s.rates[to+"/"+from]; found {
+			rate, ok = 1/inv, true
+		}
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return nil, perrors.New(perrors.FXRateUnavailable, "no rate for %s", pair).With("pair", pair)
+	}
+	return &pb.ConvertResponse{Amount: req.GetAmount() * rate}, nil
+}
+
+func (s *fxServer) UpdateRates(ctx context.Context, req *pb.UpdateRatesRequest) (*pb.UpdateRatesResponse, error) {
 	for _, r := range req.Rates {
-		log.Printf("[FX] Update rate: %s/%s = %.6f", r.BaseCurrency, r.QuoteCurrency, r.Rate)
+		slog.InfoContext(ctx, "fx rate updated", "base", r.BaseCurrency, "quote", r.QuoteCurrency, "rate", r.Rate)
 	}
 
 	// map dari protobuf ke bentuk JSON
@@ -45,13 +151,23 @@ func (s *server) UpdateRates(ctx context.Context, req *pb.UpdateRatesRequest) (*
 		})
 	}
 
-	// tulis ke seeds/fx_rates.json (atomic write)
-	if err := writeRatesJSON("seeds", "fx_rates.json", filePayload{Rates: out}); err != nil {
-		log.Printf("[FX] write JSON error: %v", err)
+	// tulis ke <seeds_dir>/fx_rates.json (atomic write)
+	if err := writeRatesJSON(s.seedsDir, "fx_rates.json", filePayload{Rates: out}); err != nil {
+		slog.ErrorContext(ctx, "fx rates: write seeds file", "error", err)
 		return &pb.UpdateRatesResponse{Success: false, Message: fmt.Sprintf("write error: %v", err)}, nil
 	}
+	rates := make(map[string]float64, len(out))
+	for _, r := range out {
+		if r.Rate > 0 {
+			rates[strings.ToUpper(r.BaseCurrency+"/"+r.QuoteCurrency)] = r.Rate
+		}
+	}
+	s.mu.Lock()
+	s.rates = rates
+	s.mu.Unlock()
+	s.updated.Store(time.Now().UnixNano())
 
-	return &pb.UpdateRatesResponse{Success: true, Message: "Rates updated & saved to seeds/fx_rates.json"}, nil
+	return &pb.UpdateRatesResponse{Success: true, Message: "Rates updated & saved to " + filepath.Join(s.seedsDir, "fx_rates.json")}, nil
 }
 
 func writeRatesJSON(dir, filename string, payload filePayload) error {
@@ -103,14 +219,25 @@ func writeRatesJS(dir, filename string, payload filePayload) error {
 */
 
 func main() {
-	lis, err := net.Listen("tcp", ":9102")
+	cfg := config.DefaultFX()
+	config.MustLoad("fx-grpc", &cfg)
+
+	srv, err := server.New(server.Options{
+		Name:   "fx-grpc",
+		Config: cfg.Server,
+		TLS:    cfg.TLS,
+		// RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+		Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+	})
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logging.Fatal("init server", "error", err)
+	}
+	fx := newFxServer(cfg.SeedsDir, cfg.MaxRateAge)
+	pb.RegisterFxServiceServer(srv.GRPC, fx)
+	if cfg.MaxRateAge > 0 {
+		srv.Check("fx_rates", health.Fresh(fx.lastUpdate, cfg.MaxRateAge))
 	}
-	s := grpc.NewServer()
-	pb.RegisterFxServiceServer(s, &server{})
-	log.Println("FX gRPC server listening on :9102")
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
 	}
 }
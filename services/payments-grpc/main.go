@@ -1,34 +1,30 @@
 package main
 
 import (
-	"log"
-	"net"
-	"net/http"
+	"context"
 
-	gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+	"github.com/example/payment-gateway-poc/pkg/server"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-		grpc.StreamInterceptor(gp.StreamServerInterceptor),
-	)
-	gp.Register(grpcServer)
+	cfg := config.DefaultPayments()
+	config.MustLoad("payments-grpc", &cfg)
 
-	lis, err := net.Listen("tcp", ":9091")
+	srv, err := server.New(server.Options{
+		Name:   "payments-grpc",
+		Config: cfg.Server,
+		TLS:    cfg.TLS,
+		// RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+		Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+	})
 	if err != nil {
-		log.Fatalf("[payments-grpc] listen error: %v", err)
+		logging.Fatal("init server", "error", err)
+	}
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
 	}
-	go func() {
-		log.Println("[payments-grpc] serving gRPC on :9091")
-		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("[payments-grpc] serve error: %v", err)
-		}
-	}()
-
-	http.Handle("/metrics", promhttp.Handler())
-	log.Println("[payments-grpc] serving metrics on :9101 /metrics")
-	log.Fatal(http.ListenAndServe(":9101", nil))
 }
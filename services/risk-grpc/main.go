@@ -3,34 +3,32 @@
 package main
 
 import (
-	"log"
-	"net"
-	"net/http"
+	"context"
 
-	gp "github.com/grpc-ecosystem/go-grpc-prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/example/payment-gateway-poc/pkg/config"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+	"github.com/example/payment-gateway-poc/pkg/server"
+	riskv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(gp.UnaryServerInterceptor),
-		grpc.StreamInterceptor(gp.StreamServerInterceptor),
-	)
-	gp.Register(grpcServer)
+	cfg := config.DefaultRisk()
+	config.MustLoad("risk-grpc", &cfg)
 
-	lis, err := net.Listen("tcp", ":9094")
+	srv, err := server.New(server.Options{
+		Name:   "risk-grpc",
+		Config: cfg.Server,
+		TLS:    cfg.TLS,
+		// RPC Admin: token ber-role (ADMIN_TOKEN_SECRET) + audit log
+		Unary: []grpc.UnaryServerInterceptor{rbac.NewGuardFromEnv().UnaryServerInterceptor()},
+	})
 	if err != nil {
-		log.Fatalf("[risk-grpc] listen error: %v", err)
+		logging.Fatal("init server", "error", err)
+	}
+	riskv1.RegisterRiskServiceServer(srv.GRPC, NewRiskServiceFromEnv())
+	if err := srv.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped", "error", err)
 	}
-	go func() {
-		log.Println("[risk-grpc] serving gRPC on :9094")
-		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("[risk-grpc] serve error: %v", err)
-		}
-	}()
-
-	http.Handle("/metrics", promhttp.Handler())
-	log.Println("[risk-grpc] serving metrics on :9104 /metrics")
-	log.Fatal(http.ListenAndServe(":9104", nil))
 }
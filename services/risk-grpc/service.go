@@ -4,7 +4,7 @@ package main
 import (
 	"context"
 	"crypto/rand"
-	"log"
+	"log/slog"
 	"math/big"
 	"time"
 
@@ -46,7 +46,7 @@ func hitTwoPercent() bool {
 	n, err := rand.Int(rand.Reader, big.NewInt(100)) // 0..99
 	if err != nil {
 		// kalau gagal random cryptographic, fallback aman: tidak reject
-		log.Printf("[risk] crypto/rand error: %v", err)
+		slog.Error("risk: crypto/rand failed", "error", err)
 		return false
 	}
 	return n.Int64() < 2 // 0 atau 1 => 2%
@@ -0,0 +1,118 @@
+// payment-gateway-poc/tests/integration/grpc_gateway_test.go
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/example/payment-gateway-poc/pkg/tenant"
+	commonv1 "github.com/example/payment-gateway-poc/proto/gen/common/v1"
+	paymentsv1 "github.com/example/payment-gateway-poc/proto/gen/payments/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/grpcweb"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/ratelimit"
+)
+
+// upstreamPayments: payments-grpc palsu; mencatat request yang sampai.
+type upstreamPayments struct {
+	paymentsv1.PaymentsServiceClient
+	mu   sync.Mutex
+	seen []string // tenant|client_tx_id
+}
+
+func (u *upstreamPayments) CreatePayment(ctx context.Context, in *paymentsv1.CreatePaymentRequest, _ ...grpc.CallOption) (*paymentsv1.CreatePaymentResponse, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.seen = append(u.seen, tenant.ID(ctx)+"|"+in.GetClientTxId())
+	return &paymentsv1.CreatePaymentResponse{PaymentId: "pay_1", Status: "PENDING"}, nil
+}
+
+func TestPaymentsServiceOnGatewayPort(t *testing.T) {
+	ctx := context.Background()
+	keys := auth.NewMemoryKeys()
+	key, err := auth.NewKey("M-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Create(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	merchants := merchant.NewMemory(&memLedger{bal: map[string]int64{"A-1": 0, "A-2": 0, "B-1": 0}})
+	for _, id := range []string{"M-1", "M-2"} {
+		mc := merchant.Merchant{ID: id, Name: id}
+		if err := mc.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		if err := merchants.Create(ctx, &mc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for acc, owner := range map[string]string{"A-1": "M-1", "A-2": "M-1", "B-1": "M-2"} {
+		if err := merchants.AssignAccount(ctx, owner, acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, err := ratelimit.ParseRules("POST /payments.v1.PaymentsService/CreatePayment merchant=2/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := &ratelimit.Limiter{Rules: rules, Store: ratelimit.NewMemory()}
+	verifier := &auth.Verifier{Keys: keys, Nonces: auth.NewMemoryNonces(), Mode: auth.ModeRequired}
+
+	upstream := &upstreamPayments{}
+	gs := grpc.NewServer()
+	paymentsv1.RegisterPaymentsServiceServer(gs, &handlers.PaymentsFacade{Upstream: upstream, Merchants: merchants})
+	defer gs.Stop()
+	grpcHandler := grpcweb.Status(verifier.Middleware(limiter.Middleware(grpcweb.Wrap(gs))))
+	srv := httptest.NewServer(grpcweb.Mux(http.NotFoundHandler(), grpcHandler))
+	defer srv.Close()
+
+	dial := func(opts ...grpc.DialOption) paymentsv1.PaymentsServiceClient {
+		conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"),
+			append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return paymentsv1.NewPaymentsServiceClient(conn)
+	}
+	signed := dial(grpc.WithUnaryInterceptor(auth.UnaryClientInterceptor(key.ID, key.Secret)))
+	anonymous := dial()
+
+	req := func(to, tx string) *paymentsv1.CreatePaymentRequest {
+		return &paymentsv1.CreatePaymentRequest{AmountMinor: 1000, Currency: commonv1.Currency_IDR,
+			SourceAccount: "A-1", DestinationAccount: to, ClientTxId: tx}
+	}
+
+	if _, err := anonymous.CreatePayment(ctx, req("A-2", "tx-0")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous: %v", err)
+	}
+	res, err := signed.CreatePayment(ctx, req("A-2", "tx-1"))
+	if err != nil || res.GetPaymentId() != "pay_1" {
+		t.Fatalf("signed: %v %v", res, err)
+	}
+	if _, err := signed.CreatePayment(ctx, req("B-1", "tx-2")); status.Code(err) != codes.NotFound {
+		t.Fatalf("foreign account: %v", err)
+	}
+	if _, err := signed.CreatePayment(ctx, req("A-2", "tx-3")); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("rate limit: %v", err)
+	}
+	if _, err := signed.LogAndSettle(ctx, &paymentsv1.LogAndSettleRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("internal RPC: %v", err)
+	}
+	if len(upstream.seen) != 1 || upstream.seen[0] != "M-1|M-1:tx-1" {
+		t.Fatalf("upstream saw %v", upstream.seen)
+	}
+}
@@ -0,0 +1,115 @@
+// payment-gateway-poc/tests/integration/idempotency_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/idempotency"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+// countingFx: setiap request yang benar-benar diproses memanggil FX sekali.
+type countingFx struct {
+	fakeFx
+	calls *atomic.Int32
+}
+
+func (f countingFx) Convert(ctx context.Context, in *fxv1.ConvertRequest, opts ...grpc.CallOption) (*fxv1.ConvertResponse, error) {
+	f.calls.Add(1)
+	time.Sleep(20 * time.Millisecond) // biar duplikat konkuren benar-benar tumpang tindih
+	return f.fakeFx.Convert(ctx, in, opts...)
+}
+
+func TestIdempotentPayments(t *testing.T) {
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 10_000_000}, seen: map[string]bool{}}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 2}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(handlers.Idempotent(idempotency.NewMemory(idempotency.Config{}), 5*time.Second,
+		handlers.PaymentsHandler(handlers.Deps{
+			Fx: countingFx{calls: &calls}, Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{}, Bus: bus, Store: store.NewMemory(0),
+		})))
+	defer srv.Close()
+
+	post := func(key string, amount float64) (*http.Response, []byte) {
+		body, _ := json.Marshal(handlers.PaymentIn{
+			SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "USD",
+			Amount: amount, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: key,
+		})
+		resp, err := http.Post(srv.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Error(err)
+			return nil, nil
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, b
+	}
+
+	// duplikat konkuren: satu yang jalan, sisanya menunggu dan dapat response yang sama
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		bodies = map[string]int{}
+	)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, b := post("idem-1", 10)
+			if resp == nil || resp.StatusCode != http.StatusOK {
+				t.Errorf("concurrent duplicate: %v", resp)
+				return
+			}
+			mu.Lock()
+			bodies[string(b)]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("FX called %d times, want 1", n)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("duplicates got different responses: %v", bodies)
+	}
+
+	// replay setelah selesai
+	resp, _ := post("idem-1", 10)
+	if resp.Header.Get("Idempotent-Replayed") != "true" || calls.Load() != 1 {
+		t.Fatalf("replay re-ran the payment (calls=%d)", calls.Load())
+	}
+	var out handlers.PaymentOut
+	for b := range bodies {
+		_ = json.Unmarshal([]byte(b), &out)
+	}
+	if out.Status != "SUCCESS" {
+		t.Fatalf("stored response %+v", out)
+	}
+
+	// key sama, payload beda
+	if resp, _ := post("idem-1", 11); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,151 @@
+// payment-gateway-poc/tests/integration/logging_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/logging"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+// syncBuffer: log ditulis dari goroutine handler, gRPC, dan worker.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// loggingFx: FX yang mencatat log dengan ctx RPC, seperti service sungguhan.
+type loggingFx struct {
+	fxv1.UnimplementedFxServiceServer
+}
+
+func (loggingFx) Convert(ctx context.Context, in *fxv1.ConvertRequest) (*fxv1.ConvertResponse, error) {
+	slog.InfoContext(ctx, "fx convert")
+	return &fxv1.ConvertResponse{Amount: in.GetAmount() * 15000}, nil
+}
+
+// Satu payment: log gateway, FX (metadata gRPC) dan worker (header Kafka)
+// membawa correlation id, idempotency key, dan payment id yang sama.
+func TestPaymentLogsCorrelated(t *testing.T) {
+	var out syncBuffer
+	prev := slog.Default()
+	slog.SetDefault(logging.New(&out, "test", "json"))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	gs := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor()))
+	fxv1.RegisterFxServiceServer(gs, loggingFx{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 1_000_000}, seen: map[string]bool{}}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	stopped := make(chan struct{})
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")); close(stopped) }()
+
+	r := mux.NewRouter()
+	r.Use(logging.Middleware)
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx: fxv1.NewFxServiceClient(conn), Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{},
+		Bus: bus, Store: store.NewMemory(0),
+	})).Methods(http.MethodPost)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "USD",
+		Amount: 10, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "log-1",
+	})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/payments", bytes.NewReader(body))
+	req.Header.Set(logging.HeaderCorrelationID, "corr-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var pay handlers.PaymentOut
+	if err := json.Unmarshal(b, &pay); err != nil || pay.Status != "SUCCESS" {
+		t.Fatalf("payment: %s %v", b, err)
+	}
+	if got := resp.Header.Get(logging.HeaderCorrelationID); got != "corr-1" {
+		t.Errorf("response %s = %q", logging.HeaderCorrelationID, got)
+	}
+	cancel()
+	<-stopped
+
+	want := map[string]string{
+		logging.FieldCorrelationID:  "corr-1",
+		logging.FieldIdempotencyKey: "log-1",
+		logging.FieldPaymentID:      pay.PaymentID,
+	}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("not JSON: %q", line)
+		}
+		msg, _ := rec["msg"].(string)
+		switch msg {
+		case "payment received", "fx convert", "payment published", "payment settled", "payment completed":
+		default:
+			continue
+		}
+		seen[msg] = true
+		for k, v := range want {
+			if rec[k] != v {
+				t.Errorf("%q: %s = %v, want %q", msg, k, rec[k], v)
+			}
+		}
+	}
+	for _, msg := range []string{"payment received", "fx convert", "payment published", "payment settled", "payment completed"} {
+		if !seen[msg] {
+			t.Errorf("no %q log line in:\n%s", msg, out.String())
+		}
+	}
+}
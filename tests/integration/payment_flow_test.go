@@ -0,0 +1,292 @@
+// payment-gateway-poc/tests/integration/payment_flow_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	riskv1 "github.com/example/payment-gateway-poc/proto/gen/risk/v1"
+	wv1 "github.com/example/payment-gateway-poc/proto/gen/wallet/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+type fakeFx struct{ fxv1.FxServiceClient }
+
+func (fakeFx) Convert(ctx context.Context, in *fxv1.ConvertRequest, _ ...grpc.CallOption) (*fxv1.ConvertResponse, error) {
+	if in.GetFromCurrency() == "IDR" {
+		return &fxv1.ConvertResponse{Amount: in.GetAmount() / 15000}, nil
+	}
+	return &fxv1.ConvertResponse{Amount: in.GetAmount() * 15000}, nil
+}
+
+type fakeWallet struct {
+	wv1.WalletServiceClient
+	balances *memLedger
+}
+
+func (f fakeWallet) GetAccount(ctx context.Context, in *wv1.GetAccountRequest, _ ...grpc.CallOption) (*wv1.GetAccountResponse, error) {
+	return &wv1.GetAccountResponse{AccountId: in.GetAccountId(), BalanceIdr: f.balances.get(in.GetAccountId())}, nil
+}
+
+type fakeRisk struct{ riskv1.RiskServiceClient }
+
+func (fakeRisk) Evaluate(ctx context.Context, in *riskv1.ScoreRequest, _ ...grpc.CallOption) (*riskv1.EvaluateResponse, error) {
+	return &riskv1.EvaluateResponse{Allow: true}, nil
+}
+
+// memLedger: pengganti Postgres untuk worker.Settler dan merchant.Ledger.
+// Seperti wallet_accounts, akun hanya dipakai payment dengan merchant yang
+// sama dengan pemiliknya ("" = akun bersama).
+type memLedger struct {
+	mu     sync.Mutex
+	bal    map[string]int64
+	seen   map[string]bool
+	paid   []worker.Payment  // payment yang settle, urut
+	owners map[string]string // account_id → merchant_id
+}
+
+func (l *memLedger) AssignAccount(_ context.Context, merchantID, accountID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.bal[accountID]; !ok {
+		return merchant.ErrAccountNotFound
+	}
+	if owner := l.owners[accountID]; owner != "" && owner != merchantID {
+		return merchant.ErrAccountTaken
+	}
+	if l.owners == nil {
+		l.owners = map[string]string{}
+	}
+	l.owners[accountID] = merchantID
+	return nil
+}
+
+func (l *memLedger) get(id string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bal[id]
+}
+
+func (l *memLedger) Settle(ctx context.Context, p worker.Payment) (worker.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen[p.IdempotencyKey] {
+		return worker.Result{Status: "SUCCESS_REPLAY", Ref: p.IdempotencyKey}, nil
+	}
+	if l.bal[p.SenderID] < p.AmountIDR || l.owners[p.SenderID] != p.MerchantID {
+		return worker.Result{Status: "FAILED", Reason: "insufficient_funds"}, nil
+	}
+	if l.owners[p.ReceiverID] != p.MerchantID {
+		return worker.Result{Status: "FAILED", Reason: "receiver_not_found"}, nil
+	}
+	l.seen[p.IdempotencyKey] = true
+	l.bal[p.SenderID] -= p.AmountIDR
+	l.bal[p.ReceiverID] += p.AmountIDR - p.FeeIDR
+	l.paid = append(l.paid, p)
+	return worker.Result{Status: "SUCCESS", Ref: p.IdempotencyKey}, nil
+}
+
+func TestPaymentFlowInMemory(t *testing.T) {
+	ledger := &memLedger{
+		bal:  map[string]int64{"ACC-1": 1_000_000, "ACC-2": 0},
+		seen: map[string]bool{},
+	}
+
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 2}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	payments := store.NewMemory(0)
+	r := mux.NewRouter()
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx:     fakeFx{},
+		Wallet: fakeWallet{balances: ledger},
+		Risk:   fakeRisk{},
+		Bus:    bus,
+		Store:  payments,
+	})).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", handlers.PaymentStatusHandler(payments)).Methods(http.MethodGet)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	post := func(key string, amount float64, async bool) *http.Response {
+		body, _ := json.Marshal(handlers.PaymentIn{
+			SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "USD",
+			Amount: amount, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: key,
+		})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/payments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if async {
+			req.Header.Set("Prefer", "respond-async")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	pay := func(key string, amount float64) handlers.PaymentOut {
+		resp := post(key, amount, false)
+		defer resp.Body.Close()
+		var out handlers.PaymentOut
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := pay("k-1", 10); out.Status != "SUCCESS" {
+		t.Fatalf("expected SUCCESS, got %+v", out)
+	}
+	if got := ledger.get("ACC-2"); got != 150_000 {
+		t.Fatalf("receiver balance = %d, want 150000", got)
+	}
+	if out := pay("k-1", 10); out.Status != "SUCCESS_REPLAY" {
+		t.Fatalf("expected replay, got %+v", out)
+	}
+	// ditolak sebelum publish: problem+json dengan kode katalog
+	resp := post("k-2", 1000, false)
+	defer resp.Body.Close()
+	var prob perrors.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&prob); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity || prob.Code != perrors.InsufficientFunds ||
+		resp.Header.Get("Content-Type") != perrors.ContentType {
+		t.Fatalf("expected INSUFFICIENT_FUNDS, got %d %+v", resp.StatusCode, prob)
+	}
+}
+
+func TestPaymentFlowAsyncPolling(t *testing.T) {
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 1_000_000}, seen: map[string]bool{}}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	payments := store.NewMemory(0)
+	r := mux.NewRouter()
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx: fakeFx{}, Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{}, Bus: bus, Store: payments,
+	})).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", handlers.PaymentStatusHandler(payments)).Methods(http.MethodGet)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "IDR",
+		Amount: 50_000, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "async-1",
+	})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/payments", bytes.NewReader(body))
+	req.Header.Set("Prefer", "respond-async")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		t.Fatal("missing Location header")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(srv.URL + loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p store.Payment
+		_ = json.NewDecoder(resp.Body).Decode(&p)
+		resp.Body.Close()
+		if p.Status == "SUCCESS" {
+			return
+		}
+		if p.Status != store.StatusPending || time.Now().After(deadline) {
+			t.Fatalf("unexpected status %+v", p)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Worker belum membalas dalam SyncWait: 202 PENDING + Location, bukan
+// FAILED; payment tetap settle belakangan.
+func TestPaymentFlowSyncTimeoutPending(t *testing.T) {
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 1_000_000}, seen: map[string]bool{}}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+
+	payments := store.NewMemory(0)
+	r := mux.NewRouter()
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx: fakeFx{}, Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{}, Bus: bus, Store: payments,
+		SyncWait: 50 * time.Millisecond,
+	})).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", handlers.PaymentStatusHandler(payments)).Methods(http.MethodGet)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "IDR",
+		Amount: 50_000, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "slow-1",
+	})
+	resp, err := http.Post(srv.URL+"/api/payments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out handlers.PaymentOut
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	resp.Body.Close()
+	loc := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusAccepted || out.Status != store.StatusPending || loc != "/api/payments/"+out.PaymentID {
+		t.Fatalf("expected 202 PENDING with Location, got %d %+v (Location %q)", resp.StatusCode, out, loc)
+	}
+
+	// worker baru jalan setelah gateway menyerah menunggu
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get(srv.URL + loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p store.Payment
+		_ = json.NewDecoder(resp.Body).Decode(&p)
+		resp.Body.Close()
+		if p.Status == "SUCCESS" {
+			return
+		}
+		if p.Status != store.StatusPending || time.Now().After(deadline) {
+			t.Fatalf("unexpected status %+v", p)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
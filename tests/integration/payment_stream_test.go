@@ -0,0 +1,199 @@
+// payment-gateway-poc/tests/integration/payment_stream_test.go
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+	"github.com/example/payment-gateway-poc/services/api-gateway/stream"
+)
+
+func TestPaymentStatusStream(t *testing.T) {
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 1_000_000, "ACC-2": 0}, seen: map[string]bool{}}
+	// merchant: key di bus di-namespace tenant, stream harus mengembalikan
+	// key seperti dikirim client
+	merchants := merchant.NewMemory(ledger)
+	mc := merchant.Merchant{ID: "M-1", Name: "Toko"}
+	if err := mc.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := merchants.Create(context.Background(), &mc); err != nil {
+		t.Fatal(err)
+	}
+	for _, acc := range []string{"ACC-1", "ACC-2"} {
+		if err := merchants.AssignAccount(context.Background(), "M-1", acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+
+	payments := store.NewMemory(0)
+	hub := stream.NewHub()
+	defer bus.WatchResults(hub.PublishResult)()
+
+	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithMerchant(r.Context(), "M-1")))
+		})
+	})
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx: fakeFx{}, Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{}, Bus: bus, Store: payments,
+		Merchants: merchants,
+	})).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}/events", handlers.PaymentEventsHandler(payments, hub)).Methods(http.MethodGet)
+	r.HandleFunc("/api/accounts/{id}/events", handlers.AccountEventsHandler(hub, merchants)).Methods(http.MethodGet)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	// worker belum jalan: payment tetap PENDING sampai stream terbuka
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "IDR",
+		Amount: 50_000, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "stream-1",
+	})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/payments", bytes.NewReader(body))
+	req.Header.Set("Prefer", "respond-async")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted handlers.PaymentOut
+	_ = json.NewDecoder(resp.Body).Decode(&accepted)
+	resp.Body.Close()
+
+	// SSE per payment
+	sse, err := http.Get(srv.URL + "/api/payments/" + accepted.PaymentID + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sse.Body.Close()
+	if ct := sse.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content-type %q", ct)
+	}
+	events := sseEvents(sse.Body)
+	if u := <-events; u.Status != store.StatusPending || u.IdempotencyKey != "stream-1" {
+		t.Fatalf("snapshot %+v", u)
+	}
+
+	// WebSocket per akun penerima
+	ws := dialWS(t, srv.URL+"/api/accounts/ACC-2/events")
+	defer ws.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	select {
+	case u := <-events:
+		if u.Status != "SUCCESS" || u.PaymentID != accepted.PaymentID || u.IdempotencyKey != "stream-1" {
+			t.Fatalf("final update %+v", u)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final update on SSE")
+	}
+	if _, open := <-events; open {
+		t.Fatal("SSE stream not closed after final status")
+	}
+
+	var msg struct {
+		Event string        `json:"event"`
+		Data  stream.Update `json:"data"`
+	}
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := json.Unmarshal(readWSText(t, ws), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Event != "payment" || msg.Data.ReceiverID != "ACC-2" || msg.Data.IdempotencyKey != "stream-1" {
+		t.Fatalf("websocket update %+v", msg)
+	}
+}
+
+func sseEvents(r io.Reader) <-chan stream.Update {
+	out := make(chan stream.Update)
+	go func() {
+		defer close(out)
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+				var u stream.Update
+				if json.Unmarshal([]byte(data), &u) == nil {
+					out <- u
+				}
+			}
+		}
+	}()
+	return out
+}
+
+type wsClient struct {
+	net.Conn
+	br *bufio.Reader
+}
+
+func dialWS(t *testing.T, url string) *wsClient {
+	t.Helper()
+	host := strings.TrimPrefix(url, "http://")
+	path := host[strings.Index(host, "/"):]
+	host = host[:strings.Index(host, "/")]
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n", path, host)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: %d %v", resp.StatusCode, resp.Header)
+	}
+	return &wsClient{Conn: conn, br: br}
+}
+
+// readWSText: frame server tidak di-mask; ping dilewati.
+func readWSText(t *testing.T, c *wsClient) []byte {
+	t.Helper()
+	for {
+		var h [2]byte
+		if _, err := io.ReadFull(c.br, h[:]); err != nil {
+			t.Fatal(err)
+		}
+		n := int(h[1] & 0x7F)
+		if n == 126 {
+			var b [2]byte
+			_, _ = io.ReadFull(c.br, b[:])
+			n = int(binary.BigEndian.Uint16(b[:]))
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.br, payload); err != nil {
+			t.Fatal(err)
+		}
+		if h[0]&0x0F == 0x1 {
+			return payload
+		}
+	}
+}
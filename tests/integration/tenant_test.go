@@ -0,0 +1,236 @@
+// payment-gateway-poc/tests/integration/tenant_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	perrors "github.com/example/payment-gateway-poc/pkg/errors"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/services/api-gateway/auth"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/merchant"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ledger := &memLedger{
+		bal:  map[string]int64{"A-1": 1_000_000, "A-2": 0, "B-1": 1_000_000, "B-2": 0, "DEMO": 1_000_000},
+		seen: map[string]bool{},
+	}
+	merchants := merchant.NewMemory(ledger)
+	for _, mc := range []merchant.Merchant{
+		{ID: "M-1", Name: "Toko Satu", FeePlan: merchant.FeePlan{PercentBps: 100, FixedIDR: 1000}},
+		{ID: "M-2", Name: "Toko Dua", SettlementCurrency: "USD"},
+	} {
+		if err := mc.Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		if err := merchants.Create(ctx, &mc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for acc, owner := range map[string]string{"A-1": "M-1", "A-2": "M-1", "B-1": "M-2", "B-2": "M-2"} {
+		if err := merchants.AssignAccount(ctx, owner, acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := merchants.AssignAccount(ctx, "M-2", "A-1"); err != merchant.ErrAccountTaken {
+		t.Fatalf("reassign: %v", err)
+	}
+
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	payments := store.NewMemory(0)
+	caller := ""
+	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if caller != "" {
+				r = r.WithContext(auth.WithMerchant(r.Context(), caller))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx:        fakeFx{},
+		Wallet:    fakeWallet{balances: ledger},
+		Risk:      fakeRisk{},
+		Bus:       bus,
+		Store:     payments,
+		Merchants: merchants,
+	})).Methods(http.MethodPost)
+	r.HandleFunc("/api/payments/{id}", handlers.PaymentStatusHandler(payments)).Methods(http.MethodGet)
+	handlers.MerchantHandlers{Store: merchants, AdminToken: "adm"}.Register(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	pay := func(as, from, to, key string) (int, handlers.PaymentOut) {
+		caller = as
+		body, _ := json.Marshal(handlers.PaymentIn{
+			SenderID: from, ReceiverID: to, Currency: "IDR",
+			Amount: 100_000, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: key,
+		})
+		resp, err := http.Post(srv.URL+"/api/payments", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out handlers.PaymentOut
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+	get := func(as, path string) *http.Response {
+		caller = as
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// idempotency key yang sama di dua tenant bukan replay
+	_, p1 := pay("M-1", "A-1", "A-2", "order-1")
+	_, p2 := pay("M-2", "B-1", "B-2", "order-1")
+	if p1.Status != "SUCCESS" || p2.Status != "SUCCESS" {
+		t.Fatalf("payments: %+v %+v", p1, p2)
+	}
+	// fee plan M-1 (1% + 1000) dipotong dari kredit receiver; M-2 settle USD
+	if got := ledger.get("A-2"); got != 98_000 {
+		t.Fatalf("receiver credited %d, want 98000", got)
+	}
+	ledger.mu.Lock()
+	settled := append([]worker.Payment(nil), ledger.paid...)
+	ledger.mu.Unlock()
+	if s := settled[0]; s.FeeIDR != 2000 || s.SettlementCurrency != "IDR" || s.SettlementAmount != 98_000 {
+		t.Fatalf("M-1 settlement: %+v", s)
+	}
+	if s := settled[1]; s.FeeIDR != 0 || s.SettlementCurrency != "USD" || s.SettlementAmount != 100_000.0/15000 {
+		t.Fatalf("M-2 settlement: %+v", s)
+	}
+
+	// akun merchant lain / akun merchant dari request anonim → 404
+	if code, out := pay("M-1", "A-1", "B-1", "x-1"); code != http.StatusNotFound || out.Code != perrors.AccountNotFound {
+		t.Fatalf("cross-tenant receiver: %d %+v", code, out)
+	}
+	if code, _ := pay("", "A-1", "A-2", "x-2"); code != http.StatusNotFound {
+		t.Fatalf("anonymous on merchant account: %d", code)
+	}
+	if code, out := pay("", "DEMO", "DEMO-2", "x-3"); code != http.StatusOK || out.Status != "SUCCESS" {
+		t.Fatalf("anonymous on shared account: %d %+v", code, out)
+	}
+
+	// status hanya terlihat oleh tenant pemilik, dengan fee dari fee plan
+	resp := get("M-2", "/api/payments/"+p1.PaymentID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cross-tenant status: %d", resp.StatusCode)
+	}
+	resp = get("M-1", "/api/payments/"+p1.PaymentID)
+	var rec store.Payment
+	_ = json.NewDecoder(resp.Body).Decode(&rec)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || rec.FeeIDR != 2000 || rec.IdempotencyKey != "order-1" || rec.MerchantID != "M-1" {
+		t.Fatalf("own status: %d %+v", resp.StatusCode, rec)
+	}
+
+	// profil merchant: sendiri boleh, merchant lain tidak
+	resp = get("M-1", "/api/merchants/M-1")
+	var prof struct {
+		Accounts []string `json:"accounts"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&prof)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(prof.Accounts) != 2 {
+		t.Fatalf("own profile: %d %+v", resp.StatusCode, prof)
+	}
+	resp = get("M-2", "/api/merchants/M-1")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("foreign profile: %d", resp.StatusCode)
+	}
+}
+
+// Mode memory (PAYMENT_STORE=memory): merchant di-onboard lewat admin API,
+// assign akun ditulis ke ledger, lalu payment merchant settle end-to-end.
+func TestMerchantPaymentMemoryMode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ledger := &memLedger{bal: map[string]int64{"S-1": 500_000, "R-1": 0}, seen: map[string]bool{}}
+	merchants := merchant.NewMemory(ledger)
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")) }()
+
+	r := mux.NewRouter()
+	pay := handlers.PaymentsHandler(handlers.Deps{
+		Fx:        fakeFx{},
+		Wallet:    fakeWallet{balances: ledger},
+		Risk:      fakeRisk{},
+		Bus:       bus,
+		Store:     store.NewMemory(0),
+		Merchants: merchants,
+	})
+	r.HandleFunc("/api/payments", func(w http.ResponseWriter, r *http.Request) {
+		pay(w, r.WithContext(auth.WithMerchant(r.Context(), "M-9")))
+	}).Methods(http.MethodPost)
+	handlers.MerchantHandlers{Store: merchants, AdminToken: "adm"}.Register(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	admin := func(path, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer adm")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := admin("/api/admin/merchants", `{"merchant_id":"M-9","name":"Toko","fee_plan":{"fixed_idr":500}}`); code != http.StatusCreated {
+		t.Fatalf("create merchant: %d", code)
+	}
+	for _, acc := range []string{"S-1", "R-1"} {
+		if code := admin("/api/admin/merchants/M-9/accounts", `{"account_id":"`+acc+`"}`); code != http.StatusNoContent {
+			t.Fatalf("assign %s: %d", acc, code)
+		}
+	}
+	if code := admin("/api/admin/merchants/M-9/accounts", `{"account_id":"NOPE"}`); code != http.StatusNotFound {
+		t.Fatalf("assign unknown account: %d", code)
+	}
+
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "S-1", ReceiverID: "R-1", Currency: "IDR",
+		Amount: 100_000, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "order-9",
+	})
+	resp, err := http.Post(srv.URL+"/api/payments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out handlers.PaymentOut
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || out.Status != "SUCCESS" {
+		t.Fatalf("merchant payment: %d %+v", resp.StatusCode, out)
+	}
+	if s, r := ledger.get("S-1"), ledger.get("R-1"); s != 400_000 || r != 99_500 {
+		t.Fatalf("balances sender %d receiver %d, want 400000 / 99500", s, r)
+	}
+}
@@ -0,0 +1,134 @@
+// payment-gateway-poc/tests/integration/tracing_test.go
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/example/payment-gateway-poc/internal/worker"
+	"github.com/example/payment-gateway-poc/pkg/queue"
+	"github.com/example/payment-gateway-poc/pkg/tracing"
+	fxv1 "github.com/example/payment-gateway-poc/proto/gen/fx/v1"
+	"github.com/example/payment-gateway-poc/services/api-gateway/handlers"
+	"github.com/example/payment-gateway-poc/services/api-gateway/store"
+)
+
+// fxServer: FX lewat gRPC sungguhan supaya span client & server terlihat.
+type fxServer struct {
+	fxv1.UnimplementedFxServiceServer
+}
+
+func (fxServer) Convert(ctx context.Context, in *fxv1.ConvertRequest) (*fxv1.ConvertResponse, error) {
+	return &fxv1.ConvertResponse{Amount: in.GetAmount() * 15000}, nil
+}
+
+// Satu payment: POST /api/payments → FX gRPC → payments.request (Kafka
+// header) → worker settle → satu trace.
+func TestPaymentTrace(t *testing.T) {
+	var out bytes.Buffer
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(&out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flush := tracing.Install("test", exp, 1)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	gs := grpc.NewServer(tracing.ServerOption())
+	fxv1.RegisterFxServiceServer(gs, fxServer{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()), tracing.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ledger := &memLedger{bal: map[string]int64{"ACC-1": 1_000_000}, seen: map[string]bool{}}
+	bus := queue.NewMemory("payments.request")
+	defer bus.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := worker.NewPool(worker.Config{Concurrency: 1}, worker.SettleHandler(ledger, bus))
+	stopped := make(chan struct{})
+	go func() { _ = pool.Run(ctx, bus.Subscribe("payments-worker")); close(stopped) }()
+
+	r := mux.NewRouter()
+	r.Use(tracing.Middleware("api-gateway"))
+	r.HandleFunc("/api/payments", handlers.PaymentsHandler(handlers.Deps{
+		Fx: fxv1.NewFxServiceClient(conn), Wallet: fakeWallet{balances: ledger}, Risk: fakeRisk{},
+		Bus: bus, Store: store.NewMemory(0),
+	})).Methods(http.MethodPost)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	body, _ := json.Marshal(handlers.PaymentIn{
+		SenderID: "ACC-1", ReceiverID: "ACC-2", Currency: "USD",
+		Amount: 10, TxDateISO: "2025-01-02T03:04:05Z", IdempotencyKey: "trace-1",
+	})
+	resp, err := http.Post(srv.URL+"/api/payments", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var pay handlers.PaymentOut
+	if err := json.Unmarshal(b, &pay); err != nil || pay.Status != "SUCCESS" {
+		t.Fatalf("payment: %s %v", b, err)
+	}
+	cancel() // span consumer selesai sebelum flush
+	<-stopped
+	if err := flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	type span struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+	}
+	traces := map[string]map[string]int{} // trace → nama span → jumlah
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var s span
+		if err := dec.Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		if traces[s.SpanContext.TraceID] == nil {
+			traces[s.SpanContext.TraceID] = map[string]int{}
+		}
+		traces[s.SpanContext.TraceID][s.Name]++
+	}
+	if len(traces) != 1 {
+		t.Fatalf("want 1 trace, got %d: %v", len(traces), traces)
+	}
+	for _, names := range traces {
+		for name, n := range map[string]int{
+			"/api/payments":            1,
+			"fx.v1.FxService/Convert":  2, // client + server
+			"payments.request publish": 1,
+			"payments.request process": 1,
+			"settle":                   1,
+		} {
+			if names[name] != n {
+				t.Errorf("span %q: got %d, want %d (all: %v)", name, names[name], n, names)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// payment-gateway-poc/tools/cmd/admintoken/main.go
+// admintoken: terbitkan token Admin gRPC (ditandatangani ADMIN_TOKEN_SECRET).
+//
+//	ADMIN_TOKEN_SECRET=... go run ./tools/cmd/admintoken -sub alice -roles treasury
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/example/payment-gateway-poc/pkg/rbac"
+)
+
+func main() {
+	sub := flag.String("sub", os.Getenv("USER"), "subject (nama pemanggil, masuk audit log)")
+	roles := flag.String("roles", rbac.RoleOps, "role dipisah koma: ops,treasury,risk-analyst")
+	ttl := flag.Duration("ttl", time.Hour, "masa berlaku token")
+	flag.Parse()
+
+	secret := os.Getenv("ADMIN_TOKEN_SECRET")
+	if secret == "" {
+		log.Fatal("ADMIN_TOKEN_SECRET not set")
+	}
+	if *sub == "" {
+		log.Fatal("-sub required")
+	}
+	tok, err := rbac.Issue([]byte(secret), *sub, strings.Split(*roles, ","), *ttl, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(tok)
+}
@@ -0,0 +1,49 @@
+// payment-gateway-poc/tools/cmd/devca/main.go
+// devca: buat CA lokal + cert mTLS untuk setiap service di compose.
+//
+//	go run ./tools/cmd/devca -out deployments/certs
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/example/payment-gateway-poc/pkg/mtls"
+)
+
+func main() {
+	out := flag.String("out", "deployments/certs", "direktori output PEM")
+	ttl := flag.Duration("ttl", 30*24*time.Hour, "masa berlaku cert service")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatal(err)
+	}
+	ca, err := mtls.NewDevCA()
+	if err != nil {
+		log.Fatal(err)
+	}
+	caFile := filepath.Join(*out, "ca.pem")
+	if err := os.WriteFile(caFile, ca.PEM, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	// nama service = identitas = hostname di compose
+	for _, name := range []string{
+		mtls.IdentityGateway,
+		mtls.IdentityWallet,
+		mtls.IdentityFx,
+		mtls.IdentityRisk,
+		mtls.IdentityPayments,
+		mtls.IdentityOps,
+	} {
+		if _, err := ca.WriteFiles(*out, name, caFile, *ttl, name, "localhost"); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("issued %s", name)
+	}
+	log.Printf("CA + certs written to %s", *out)
+}